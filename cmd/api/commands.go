@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jcorry/morellis/pkg/models"
+	"github.com/jcorry/morellis/pkg/sms"
+)
+
+const helpReply = `Morellis flavor alerts 🍦
+ADD <ingredient> - follow an ingredient
+REMOVE <ingredient> - stop following it
+LIST - what you follow
+STORES - our locations
+LOGIN - a link to the app
+STOP - stop all messages
+START - start them again`
+
+// runCommand runs the Command on behalf of the User and returns the text of the reply
+// to be sent back to them.
+func (app *application) runCommand(ctx context.Context, user *models.User, cmd sms.Command) (string, error) {
+	switch cmd.Name {
+	case sms.CMD_ADD:
+		return app.addCommand(user, cmd.Args)
+	case sms.CMD_REMOVE:
+		return app.removeCommand(user, cmd.Args)
+	case sms.CMD_LIST:
+		return app.listCommand(user)
+	case sms.CMD_STORES:
+		return app.storesCommand()
+	case sms.CMD_STOP:
+		return app.stopCommand(user, cmd.Keyword)
+	case sms.CMD_START:
+		return app.startCommand(user)
+	case sms.CMD_LOGIN:
+		return app.authLinkMessage(user)
+	}
+
+	return helpReply, nil
+}
+
+// isConfirmation reports whether the reply to the Command confirms a STOP, START or HELP, which
+// is sent even to numbers which have opted out. Messages which aren't a command are parsed as
+// HELP, but only an explicit HELP is confirmed.
+func isConfirmation(cmd sms.Command) bool {
+	switch cmd.Name {
+	case sms.CMD_STOP, sms.CMD_START:
+		return true
+	case sms.CMD_HELP:
+		return cmd.Args == ""
+	}
+
+	return false
+}
+
+func (app *application) addCommand(user *models.User, term string) (string, error) {
+	if term == "" {
+		return "What would you like to follow? Text ADD followed by an ingredient, like ADD coconut", nil
+	}
+
+	ingredient, err := app.ingredients.GetByName(term)
+	if err == models.ErrNoRecord {
+		return fmt.Sprintf("Sorry, none of our flavors have %s in them. Text LIST to see what you follow.", term), nil
+	} else if err != nil {
+		return "", err
+	}
+
+	_, err = app.users.AddIngredient(user.ID, ingredient, "", models.SubscriptionScope{})
+	if err == models.ErrDuplicateUserIngredient {
+		return fmt.Sprintf("You're already following %s.", ingredient.Name), nil
+	} else if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Got it! We'll text you when a flavor with %s is in the cooler.", ingredient.Name), nil
+}
+
+func (app *application) removeCommand(user *models.User, term string) (string, error) {
+	if term == "" {
+		return "What would you like to stop following? Text REMOVE followed by an ingredient, like REMOVE pecan", nil
+	}
+
+	userIngredients, err := app.users.GetIngredients(user.ID)
+	if err != nil {
+		return "", err
+	}
+
+	for _, ui := range userIngredients {
+		if (ui.Ingredient != nil && strings.EqualFold(ui.Name, term)) || strings.EqualFold(ui.Keyword, term) {
+			err = app.users.RemoveUserIngredient(ui.UserIngredientID)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("Done. You're no longer following %s.", term), nil
+		}
+	}
+
+	return fmt.Sprintf("You aren't following %s. Text LIST to see what you follow.", term), nil
+}
+
+func (app *application) listCommand(user *models.User) (string, error) {
+	userIngredients, err := app.users.GetIngredients(user.ID)
+	if err != nil {
+		return "", err
+	}
+
+	if len(userIngredients) == 0 {
+		return "You aren't following anything yet. Text ADD followed by an ingredient, like ADD coconut", nil
+	}
+
+	var names []string
+	for _, ui := range userIngredients {
+		if ui.Keyword != "" {
+			names = append(names, ui.Keyword)
+			continue
+		}
+		names = append(names, ui.Name)
+	}
+
+	return fmt.Sprintf("You're following: %s", strings.Join(names, ", ")), nil
+}
+
+func (app *application) storesCommand() (string, error) {
+	stores, err := app.stores.List(0, 0, "s.name")
+	if err != nil {
+		return "", err
+	}
+
+	lines := []string{"Morellis locations:"}
+	for _, s := range stores {
+		lines = append(lines, fmt.Sprintf("%s - %s, %s", s.Name, s.Address, s.City))
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// stopCommand opts the User's phone number out of all messages, recording the `keyword` they
+// texted to do so. Their subscriptions are kept, so that they pick up where they left off if
+// they text START.
+func (app *application) stopCommand(user *models.User, keyword string) (string, error) {
+	_, err := app.suppressions.Suppress(user.Phone, keyword)
+	if err != nil {
+		return "", err
+	}
+
+	return "You've been unsubscribed and won't get any more messages from us. Text START to resubscribe.", nil
+}
+
+func (app *application) startCommand(user *models.User) (string, error) {
+	err := app.suppressions.Unsuppress(user.Phone)
+	if err != nil && err != models.ErrNoneAffected {
+		return "", err
+	}
+
+	return "Welcome back! We'll text you flavor alerts again. Text HELP for help.", nil
+}
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jcorry/morellis/pkg/models"
+	"github.com/jcorry/morellis/pkg/sms"
+)
+
+func TestRunCommand(t *testing.T) {
+	user := &models.User{ID: 4, Phone: "4045551212"}
+	coconut := &models.Ingredient{ID: 1, Name: "coconut"}
+	pecan := &models.Ingredient{ID: 4, Name: "pecan"}
+	following := []*models.UserIngredient{
+		{UserIngredientID: 10, Ingredient: coconut},
+		{UserIngredientID: 11, Ingredient: pecan},
+	}
+
+	tests := []struct {
+		name           string
+		body           string
+		ingredient     *models.Ingredient
+		ingredErr      error
+		addErr         error
+		following      []*models.UserIngredient
+		wantReply      string
+		wantAdded      int
+		wantRemoved    []int64
+		wantSuppress   int
+		wantKeyword    string
+		wantUnsuppress int
+	}{
+		{
+			name:       "add",
+			body:       "ADD coconut",
+			ingredient: coconut,
+			wantReply:  "Got it! We'll text you when a flavor with coconut is in the cooler.",
+			wantAdded:  1,
+		},
+		{
+			name:      "add unknown ingredient",
+			body:      "ADD kale",
+			ingredErr: models.ErrNoRecord,
+			wantReply: "Sorry, none of our flavors have kale in them. Text LIST to see what you follow.",
+		},
+		{
+			name:       "add duplicate",
+			body:       "add coconut",
+			ingredient: coconut,
+			addErr:     models.ErrDuplicateUserIngredient,
+			wantReply:  "You're already following coconut.",
+			wantAdded:  1,
+		},
+		{
+			name:        "remove",
+			body:        "REMOVE pecan",
+			following:   following,
+			wantReply:   "Done. You're no longer following pecan.",
+			wantRemoved: []int64{11},
+		},
+		{
+			name:      "remove not followed",
+			body:      "REMOVE jalapeno",
+			following: following,
+			wantReply: "You aren't following jalapeno. Text LIST to see what you follow.",
+		},
+		{
+			name:      "list",
+			body:      "LIST",
+			following: following,
+			wantReply: "You're following: coconut, pecan",
+		},
+		{
+			name:      "list nothing",
+			body:      "LIST",
+			wantReply: "You aren't following anything yet. Text ADD followed by an ingredient, like ADD coconut",
+		},
+		{
+			name:         "stop",
+			body:         "STOP",
+			following:    following,
+			wantReply:    "You've been unsubscribed and won't get any more messages from us. Text START to resubscribe.",
+			wantSuppress: 1,
+			wantKeyword:  "STOP",
+		},
+		{
+			name:         "stop alias",
+			body:         "unsubscribe",
+			following:    following,
+			wantReply:    "You've been unsubscribed and won't get any more messages from us. Text START to resubscribe.",
+			wantSuppress: 1,
+			wantKeyword:  "UNSUBSCRIBE",
+		},
+		{
+			name:           "start",
+			body:           "START",
+			wantReply:      "Welcome back! We'll text you flavor alerts again. Text HELP for help.",
+			wantUnsuppress: 1,
+		},
+		{
+			name:      "help",
+			body:      "what is this?",
+			wantReply: helpReply,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			app, fakes := newFakeApplication()
+			fakes.ingredients.GetByNameReturns(tt.ingredient, tt.ingredErr)
+			fakes.users.AddIngredientReturns(&models.UserIngredient{}, tt.addErr)
+			fakes.users.GetIngredientsReturns(tt.following, nil)
+
+			reply, err := app.runCommand(context.TODO(), user, sms.ParseCommand(tt.body))
+			require.NoError(t, err)
+			require.Equal(t, tt.wantReply, reply)
+
+			require.Equal(t, tt.wantAdded, fakes.users.AddIngredientCallCount())
+			require.Equal(t, len(tt.wantRemoved), fakes.users.RemoveUserIngredientCallCount())
+			for i, id := range tt.wantRemoved {
+				require.Equal(t, id, fakes.users.RemoveUserIngredientArgsForCall(i))
+			}
+
+			require.Equal(t, tt.wantSuppress, fakes.suppressions.SuppressCallCount())
+			if tt.wantSuppress > 0 {
+				phone, keyword := fakes.suppressions.SuppressArgsForCall(0)
+				require.Equal(t, user.Phone, phone)
+				require.Equal(t, tt.wantKeyword, keyword)
+			}
+			require.Equal(t, tt.wantUnsuppress, fakes.suppressions.UnsuppressCallCount())
+		})
+	}
+}
+
+func TestRunCommand_Login(t *testing.T) {
+	user := &models.User{ID: 4, Phone: "4045551212"}
+
+	tests := []struct {
+		name     string
+		requests int64
+		wantSent bool
+	}{
+		{"first request", 1, true},
+		{"last request in the window", AUTH_REQUEST_LIMIT, true},
+		{"too many requests", AUTH_REQUEST_LIMIT + 1, false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			app, fakes := newFakeApplication()
+			app.baseUrl = "https://morellis.test"
+			fakes.users.CountAuthRequestReturns(tt.requests, nil)
+
+			reply, err := app.runCommand(context.TODO(), user, sms.ParseCommand("LOGIN"))
+			require.NoError(t, err)
+
+			phone, window := fakes.users.CountAuthRequestArgsForCall(0)
+			require.Equal(t, user.Phone, phone)
+			require.Equal(t, AUTH_REQUEST_WINDOW, window)
+
+			if !tt.wantSent {
+				require.Equal(t, 0, fakes.users.SaveAuthTokenCallCount())
+				require.Equal(t, 0, fakes.users.SaveAuthCodeCallCount())
+				require.Contains(t, reply, "too many times")
+				return
+			}
+
+			require.Equal(t, 1, fakes.users.SaveAuthTokenCallCount())
+			token, userID := fakes.users.SaveAuthTokenArgsForCall(0)
+			require.Equal(t, 4, userID)
+			require.Contains(t, reply, "https://morellis.test/auth/"+token)
+
+			require.Equal(t, 1, fakes.users.SaveAuthCodeCallCount())
+			codeUserID, code, ttl := fakes.users.SaveAuthCodeArgsForCall(0)
+			require.Equal(t, int64(4), codeUserID)
+			require.Regexp(t, `^[0-9]{6}$`, code)
+			require.Equal(t, AUTH_CODE_TTL, ttl)
+			require.Contains(t, reply, code)
+		})
+	}
+}
+
+func TestRunCommand_Stores(t *testing.T) {
+	app, fakes := newFakeApplication()
+	fakes.stores.ListReturns([]*models.Store{
+		{Name: "Morellis On Moreland", Address: "749 Moreland Ave SE", City: "Atlanta"},
+		{Name: "Dunwoody Farmburger", Address: "4514 Chamblee Dunwoody Rd", City: "Dunwoody"},
+	}, nil)
+
+	reply, err := app.runCommand(context.TODO(), &models.User{ID: 4}, sms.ParseCommand("stores"))
+	require.NoError(t, err)
+	require.Equal(t, "Morellis locations:\nMorellis On Moreland - 749 Moreland Ave SE, Atlanta\nDunwoody Farmburger - 4514 Chamblee Dunwoody Rd, Dunwoody", reply)
+}
+
+func TestSmsInbound(t *testing.T) {
+	app, fakes := newFakeApplication()
+	fakes.users.GetByPhoneReturns(&models.User{ID: 4, Phone: "4045551212"}, nil)
+	fakes.ingredients.GetByNameReturns(&models.Ingredient{ID: 1, Name: "coconut"}, nil)
+	fakes.users.AddIngredientReturns(&models.UserIngredient{}, nil)
+
+	reqUrl, err := url.Parse(fmt.Sprintf("%s/webhooks/v1/sms/inbound", os.Getenv("HOST")))
+	require.NoError(t, err)
+
+	form := url.Values{
+		"From": {"+14045551212"},
+		"To":   {"+18005551212"},
+		"Body": {"ADD coconut"},
+	}
+	sig := sms.GetExpectedTwilioSignature(os.Getenv("HOST"), os.Getenv("TWILIO_AUTH_TOKEN"), reqUrl.String(), form)
+
+	req := httptest.NewRequest(http.MethodPost, reqUrl.String(), strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Twilio-Signature", sig)
+
+	res := NewFakeResponse(t)
+	app.smsInbound(res, req)
+
+	res.Assert(http.StatusOK, `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+
+		`<Response><Message><Body>Got it! We&#39;ll text you when a flavor with coconut is in the cooler.</Body></Message></Response>`)
+	require.Equal(t, "text/xml", res.Header().Get("Content-Type"))
+	require.Equal(t, "+14045551212", fakes.users.GetByPhoneArgsForCall(0))
+}
+
+func TestSmsInbound_SeparateReply(t *testing.T) {
+	app, fakes := newFakeApplication()
+	fakes.users.GetByPhoneReturns(&models.User{ID: 4, Phone: "14045551212"}, nil)
+	// Vonage can't reply in the webhook response, so replies are sent as new messages
+	app.provider = sms.NewVonageMessager(&http.Client{}, "key", "secret", "sigsecret", "18005551212")
+
+	form := url.Values{
+		"msisdn":    {"14045551212"},
+		"to":        {"18005551212"},
+		"messageId": {"0A0000000123ABCD1"},
+		"text":      {"LIST"},
+	}
+	form.Set("sig", sms.GetExpectedVonageSignature("sigsecret", form))
+
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/v1/sms/inbound", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res := NewFakeResponse(t)
+	app.smsInbound(res, req)
+
+	require.Equal(t, http.StatusNoContent, res.status)
+	require.Equal(t, 1, fakes.sender.SendCallCount())
+	_, number, body := fakes.sender.SendArgsForCall(0)
+	require.Equal(t, "14045551212", number)
+	require.Equal(t, "You aren't following anything yet. Text ADD followed by an ingredient, like ADD coconut", body)
+}
+
+func TestSmsInbound_Suppressed(t *testing.T) {
+	tests := []struct {
+		name          string
+		text          string
+		wantDelivered bool
+	}{
+		{"command", "LIST", false},
+		{"not a command", "what flavors are there?", false},
+		{"stop", "STOP", true},
+		{"start", "START", true},
+		{"help", "HELP", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Stands in for the Vonage API, recording the messages actually delivered
+			var delivered []url.Values
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.NoError(t, r.ParseForm())
+				delivered = append(delivered, r.PostForm)
+				w.Write([]byte(`{"message-count":"1","messages":[{"to":"14045551212","message-id":"0A0000000123ABCD2","status":"0"}]}`))
+			}))
+			defer ts.Close()
+
+			app, fakes := newFakeApplication()
+			fakes.users.GetByPhoneReturns(&models.User{ID: 4, Phone: "14045551212"}, nil)
+			fakes.suppressions.IsSuppressedReturns(true, nil)
+			app.provider = sms.NewVonageMessager(ts.Client(), "key", "secret", "sigsecret", "18005551212").WithBaseURL(ts.URL)
+
+			form := url.Values{
+				"msisdn":    {"14045551212"},
+				"to":        {"18005551212"},
+				"messageId": {"0A0000000123ABCD1"},
+				"text":      {tt.text},
+			}
+			form.Set("sig", sms.GetExpectedVonageSignature("sigsecret", form))
+
+			req := httptest.NewRequest(http.MethodPost, "/webhooks/v1/sms/inbound", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			res := NewFakeResponse(t)
+			app.smsInbound(res, req)
+
+			require.Equal(t, http.StatusNoContent, res.status)
+			// Nothing is queued in the outbox, where it would be suppressed
+			require.Equal(t, 0, fakes.sender.SendCallCount())
+			require.Equal(t, tt.wantDelivered, len(delivered) == 1)
+			if tt.wantDelivered {
+				require.Equal(t, "14045551212", delivered[0].Get("to"))
+			}
+		})
+	}
+}
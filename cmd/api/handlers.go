@@ -1,141 +1,238 @@
 package main
 
 import (
-	"encoding/base64"
+	"context"
 	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"html/template"
 	"io"
 	"io/ioutil"
 	"net/http"
-	"os"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
-	"golang.org/x/crypto/bcrypt"
 
+	"github.com/jcorry/morellis/pkg/inventory"
 	"github.com/jcorry/morellis/pkg/models"
 	"github.com/jcorry/morellis/pkg/models/mysql"
+	"github.com/jcorry/morellis/pkg/notify"
+	"github.com/jcorry/morellis/pkg/report"
 	"github.com/jcorry/morellis/pkg/sms"
 )
 
+// UserIngredientBody is a User's subscription to an Ingredient, or to Flavors matching a
+// keyword. It is limited to the Store identified by StoreID, or those identified by StoreIDs,
+// or Stores within Area if any are given.
 type UserIngredientBody struct {
-	ID           int64     `json:"id"`
-	UserUUID     uuid.UUID `json:"userUuid"`
-	IngredientID int64     `json:"ingredientId"`
-	StoreID      int64     `json:"storeId,omitempty"`
-	Keyword      string    `json:"keyword,omitempty"`
-	Created      time.Time `json:"created"`
+	ID           int64        `json:"id"`
+	UserUUID     uuid.UUID    `json:"userUuid"`
+	IngredientID int64        `json:"ingredientId,omitempty"`
+	StoreID      int64        `json:"storeId,omitempty"`
+	StoreIDs     []int64      `json:"storeIds,omitempty"`
+	Area         *models.Area `json:"area,omitempty"`
+	Keyword      string       `json:"keyword,omitempty"`
+	Created      time.Time    `json:"created"`
+}
+
+// MAX_KEYWORD_LENGTH is the longest keyword a User may subscribe to
+const MAX_KEYWORD_LENGTH = 64
+
+// UserFlavorBody is a User's subscription to a Flavor
+type UserFlavorBody struct {
+	ID       int64     `json:"id"`
+	UserUUID uuid.UUID `json:"userUuid"`
+	FlavorID int64     `json:"flavorId"`
+	Created  time.Time `json:"created"`
+}
+
+// UserRoleBody is a Role granted to a User, at the Store identified by StoreID or at every Store
+// if StoreID is 0.
+type UserRoleBody struct {
+	ID       int64     `json:"id"`
+	UserUUID uuid.UUID `json:"userUuid"`
+	Role     string    `json:"role"`
+	StoreID  int64     `json:"storeId,omitempty"`
+	Created  time.Time `json:"created"`
 }
 
 // Webhook handlers
 
-// smsAuthRequest looks the user up by their phone number, supplied by the incoming twilio
-// webhook. If found, generates an expiring auth token and sends the user a URL at
+// smsAuthRequest looks the user up by their phone number, supplied by the incoming SMS
+// webhook. If found, generates an expiring auth token and replies to the user with a URL at
 // which they can authenticate and get a JWT with limited permissions for future requests
 func (app *application) smsAuthRequest(w http.ResponseWriter, r *http.Request) {
-	err := sms.ValidateIncomingRequest(os.Getenv("HOST"), os.Getenv("TWILIO_AUTH_TOKEN"), r)
+	inbound, err := app.provider.ParseInbound(r)
 	if err != nil {
 		app.errorLog.Output(2, err.Error())
 		app.clientError(w, http.StatusUnauthorized)
 		return
 	}
 
-	guid := uuid.New()
-	token := base64.StdEncoding.EncodeToString([]byte(guid.String()))
+	user, err := app.userByPhone(inbound.From)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
 
-	type TwilioPayload struct {
-		From string `json:"From"`
+	message, err := app.authLinkMessage(user)
+	if err != nil {
+		app.serverError(w, err)
+		return
 	}
-	var tp TwilioPayload
-	tp.From = r.FormValue(`From`)
 
-	var user *models.User
-	user, err = app.users.GetByPhone(tp.From)
-	// If no user is found, create one
+	app.smsReply(w, inbound, message, false)
+}
+
+// smsInbound handles SMS messages sent to our number. The message body is parsed as a
+// Command, which is run on behalf of the sender, and the result is the reply.
+func (app *application) smsInbound(w http.ResponseWriter, r *http.Request) {
+	inbound, err := app.provider.ParseInbound(r)
 	if err != nil {
-		if err == models.ErrNoRecord {
-			password, err := bcrypt.GenerateFromPassword([]byte(uuid.New().String()), bcrypt.DefaultCost)
-			if err != nil {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
-				return
-			}
-			user, err = app.users.Insert(uuid.New(), models.NullString{}, models.NullString{}, models.NullString{}, tp.From, int(models.USER_STATUS_VERIFIED), string(password))
-		} else {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
+		app.errorLog.Output(2, err.Error())
+		app.clientError(w, http.StatusUnauthorized)
+		return
 	}
 
-	// save the token
-	err = app.users.SaveAuthToken(token, int(user.ID))
+	user, err := app.userByPhone(inbound.From)
 	if err != nil {
 		app.serverError(w, err)
 		return
 	}
 
-	url := fmt.Sprintf(`%s/auth/%s`, app.baseUrl, token)
-	message := fmt.Sprintf(`access the 🍦 app at: %s`, url)
+	cmd := sms.ParseCommand(inbound.Body)
+
+	reply, err := app.runCommand(r.Context(), user, cmd)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.smsReply(w, inbound, reply, isConfirmation(cmd))
+}
+
+// smsStatus records a delivery status reported by the SMS provider against the sent Message
+// it identifies. The provider posts to this endpoint as a message is queued, sent, delivered,
+// or fails to be delivered.
+func (app *application) smsStatus(w http.ResponseWriter, r *http.Request) {
+	report, err := app.provider.ParseStatus(r)
+	if err != nil {
+		app.errorLog.Output(2, err.Error())
+		app.clientError(w, http.StatusUnauthorized)
+		return
+	}
+
+	if report.Status == "" {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	msg, err := app.messages.GetBySID(report.ID)
+	if err == models.ErrNoRecord {
+		app.notFound(w)
+		return
+	} else if err != nil {
+		app.serverError(w, err)
+		return
+	}
 
-	_, err = app.sender.Send(r.Context(), user.Phone, message)
+	_, err = app.messages.AddEvent(msg.ID, report.Status, report.ErrorCode)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		app.serverError(w, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(http.StatusText(http.StatusOK)))
+	app.noContentResponse(w)
 }
 
-// authByToken looks for a valid auth token in the URL and if found, returns a JWT
+// authByToken looks for a valid auth token in the URL and if found, returns a JWT. Each auth
+// token can only be used once.
 func (app *application) authByToken(w http.ResponseWriter, r *http.Request) {
 	// look up user by token
-	user, err := app.users.GetByAuthToken(r.URL.Query().Get(":token"))
+	user, err := app.users.ConsumeAuthToken(r.URL.Query().Get(":token"))
 	if err != nil {
 		app.errorLog.Output(2, err.Error())
-		if err == models.ErrNoRecord {
-			app.clientError(w, http.StatusNotFound)
-			return
-		}
-		if err == mysql.ErrNoAuthTokenFound {
+		if err == models.ErrNoRecord || err == mysql.ErrNoAuthTokenFound {
+			app.auditLogin(r, &models.LoginAttempt{Method: models.LOGIN_METHOD_LINK, Reason: err.Error()})
 			app.clientError(w, http.StatusNotFound)
 			return
 		}
 		app.serverError(w, err)
 		return
 	}
-	user.Permissions, err = app.users.GetPermissions(int(user.ID))
+
+	app.auditLogin(r, &models.LoginAttempt{UserID: user.ID, Phone: user.Phone, Method: models.LOGIN_METHOD_LINK, Success: true})
+
+	app.login(w, user)
+}
+
+// authByCode exchanges the one time code texted to a phone number for a JWT. Each code can
+// only be used once, and is deleted after AUTH_CODE_MAX_ATTEMPTS wrong guesses.
+func (app *application) authByCode(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Phone string `json:"phone"`
+		Code  string `json:"code"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&body)
 	if err != nil {
-		app.serverError(w, err)
+		app.badRequest(w, err)
+		return
+	}
+	defer r.Body.Close()
+
+	if body.Phone == "" || body.Code == "" {
+		app.badRequest(w, fmt.Errorf("phone and code are required"))
 		return
 	}
 
-	token, err := generateToken(user)
-	if err != nil {
+	user, err := app.users.GetByPhone(body.Phone)
+	if err == models.ErrNoRecord {
+		app.auditLogin(r, &models.LoginAttempt{Phone: body.Phone, Method: models.LOGIN_METHOD_CODE, Reason: "unknown phone"})
+		app.clientError(w, http.StatusUnauthorized)
+		return
+	} else if err != nil {
 		app.serverError(w, err)
 		return
 	}
 
-	claims, err := verifyToken(token)
+	ok, err := app.users.ConsumeAuthCode(user.ID, body.Code, AUTH_CODE_MAX_ATTEMPTS)
 	if err != nil {
 		app.serverError(w, err)
 		return
 	}
 
-	exp := time.Unix(claims.ExpiresAt, 0)
+	if !ok {
+		app.auditLogin(r, &models.LoginAttempt{UserID: user.ID, Phone: user.Phone, Method: models.LOGIN_METHOD_CODE, Reason: "wrong or expired code"})
+		app.clientError(w, http.StatusUnauthorized)
+		return
+	}
+
+	app.auditLogin(r, &models.LoginAttempt{UserID: user.ID, Phone: user.Phone, Method: models.LOGIN_METHOD_CODE, Success: true})
+
+	app.login(w, user)
+}
+
+// login responds with new tokens for the User, with their current permissions and roles
+func (app *application) login(w http.ResponseWriter, user *models.User) {
+	var err error
 
-	response := struct {
-		Token   string    `json:"token"`
-		Expires time.Time `json:"expires"`
-	}{
-		token,
-		exp,
+	user.Permissions, err = app.users.GetPermissions(int(user.ID))
+	if err != nil {
+		app.serverError(w, err)
+		return
 	}
 
-	app.jsonResponse(w, response)
+	user.Roles, err = app.users.GetRoles(user.ID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
 
+	app.issueTokens(w, user)
 }
 
 // Auth handlers
@@ -152,39 +249,246 @@ func (app *application) createAuth(w http.ResponseWriter, r *http.Request) {
 	user, err := app.users.GetByCredentials(creds)
 	if err != nil {
 		app.errorLog.Output(2, err.Error())
+		app.auditLogin(r, &models.LoginAttempt{Method: models.LOGIN_METHOD_PASSWORD, Reason: err.Error()})
 		app.clientError(w, http.StatusNotFound)
 		return
 	}
 
-	user.Permissions, err = app.users.GetPermissions(int(user.ID))
+	app.auditLogin(r, &models.LoginAttempt{UserID: user.ID, Phone: user.Phone, Method: models.LOGIN_METHOD_PASSWORD, Success: true})
+
+	app.login(w, user)
+}
+
+// refreshAuth exchanges a refresh token for a new JWT and refresh token. Each refresh token
+// can only be used once.
+func (app *application) refreshAuth(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		RefreshToken string `json:"refreshToken"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		app.badRequest(w, err)
+		return
+	}
+	defer r.Body.Close()
+
+	if body.RefreshToken == "" {
+		app.clientError(w, http.StatusUnauthorized)
+		return
+	}
+
+	user, err := app.users.ConsumeRefreshToken(body.RefreshToken)
+	if err == mysql.ErrNoRefreshTokenFound || err == models.ErrNoRecord {
+		app.clientError(w, http.StatusUnauthorized)
+		return
+	} else if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.login(w, user)
+}
+
+// logout revokes the JWT the request was made with, and the refresh token in the request body
+// if there is one.
+func (app *application) logout(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value(ContextKeyUser).(*Claims)
+
+	var body struct {
+		RefreshToken string `json:"refreshToken"`
+	}
+
+	if r.Body != nil && r.ContentLength != 0 {
+		err := json.NewDecoder(r.Body).Decode(&body)
+		if err != nil {
+			app.badRequest(w, err)
+			return
+		}
+		defer r.Body.Close()
+	}
+
+	if claims.Id != "" {
+		err := app.users.RevokeToken(claims.Id, time.Until(time.Unix(claims.ExpiresAt, 0)))
+		if err != nil {
+			app.serverError(w, err)
+			return
+		}
+	}
+
+	if body.RefreshToken != "" {
+		_, err := app.users.ConsumeRefreshToken(body.RefreshToken)
+		if err != nil && err != mysql.ErrNoRefreshTokenFound && err != models.ErrNoRecord {
+			app.serverError(w, err)
+			return
+		}
+	}
+
+	app.noContentResponse(w)
+}
+
+// requestPasswordReset emails a password reset link to the User with the email address in the
+// request body. It responds the same whether or not there is such a User, so it can't be used to
+// discover who has an account.
+func (app *application) requestPasswordReset(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Email string `json:"email"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		app.badRequest(w, err)
+		return
+	}
+	defer r.Body.Close()
+
+	if body.Email == "" {
+		app.badRequest(w, fmt.Errorf("email is required"))
+		return
+	}
+
+	user, err := app.users.GetByEmail(body.Email)
+	if err == models.ErrNoRecord {
+		app.noContentResponse(w)
+		return
+	} else if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.background(func() {
+		err := app.sendPasswordResetEmail(context.Background(), user)
+		if err != nil {
+			app.errorLog.Output(2, err.Error())
+		}
+	})
+
+	app.noContentResponse(w)
+}
+
+// passwordResetPage is the page the emailed password reset link opens, with a form which posts
+// the new password back to the same URL. Once the password is reset it confirms so instead.
+var passwordResetPage = template.Must(template.New("password-reset").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Reset your password</title></head>
+<body>
+{{if .Reset}}
+<p>Your password has been reset.</p>
+{{else}}
+<form method="post">
+<label>New password <input type="password" name="password" minlength="{{.MinLength}}" required></label>
+<button type="submit">Reset password</button>
+</form>
+{{end}}
+</body>
+</html>
+`))
+
+// passwordResetForm serves the page the emailed password reset link opens. The token isn't used
+// until the form is posted to resetPassword.
+func (app *application) passwordResetForm(w http.ResponseWriter, r *http.Request) {
+	app.passwordResetResponse(w, false)
+}
+
+// passwordResetResponse writes passwordResetPage, confirming the password was reset if `reset`
+func (app *application) passwordResetResponse(w http.ResponseWriter, reset bool) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	err := passwordResetPage.Execute(w, struct {
+		Reset     bool
+		MinLength int
+	}{reset, MIN_PASSWORD_LENGTH})
 	if err != nil {
+		app.errorLog.Output(2, err.Error())
+	}
+}
+
+// resetPassword sets the password of the User the password reset token in the URL was issued
+// to. Each token can only be used once. Following the emailed link proves the User owns their
+// email address, so they are also verified. The password is read from a JSON body, or from the
+// form on the page the emailed link opens, which is answered with that page.
+func (app *application) resetPassword(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Password string `json:"password"`
+	}
+
+	form := strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded")
+	if form {
+		body.Password = r.PostFormValue("password")
+	} else {
+		err := json.NewDecoder(r.Body).Decode(&body)
+		if err != nil {
+			app.badRequest(w, err)
+			return
+		}
+		defer r.Body.Close()
+	}
+
+	if len(body.Password) < MIN_PASSWORD_LENGTH {
+		app.badRequest(w, fmt.Errorf("password must be at least %d characters", MIN_PASSWORD_LENGTH))
+		return
+	}
+
+	user, err := app.users.ConsumePasswordResetToken(r.URL.Query().Get(":token"))
+	if err == mysql.ErrNoPasswordResetTokenFound || err == models.ErrNoRecord {
+		app.notFound(w)
+		return
+	} else if err != nil {
 		app.serverError(w, err)
 		return
 	}
 
-	token, err := generateToken(user)
+	err = app.users.UpdatePassword(user.ID, body.Password)
 	if err != nil {
 		app.serverError(w, err)
 		return
 	}
 
-	claims, err := verifyToken(token)
+	err = app.verifyUser(user)
 	if err != nil {
 		app.serverError(w, err)
 		return
 	}
 
-	exp := time.Unix(claims.ExpiresAt, 0)
+	if form {
+		app.passwordResetResponse(w, true)
+		return
+	}
+
+	app.noContentResponse(w)
+}
 
-	response := struct {
-		Token   string    `json:"token"`
-		Expires time.Time `json:"expires"`
-	}{
-		token,
-		exp,
+// verifyEmail verifies the User the email verification token in the URL was issued to. Each
+// token can only be used once.
+func (app *application) verifyEmail(w http.ResponseWriter, r *http.Request) {
+	user, err := app.users.ConsumeVerificationToken(r.URL.Query().Get(":token"))
+	if err == mysql.ErrNoVerificationTokenFound || err == models.ErrNoRecord {
+		app.notFound(w)
+		return
+	} else if err != nil {
+		app.serverError(w, err)
+		return
 	}
 
-	app.jsonResponse(w, response)
+	err = app.verifyUser(user)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.noContentResponse(w)
+}
+
+// verifyUser moves an unverified User to USER_STATUS_VERIFIED
+func (app *application) verifyUser(user *models.User) error {
+	if user.Status != models.USER_STATUS_UNVERIFIED.Slug() {
+		return nil
+	}
+
+	user.Status = models.USER_STATUS_VERIFIED.Slug()
+	_, err := app.users.Update(user)
+
+	return err
 }
 
 // User handlers
@@ -221,6 +525,15 @@ func (app *application) createUser(w http.ResponseWriter, r *http.Request) {
 	user.Password = ""
 	user.UUID = uid
 
+	if user.Email.String != "" && user.Status == models.USER_STATUS_UNVERIFIED.Slug() {
+		app.background(func() {
+			err := app.sendVerificationEmail(context.Background(), user)
+			if err != nil {
+				app.errorLog.Output(2, err.Error())
+			}
+		})
+	}
+
 	for _, up := range reqUser.Permissions {
 		_, err = app.users.AddPermission(int(user.ID), up.Permission)
 		if err != nil {
@@ -254,6 +567,11 @@ func (app *application) partialUpdateUser(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	if user.Frequency != "" && !user.Frequency.Valid() {
+		app.badRequest(w, fmt.Errorf("invalid notificationFrequency %q", user.Frequency))
+		return
+	}
+
 	user, err = app.users.Update(user)
 	if err != nil {
 		if err == models.ErrDuplicateEmail || err == models.ErrDuplicatePhone {
@@ -291,6 +609,12 @@ func (app *application) getUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	user.Roles, err = app.users.GetRoles(user.ID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
 	app.jsonResponse(w, user)
 }
 
@@ -400,10 +724,18 @@ func (app *application) listUserIngredient(w http.ResponseWriter, r *http.Reques
 	userIngredientResponses := []*UserIngredientBody{}
 
 	for _, ui := range userIngredients {
+		var ingredientID int64
+		if ui.Ingredient != nil {
+			ingredientID = ui.Ingredient.ID
+		}
+
 		userIngredientResponses = append(userIngredientResponses, &UserIngredientBody{
 			ID:           ui.UserIngredientID,
 			UserUUID:     userUUID,
-			IngredientID: ui.Ingredient.ID,
+			IngredientID: ingredientID,
+			StoreIDs:     ui.Scope.StoreIDs,
+			Area:         ui.Scope.Area,
+			Keyword:      ui.Keyword,
 			Created:      ui.Created,
 		})
 	}
@@ -420,99 +752,420 @@ func (app *application) listUserIngredient(w http.ResponseWriter, r *http.Reques
 	return
 }
 
-// Store handlers
-func (app *application) createStore(w http.ResponseWriter, r *http.Request) {
-	var store *models.Store
-	b, err := ioutil.ReadAll(r.Body)
-	defer r.Body.Close()
-
-	if err != nil {
-		app.serverError(w, err)
+// addUserIngredient subscribes the User to an Ingredient or a keyword, optionally limited to
+// some Stores or to an area around a point.
+func (app *application) addUserIngredient(w http.ResponseWriter, r *http.Request) {
+	userUUID, err := uuid.Parse(r.URL.Query().Get(":uuid"))
+	if err != nil || userUUID == uuid.Nil {
+		app.notFound(w)
 		return
 	}
 
-	err = json.Unmarshal(b, &store)
-
+	user, err := app.users.GetByUUID(userUUID)
 	if err != nil {
-		app.serverError(w, err)
+		app.notFound(w)
 		return
 	}
 
-	// Geocode the store
-	err = app.geocodeStore(store)
+	var body *UserIngredientBody
+	err = json.NewDecoder(r.Body).Decode(&body)
 	if err != nil {
-		app.serverError(w, err)
+		app.badRequest(w, err)
 		return
 	}
+	defer r.Body.Close()
 
-	store, err = app.stores.Insert(store.Name, store.Phone, store.Email, store.URL, store.Address, store.City, store.State, store.Zip, store.Lat, store.Lng)
-
-	if err != nil {
-		app.serverError(w, err)
+	body.Keyword = strings.TrimSpace(body.Keyword)
+	if body.IngredientID == 0 && body.Keyword == "" {
+		app.badRequest(w, errors.New("an ingredientId or a keyword is required"))
 		return
 	}
 
-	app.jsonResponse(w, store)
-}
-
-func (app *application) partialUpdateStore(w http.ResponseWriter, r *http.Request) {
-	id, err := strconv.Atoi(r.URL.Query().Get(":id"))
-	if err != nil || id < 1 {
-		app.notFound(w)
+	if len(body.Keyword) > MAX_KEYWORD_LENGTH || (body.Keyword != "" && len(notify.Words(body.Keyword)) == 0) {
+		app.badRequest(w, fmt.Errorf("keyword must contain a word and be at most %d characters", MAX_KEYWORD_LENGTH))
 		return
 	}
 
-	store, err := app.stores.Get(id)
+	var ingredient *models.Ingredient
+	if body.IngredientID > 0 {
+		ingredient, err = app.ingredients.Get(body.IngredientID)
+		if err == models.ErrNoRecord {
+			app.badRequest(w, fmt.Errorf("no ingredient with id %d", body.IngredientID))
+			return
+		} else if err != nil {
+			app.serverError(w, err)
+			return
+		}
+	}
 
+	scope := models.SubscriptionScope{StoreIDs: body.StoreIDs, Area: body.Area}
+	if body.StoreID > 0 {
+		scope.StoreIDs = append([]int64{body.StoreID}, scope.StoreIDs...)
+	}
+
+	for _, storeID := range scope.StoreIDs {
+		_, err = app.stores.Get(int(storeID))
+		if err == models.ErrNoRecord {
+			app.badRequest(w, fmt.Errorf("no store with id %d", storeID))
+			return
+		} else if err != nil {
+			app.serverError(w, err)
+			return
+		}
+	}
+
+	if a := scope.Area; a != nil && (a.RadiusMiles <= 0 || a.Lat < -90 || a.Lat > 90 || a.Lng < -180 || a.Lng > 180) {
+		app.badRequest(w, errors.New("area must have a valid lat and lng and a positive radiusMiles"))
+		return
+	}
+
+	ui, err := app.users.AddIngredient(user.ID, ingredient, body.Keyword, scope)
+	if err == models.ErrDuplicateUserIngredient {
+		app.badRequest(w, err)
+		return
+	} else if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.jsonResponse(w, &UserIngredientBody{
+		ID:           ui.UserIngredientID,
+		UserUUID:     userUUID,
+		IngredientID: body.IngredientID,
+		StoreIDs:     ui.Scope.StoreIDs,
+		Area:         ui.Scope.Area,
+		Keyword:      ui.Keyword,
+		Created:      ui.Created,
+	})
+}
+
+// listUserFlavor lists the Flavors the User is subscribed to
+func (app *application) listUserFlavor(w http.ResponseWriter, r *http.Request) {
+	userUUID, err := uuid.Parse(r.URL.Query().Get(":uuid"))
+	if err != nil || userUUID == uuid.Nil {
+		app.notFound(w)
+		return
+	}
+
+	user, err := app.users.GetByUUID(userUUID)
 	if err != nil {
 		app.notFound(w)
 		return
 	}
 
-	err = json.NewDecoder(r.Body).Decode(&store)
+	userFlavors, err := app.users.GetFlavors(user.ID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
 
-	app.geocodeStore(store)
+	userFlavorResponses := []*UserFlavorBody{}
 
-	store, err = app.stores.Update(id, store.Name, store.Phone, store.Email, store.URL, store.Address, store.City, store.State, store.Zip, store.Lat, store.Lng)
+	for _, uf := range userFlavors {
+		userFlavorResponses = append(userFlavorResponses, &UserFlavorBody{
+			ID:       uf.UserFlavorID,
+			UserUUID: userUUID,
+			FlavorID: uf.Flavor.ID,
+			Created:  uf.Created,
+		})
+	}
+
+	meta := make(map[string]interface{})
+	meta["totalRecords"] = len(userFlavorResponses)
+	meta["count"] = len(userFlavorResponses)
+
+	response := make(map[string]interface{})
+	response["meta"] = meta
+	response["items"] = userFlavorResponses
+
+	app.jsonResponse(w, response)
+}
+
+// addUserFlavor subscribes the User to a Flavor
+func (app *application) addUserFlavor(w http.ResponseWriter, r *http.Request) {
+	userUUID, err := uuid.Parse(r.URL.Query().Get(":uuid"))
+	if err != nil || userUUID == uuid.Nil {
+		app.notFound(w)
+		return
+	}
+
+	user, err := app.users.GetByUUID(userUUID)
+	if err != nil {
+		app.notFound(w)
+		return
+	}
 
+	var body *UserFlavorBody
+	err = json.NewDecoder(r.Body).Decode(&body)
 	if err != nil {
+		app.badRequest(w, err)
+		return
+	}
+	defer r.Body.Close()
+
+	flavor, err := app.flavors.Get(int(body.FlavorID))
+	if err == models.ErrNoRecord {
+		app.badRequest(w, fmt.Errorf("no flavor with id %d", body.FlavorID))
+		return
+	} else if err != nil {
 		app.serverError(w, err)
 		return
 	}
 
-	app.jsonResponse(w, store)
+	uf, err := app.users.AddFlavor(user.ID, flavor)
+	if err == models.ErrDuplicateUserFlavor {
+		app.badRequest(w, err)
+		return
+	} else if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.jsonResponse(w, &UserFlavorBody{
+		ID:       uf.UserFlavorID,
+		UserUUID: userUUID,
+		FlavorID: flavor.ID,
+		Created:  uf.Created,
+	})
 }
 
-func (app *application) updateStore(w http.ResponseWriter, r *http.Request) {
-	id, err := strconv.Atoi(r.URL.Query().Get(":id"))
+// removeUserFlavor unsubscribes the User from the Flavor subscription identified by :id
+func (app *application) removeUserFlavor(w http.ResponseWriter, r *http.Request) {
+	userUUID, err := uuid.Parse(r.URL.Query().Get(":uuid"))
+	if err != nil || userUUID == uuid.Nil {
+		app.notFound(w)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.URL.Query().Get(":id"), 10, 64)
 	if err != nil || id < 1 {
 		app.notFound(w)
 		return
 	}
 
-	var store *models.Store
+	user, err := app.users.GetByUUID(userUUID)
+	if err != nil {
+		app.notFound(w)
+		return
+	}
+
+	userFlavors, err := app.users.GetFlavors(user.ID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	for _, uf := range userFlavors {
+		if uf.UserFlavorID != id {
+			continue
+		}
+
+		err = app.users.RemoveUserFlavor(id)
+		if err != nil && err != models.ErrNoneAffected {
+			app.serverError(w, err)
+			return
+		}
+
+		app.noContentResponse(w)
+		return
+	}
+
+	app.notFound(w)
+}
+
+// listUserRole lists the Roles granted to the User
+func (app *application) listUserRole(w http.ResponseWriter, r *http.Request) {
+	userUUID, err := uuid.Parse(r.URL.Query().Get(":uuid"))
+	if err != nil || userUUID == uuid.Nil {
+		app.notFound(w)
+		return
+	}
 
+	user, err := app.users.GetByUUID(userUUID)
 	if err != nil {
 		app.notFound(w)
 		return
 	}
 
-	err = json.NewDecoder(r.Body).Decode(&store)
+	userRoles, err := app.users.GetRoles(user.ID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
 
-	app.geocodeStore(store)
+	userRoleResponses := []*UserRoleBody{}
 
-	store, err = app.stores.Update(id, store.Name, store.Phone, store.Email, store.URL, store.Address, store.City, store.State, store.Zip, store.Lat, store.Lng)
+	for _, ur := range userRoles {
+		userRoleResponses = append(userRoleResponses, &UserRoleBody{
+			ID:       ur.UserRoleID,
+			UserUUID: userUUID,
+			Role:     ur.Role,
+			StoreID:  ur.StoreID,
+			Created:  ur.Created,
+		})
+	}
+
+	meta := make(map[string]interface{})
+	meta["totalRecords"] = len(userRoleResponses)
+	meta["count"] = len(userRoleResponses)
+
+	response := make(map[string]interface{})
+	response["meta"] = meta
+	response["items"] = userRoleResponses
+
+	app.jsonResponse(w, response)
+}
+
+// addUserRole grants the User a Role, at a single Store if the request body has a storeId
+func (app *application) addUserRole(w http.ResponseWriter, r *http.Request) {
+	userUUID, err := uuid.Parse(r.URL.Query().Get(":uuid"))
+	if err != nil || userUUID == uuid.Nil {
+		app.notFound(w)
+		return
+	}
+
+	user, err := app.users.GetByUUID(userUUID)
+	if err != nil {
+		app.notFound(w)
+		return
+	}
 
+	var body *UserRoleBody
+	err = json.NewDecoder(r.Body).Decode(&body)
 	if err != nil {
+		app.badRequest(w, err)
+		return
+	}
+	defer r.Body.Close()
+
+	if !defaultPolicy.HasRole(body.Role) {
+		app.badRequest(w, fmt.Errorf("%q is not a valid role", body.Role))
+		return
+	}
+
+	if body.StoreID < 0 {
+		app.badRequest(w, fmt.Errorf("no store with id %d", body.StoreID))
+		return
+	} else if body.StoreID > 0 {
+		_, err = app.stores.Get(int(body.StoreID))
+		if err == models.ErrNoRecord {
+			app.badRequest(w, fmt.Errorf("no store with id %d", body.StoreID))
+			return
+		} else if err != nil {
+			app.serverError(w, err)
+			return
+		}
+	}
+
+	ur, err := app.users.AddRole(user.ID, body.Role, body.StoreID)
+	if err == models.ErrDuplicateUserRole {
+		app.badRequest(w, err)
+		return
+	} else if err != nil {
 		app.serverError(w, err)
 		return
 	}
 
-	app.jsonResponse(w, store)
+	app.jsonResponse(w, &UserRoleBody{
+		ID:       ur.UserRoleID,
+		UserUUID: userUUID,
+		Role:     ur.Role,
+		StoreID:  ur.StoreID,
+		Created:  ur.Created,
+	})
 }
 
-func (app *application) listStore(w http.ResponseWriter, r *http.Request) {
-	var err error
+// removeUserRole revokes the User's Role identified by :id
+func (app *application) removeUserRole(w http.ResponseWriter, r *http.Request) {
+	userUUID, err := uuid.Parse(r.URL.Query().Get(":uuid"))
+	if err != nil || userUUID == uuid.Nil {
+		app.notFound(w)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.URL.Query().Get(":id"), 10, 64)
+	if err != nil || id < 1 {
+		app.notFound(w)
+		return
+	}
+
+	user, err := app.users.GetByUUID(userUUID)
+	if err != nil {
+		app.notFound(w)
+		return
+	}
+
+	userRoles, err := app.users.GetRoles(user.ID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	for _, ur := range userRoles {
+		if ur.UserRoleID != id {
+			continue
+		}
+
+		err = app.users.RemoveRole(id)
+		if err != nil && err != models.ErrNoneAffected {
+			app.serverError(w, err)
+			return
+		}
+
+		app.noContentResponse(w)
+		return
+	}
+
+	app.notFound(w)
+}
+
+// sendUserVerification emails the User a link to verify their email address with
+func (app *application) sendUserVerification(w http.ResponseWriter, r *http.Request) {
+	userUUID, err := uuid.Parse(r.URL.Query().Get(":uuid"))
+	if err != nil || userUUID == uuid.Nil {
+		app.notFound(w)
+		return
+	}
+
+	user, err := app.users.GetByUUID(userUUID)
+	if err != nil {
+		app.notFound(w)
+		return
+	}
+
+	if user.Email.String == "" {
+		app.badRequest(w, fmt.Errorf("user has no email address"))
+		return
+	}
+
+	if user.Status != models.USER_STATUS_UNVERIFIED.Slug() {
+		app.badRequest(w, fmt.Errorf("user is already %s", user.Status))
+		return
+	}
+
+	err = app.sendVerificationEmail(r.Context(), user)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.noContentResponse(w)
+}
+
+// listUserMessage lists the Messages sent to the User, with the delivery history of each.
+func (app *application) listUserMessage(w http.ResponseWriter, r *http.Request) {
+	userUUID, err := uuid.Parse(r.URL.Query().Get(":uuid"))
+	if err != nil || userUUID == uuid.Nil {
+		app.notFound(w)
+		return
+	}
+
+	user, err := app.users.GetByUUID(userUUID)
+	if err != nil {
+		app.notFound(w)
+		return
+	}
+
 	params := r.URL.Query()
 
 	l := params.Get("count")
@@ -535,150 +1188,951 @@ func (app *application) listStore(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	sb := "s.name"
+	messages, err := app.messages.ListByUser(user.ID, limit, offset)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	meta := make(map[string]interface{})
+	meta["totalRecords"] = app.messages.CountByUser(user.ID)
+	meta["count"] = len(messages)
+	meta["start"] = offset
+
+	response := make(map[string]interface{})
+	response["meta"] = meta
+	response["items"] = messages
+
+	app.jsonResponse(w, response)
+}
+
+// getNotificationPolicy gets the User's NotificationPolicy, or the default policy if they
+// haven't saved one.
+func (app *application) getNotificationPolicy(w http.ResponseWriter, r *http.Request) {
+	userUUID, err := uuid.Parse(r.URL.Query().Get(":uuid"))
+	if err != nil || userUUID == uuid.Nil {
+		app.notFound(w)
+		return
+	}
+
+	user, err := app.users.GetByUUID(userUUID)
+	if err != nil {
+		app.notFound(w)
+		return
+	}
+
+	policy, err := app.notifications.GetPolicy(user.ID)
+	if err == models.ErrNoRecord {
+		policy = models.DefaultNotificationPolicy(user.ID)
+	} else if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.jsonResponse(w, policy)
+}
+
+// updateNotificationPolicy replaces the User's NotificationPolicy. Quiet hours are "15:04"
+// clock times, or both empty for no quiet hours.
+func (app *application) updateNotificationPolicy(w http.ResponseWriter, r *http.Request) {
+	userUUID, err := uuid.Parse(r.URL.Query().Get(":uuid"))
+	if err != nil || userUUID == uuid.Nil {
+		app.notFound(w)
+		return
+	}
+
+	user, err := app.users.GetByUUID(userUUID)
+	if err != nil {
+		app.notFound(w)
+		return
+	}
+
+	var policy *models.NotificationPolicy
+	err = json.NewDecoder(r.Body).Decode(&policy)
+	if err != nil {
+		app.badRequest(w, err)
+		return
+	}
+	defer r.Body.Close()
+
+	if policy.MaxPerDay < 0 || policy.MinIntervalMinutes < 0 {
+		app.badRequest(w, errors.New("maxPerDay and minIntervalMinutes may not be negative"))
+		return
+	}
+
+	if policy.QuietStart != "" || policy.QuietEnd != "" {
+		for _, clock := range []string{policy.QuietStart, policy.QuietEnd} {
+			if _, err = notify.ParseClock(clock); err != nil {
+				app.badRequest(w, err)
+				return
+			}
+		}
+	}
+
+	policy.UserID = user.ID
+	policy, err = app.notifications.SavePolicy(policy)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.jsonResponse(w, policy)
+}
+
+// Suppression handlers
+func (app *application) listSuppression(w http.ResponseWriter, r *http.Request) {
+	var err error
+	params := r.URL.Query()
+
+	l := params.Get("count")
+	limit := 0
+	if l != "" {
+		limit, err = strconv.Atoi(l)
+		if err != nil {
+			app.serverError(w, err)
+			return
+		}
+	}
+
+	o := params.Get("start")
+	offset := 0
+	if o != "" {
+		offset, err = strconv.Atoi(o)
+		if err != nil {
+			app.serverError(w, err)
+			return
+		}
+	}
+
+	suppressions, err := app.suppressions.List(limit, offset)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	meta := make(map[string]interface{})
+	meta["totalRecords"] = app.suppressions.Count()
+	meta["count"] = len(suppressions)
+	meta["start"] = offset
+
+	response := make(map[string]interface{})
+	response["meta"] = meta
+	response["items"] = suppressions
+
+	app.jsonResponse(w, response)
+}
+
+// createSuppression opts a phone number out of all messages on the customer's behalf.
+func (app *application) createSuppression(w http.ResponseWriter, r *http.Request) {
+	var req *models.Suppression
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		app.badRequest(w, err)
+		return
+	}
+	defer r.Body.Close()
+
+	if mysql.NormalizePhone(req.Phone) == "" {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	suppression, err := app.suppressions.Suppress(req.Phone, req.Keyword)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.jsonResponse(w, suppression)
+}
+
+// deleteSuppression opts a phone number back in to receiving messages.
+func (app *application) deleteSuppression(w http.ResponseWriter, r *http.Request) {
+	err := app.suppressions.Unsuppress(r.URL.Query().Get(":phone"))
+	if err == models.ErrNoneAffected {
+		app.notFound(w)
+		return
+	} else if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.noContentResponse(w)
+}
+
+// Store handlers
+func (app *application) createStore(w http.ResponseWriter, r *http.Request) {
+	var store *models.Store
+	b, err := ioutil.ReadAll(r.Body)
+	defer r.Body.Close()
+
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	err = json.Unmarshal(b, &store)
+
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	// Geocode the store
+	err = app.geocodeStore(store)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	store, err = app.stores.Insert(store.Name, store.Phone, store.Email, store.URL, store.Address, store.City, store.State, store.Zip, store.Lat, store.Lng)
+
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.jsonResponse(w, store)
+}
+
+func (app *application) partialUpdateStore(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.URL.Query().Get(":id"))
+	if err != nil || id < 1 {
+		app.notFound(w)
+		return
+	}
+
+	store, err := app.stores.Get(id)
+
+	if err != nil {
+		app.notFound(w)
+		return
+	}
+
+	err = json.NewDecoder(r.Body).Decode(&store)
+
+	app.geocodeStore(store)
+
+	store, err = app.stores.Update(id, store.Name, store.Phone, store.Email, store.URL, store.Address, store.City, store.State, store.Zip, store.Lat, store.Lng)
+
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.jsonResponse(w, store)
+}
+
+func (app *application) updateStore(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.URL.Query().Get(":id"))
+	if err != nil || id < 1 {
+		app.notFound(w)
+		return
+	}
+
+	var store *models.Store
+
+	if err != nil {
+		app.notFound(w)
+		return
+	}
+
+	err = json.NewDecoder(r.Body).Decode(&store)
+
+	app.geocodeStore(store)
+
+	store, err = app.stores.Update(id, store.Name, store.Phone, store.Email, store.URL, store.Address, store.City, store.State, store.Zip, store.Lat, store.Lng)
+
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.jsonResponse(w, store)
+}
+
+func (app *application) listStore(w http.ResponseWriter, r *http.Request) {
+	var err error
+	params := r.URL.Query()
+
+	l := params.Get("count")
+	limit := 0
+	if l != "" {
+		limit, err = strconv.Atoi(l)
+		if err != nil {
+			app.serverError(w, err)
+			return
+		}
+	}
+
+	o := params.Get("start")
+	offset := 0
+	if o != "" {
+		offset, err = strconv.Atoi(o)
+		if err != nil {
+			app.serverError(w, err)
+			return
+		}
+	}
+
+	sb := "s.name"
+
+	stores, err := app.stores.List(limit, offset, sb)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	meta := make(map[string]interface{})
+	meta["totalRecords"] = app.stores.Count()
+	meta["count"] = len(stores)
+	meta["start"] = offset
+	meta["sortBy"] = sb
+
+	response := make(map[string]interface{})
+	response["meta"] = meta
+	response["items"] = stores
+
+	app.jsonResponse(w, response)
+}
+
+func (app *application) getStore(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.URL.Query().Get(":id"))
+	if err != nil || id < 1 {
+		app.notFound(w)
+		return
+	}
+
+	store, err := app.stores.Get(id)
+
+	if err == models.ErrNoRecord {
+		app.notFound(w)
+		return
+	} else if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.jsonResponse(w, store)
+}
+
+func (app *application) activateStoreFlavor(w http.ResponseWriter, r *http.Request) {
+	storeID, err := strconv.Atoi(r.URL.Query().Get(":storeID"))
+	if err != nil || storeID < 1 {
+		app.notFound(w)
+		return
+	}
+
+	flavorID, err := strconv.Atoi(r.URL.Query().Get(":flavorID"))
+	if err != nil || flavorID < 1 {
+		app.notFound(w)
+		return
+	}
+
+	type activationRequestBody struct {
+		StoreID  int64     `json:"store_id"`
+		FlavorID int64     `json:"flavor_id"`
+		Position int       `json:"position"`
+		Created  time.Time `json:"created,omitempty"`
+	}
+
+	var req activationRequestBody
+
+	err = json.NewDecoder(r.Body).Decode(&req)
+
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	if req.FlavorID != int64(flavorID) {
+		app.errorLog.Output(2, fmt.Sprintf("Request body flavor_id (%d) must match URL query :flavorID (%d)", req.FlavorID, flavorID))
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	if req.StoreID != int64(storeID) {
+		app.errorLog.Output(2, fmt.Sprintf("Request body store_id (%d) must match URL query :storeID (%d)", req.StoreID, storeID))
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	// Is it even an available Flavor?
+	f, err := app.flavors.Get(flavorID)
+	if err == models.ErrNoRecord {
+		app.clientError(w, http.StatusNotFound)
+		return
+	} else if err != nil {
+		app.serverError(w, err)
+		return
+	}
+	req.FlavorID = f.ID
+
+	// Is it a valid store?
+	s, err := app.stores.Get(storeID)
+	if err == models.ErrNoRecord {
+		app.clientError(w, http.StatusNotFound)
+		return
+	} else if err != nil {
+		app.serverError(w, err)
+		return
+	}
+	req.StoreID = s.ID
+
+	// Make the association link
+	var conflict *models.LayoutConflictError
+	err = app.stores.ActivateFlavor(s.ID, f.ID, req.Position)
+	if err == models.ErrDuplicateFlavor || err == models.ErrFlavorConflict || errors.As(err, &conflict) {
+		app.errorLog.Output(2, err.Error())
+		app.clientError(w, http.StatusConflict)
+		return
+	} else if err != nil {
+		app.serverError(w, err)
+		return
+	}
+	req.Created = time.Now()
+
+	// Let subscribed Users know the Flavor is available
+	app.background(func() {
+		n, err := app.notifier.FlavorActivated(context.Background(), s, f)
+		if err != nil {
+			app.errorLog.Output(2, err.Error())
+		}
+		app.infoLog.Printf("Notified %d users of %s at %s", n, f.Name, s.Name)
+	})
+
+	app.jsonResponse(w, req)
+}
+
+func (app *application) deactivateStoreFlavor(w http.ResponseWriter, r *http.Request) {
+	storeID, err := strconv.Atoi(r.URL.Query().Get(":storeID"))
+	if err != nil || storeID < 1 {
+		app.notFound(w)
+		return
+	}
+
+	flavorID, err := strconv.Atoi(r.URL.Query().Get(":flavorID"))
+	if err != nil || flavorID < 1 {
+		app.notFound(w)
+		return
+	}
+
+	_, err = app.stores.DeactivateFlavor(int64(storeID), int64(flavorID))
+	if err != nil {
+		app.errorLog.Output(2, err.Error())
+		app.clientError(w, http.StatusBadRequest)
+	}
+
+	app.noContentResponse(w)
+}
+
+// replaceStoreFlavors replaces the Flavors active at the Store with the lineup in the request
+// body, which maps each Position available in the Store's Layout to the ID of the Flavor to be
+// active in it. Positions left out are emptied. Every change is made together, and subscribed
+// Users are notified of Flavors which weren't already active at the Store.
+func (app *application) replaceStoreFlavors(w http.ResponseWriter, r *http.Request) {
+	storeID, err := strconv.Atoi(r.URL.Query().Get(":storeID"))
+	if err != nil || storeID < 1 {
+		app.notFound(w)
+		return
+	}
+
+	var lineup map[int]int64
+	err = json.NewDecoder(r.Body).Decode(&lineup)
+	if err != nil {
+		app.badRequest(w, err)
+		return
+	}
+
+	store, err := app.stores.Get(storeID)
+	if err == models.ErrNoRecord {
+		app.notFound(w)
+		return
+	} else if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	flavors := map[int64]*models.Flavor{}
+	for position, flavorID := range lineup {
+		if _, ok := flavors[flavorID]; ok {
+			continue
+		}
+
+		f, err := app.flavors.Get(int(flavorID))
+		if err == models.ErrNoRecord {
+			app.badRequest(w, fmt.Errorf("flavor %d in position %d not found", flavorID, position))
+			return
+		} else if err != nil {
+			app.serverError(w, err)
+			return
+		}
+		flavors[flavorID] = f
+	}
+
+	var conflict *models.LayoutConflictError
+	diff, err := app.stores.ReplaceLineup(store.ID, lineup)
+	if err == models.ErrDuplicateFlavor || err == models.ErrFlavorConflict || errors.As(err, &conflict) {
+		app.errorLog.Output(2, err.Error())
+		app.clientError(w, http.StatusConflict)
+		return
+	} else if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	wasActive := map[int64]bool{}
+	for _, lf := range diff.Unchanged {
+		wasActive[lf.Flavor.ID] = true
+	}
+	for _, lf := range diff.Deactivated {
+		wasActive[lf.Flavor.ID] = true
+	}
+
+	activated := []*models.Flavor{}
+	for _, lf := range diff.Activated {
+		lf.Flavor = flavors[lf.Flavor.ID]
+		if !wasActive[lf.Flavor.ID] {
+			activated = append(activated, lf.Flavor)
+			wasActive[lf.Flavor.ID] = true
+		}
+	}
+
+	// Let subscribed Users know the Flavors are available, one Flavor at a time
+	if len(activated) > 0 {
+		app.background(func() {
+			for _, f := range activated {
+				n, err := app.notifier.FlavorActivated(context.Background(), store, f)
+				if err != nil {
+					app.errorLog.Output(2, err.Error())
+				}
+				app.infoLog.Printf("Notified %d users of %s at %s", n, f.Name, store.Name)
+			}
+		})
+	}
+
+	app.jsonResponse(w, diff)
+}
+
+// LayoutBody is the Layout of a Store's cooler, as set by staff. Slots need only be given for
+// Positions which are labeled or disabled.
+type LayoutBody struct {
+	Positions int           `json:"positions"`
+	Slots     []models.Slot `json:"slots"`
+}
+
+// getStoreLayout gets the Layout of the Store's cooler, with a Slot for each Position
+func (app *application) getStoreLayout(w http.ResponseWriter, r *http.Request) {
+	storeID, err := strconv.ParseInt(r.URL.Query().Get(":storeID"), 10, 64)
+	if err != nil || storeID < 1 {
+		app.notFound(w)
+		return
+	}
+
+	_, err = app.stores.Get(int(storeID))
+	if err == models.ErrNoRecord {
+		app.notFound(w)
+		return
+	} else if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	layout, err := app.stores.GetLayout(storeID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.jsonResponse(w, layout)
+}
+
+// updateStoreLayout replaces the Layout of the Store's cooler. A Layout which would leave an
+// active Flavor in a Position which is disabled, or no longer in the cooler, conflicts with the
+// Store's lineup, and the Flavor must be deactivated first.
+func (app *application) updateStoreLayout(w http.ResponseWriter, r *http.Request) {
+	storeID, err := strconv.ParseInt(r.URL.Query().Get(":storeID"), 10, 64)
+	if err != nil || storeID < 1 {
+		app.notFound(w)
+		return
+	}
+
+	var body LayoutBody
+	err = json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		app.badRequest(w, err)
+		return
+	}
+
+	err = validateLayout(body)
+	if err != nil {
+		app.badRequest(w, err)
+		return
+	}
+
+	_, err = app.stores.Get(int(storeID))
+	if err == models.ErrNoRecord {
+		app.notFound(w)
+		return
+	} else if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	layout := models.NewLayout(storeID, body.Positions, body.Slots)
+
+	var conflict *models.LayoutConflictError
+	err = app.stores.SaveLayout(layout)
+	if errors.As(err, &conflict) {
+		app.errorLog.Output(2, err.Error())
+		app.clientError(w, http.StatusConflict)
+		return
+	} else if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.jsonResponse(w, layout)
+}
+
+// validateLayout checks the Layout has between 1 and MAX_POSITIONS Positions, and a Slot for each
+// Position at most, each with a unique label no longer than MAX_SLOT_LABEL_LENGTH.
+func validateLayout(body LayoutBody) error {
+	if body.Positions < 1 || body.Positions > models.MAX_POSITIONS {
+		return fmt.Errorf("positions must be between 1 and %d", models.MAX_POSITIONS)
+	}
+
+	positions := map[int]bool{}
+	labels := map[string]bool{}
+
+	for _, slot := range body.Slots {
+		if slot.Position < 1 || slot.Position > body.Positions {
+			return fmt.Errorf("%w: %d", models.ErrInvalidPosition, slot.Position)
+		}
+		if positions[slot.Position] {
+			return fmt.Errorf("position %d has more than one slot", slot.Position)
+		}
+		positions[slot.Position] = true
+
+		if len(slot.Label) > models.MAX_SLOT_LABEL_LENGTH {
+			return fmt.Errorf("label %q is longer than %d characters", slot.Label, models.MAX_SLOT_LABEL_LENGTH)
+		}
+		if slot.Label != "" && labels[slot.Label] {
+			return fmt.Errorf("label %q is used more than once", slot.Label)
+		}
+		labels[slot.Label] = true
+	}
+
+	return nil
+}
+
+// Inventory handlers
+
+// BarrelBody is a Barrel active at a Store, with the estimate of when it will be empty
+type BarrelBody struct {
+	ID        int64          `json:"id"`
+	Position  int            `json:"position"`
+	Flavor    *models.Flavor `json:"flavor"`
+	Activated time.Time      `json:"activated"`
+	inventory.BarrelEstimate
+}
+
+// listStoreInventory lists the Barrels active at the Store with their latest levels and
+// estimates of when they'll be empty, and totals them by Flavor.
+func (app *application) listStoreInventory(w http.ResponseWriter, r *http.Request) {
+	storeID, err := strconv.ParseInt(r.URL.Query().Get(":storeID"), 10, 64)
+	if err != nil || storeID < 1 {
+		app.notFound(w)
+		return
+	}
+
+	_, err = app.stores.Get(int(storeID))
+	if err == models.ErrNoRecord {
+		app.notFound(w)
+		return
+	} else if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	lowLevel, err := app.inventory.GetLowLevel(storeID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	barrels, err := app.inventory.ListBarrels(storeID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	barrelResponses := []*BarrelBody{}
+	estimates := []inventory.BarrelEstimate{}
+
+	for _, b := range barrels {
+		e := inventory.Estimate(b, lowLevel)
+		estimates = append(estimates, e)
+		barrelResponses = append(barrelResponses, &BarrelBody{
+			ID:             b.ID,
+			Position:       b.Position,
+			Flavor:         b.Flavor,
+			Activated:      b.Activated,
+			BarrelEstimate: e,
+		})
+	}
+
+	meta := make(map[string]interface{})
+	meta["totalRecords"] = len(barrelResponses)
+	meta["count"] = len(barrelResponses)
+	meta["lowLevel"] = lowLevel
+
+	response := make(map[string]interface{})
+	response["meta"] = meta
+	response["items"] = barrelResponses
+	response["flavors"] = inventory.ByFlavor(barrels, estimates)
+
+	app.jsonResponse(w, response)
+}
+
+// recordBarrelLevel records the fill level, in percent, of the Barrel active in the Position at
+// the Store. Staff at the Store are alerted when a Barrel falls below the Store's low level.
+func (app *application) recordBarrelLevel(w http.ResponseWriter, r *http.Request) {
+	storeID, err := strconv.ParseInt(r.URL.Query().Get(":storeID"), 10, 64)
+	if err != nil || storeID < 1 {
+		app.notFound(w)
+		return
+	}
+
+	position, err := strconv.Atoi(r.URL.Query().Get(":position"))
+	if err != nil || position < 0 {
+		app.notFound(w)
+		return
+	}
+
+	var body struct {
+		Level *int `json:"level"`
+	}
+
+	err = json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		app.badRequest(w, err)
+		return
+	}
+	defer r.Body.Close()
+
+	if body.Level == nil || *body.Level < 0 || *body.Level > inventory.FULL {
+		app.badRequest(w, fmt.Errorf("level must be between 0 and %d", inventory.FULL))
+		return
+	}
+
+	store, err := app.stores.Get(int(storeID))
+	if err == models.ErrNoRecord {
+		app.notFound(w)
+		return
+	} else if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	barrel, err := app.inventory.GetBarrel(storeID, position)
+	if err == models.ErrNoRecord {
+		app.notFound(w)
+		return
+	} else if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	lowLevel, err := app.inventory.GetLowLevel(storeID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	claims := r.Context().Value(ContextKeyUser).(*Claims)
+
+	var userID int64
+	if userUUID, err := uuid.Parse(claims.UUID); err == nil {
+		if user, err := app.users.GetByUUID(userUUID); err == nil {
+			userID = user.ID
+		}
+	}
+
+	crossedLow := inventory.CrossedLow(barrel, *body.Level, lowLevel)
 
-	stores, err := app.stores.List(limit, offset, sb)
+	bl, err := app.inventory.RecordLevel(barrel.ID, *body.Level, userID)
 	if err != nil {
 		app.serverError(w, err)
 		return
 	}
+	barrel.Levels = append(barrel.Levels, *bl)
 
-	meta := make(map[string]interface{})
-	meta["totalRecords"] = app.stores.Count()
-	meta["count"] = len(stores)
-	meta["start"] = offset
-	meta["sortBy"] = sb
+	estimate := inventory.Estimate(barrel, lowLevel)
 
-	response := make(map[string]interface{})
-	response["meta"] = meta
-	response["items"] = stores
+	if crossedLow {
+		app.background(func() {
+			app.alertLowBarrel(context.Background(), store, barrel, estimate)
+		})
+	}
 
-	app.jsonResponse(w, response)
+	app.jsonResponse(w, &BarrelBody{
+		ID:             barrel.ID,
+		Position:       barrel.Position,
+		Flavor:         barrel.Flavor,
+		Activated:      barrel.Activated,
+		BarrelEstimate: estimate,
+	})
 }
 
-func (app *application) getStore(w http.ResponseWriter, r *http.Request) {
-	id, err := strconv.Atoi(r.URL.Query().Get(":id"))
-	if err != nil || id < 1 {
+// updateInventoryThreshold sets the level, in percent, below which Barrels at the Store are low
+func (app *application) updateInventoryThreshold(w http.ResponseWriter, r *http.Request) {
+	storeID, err := strconv.ParseInt(r.URL.Query().Get(":storeID"), 10, 64)
+	if err != nil || storeID < 1 {
 		app.notFound(w)
 		return
 	}
 
-	store, err := app.stores.Get(id)
+	var body struct {
+		LowLevel *int `json:"lowLevel"`
+	}
 
-	if err == models.ErrNoRecord {
-		app.notFound(w)
-		return
-	} else if err != nil {
-		app.serverError(w, err)
+	err = json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		app.badRequest(w, err)
 		return
 	}
+	defer r.Body.Close()
 
-	app.jsonResponse(w, store)
-}
-
-func (app *application) activateStoreFlavor(w http.ResponseWriter, r *http.Request) {
-	storeID, err := strconv.Atoi(r.URL.Query().Get(":storeID"))
-	if err != nil || storeID < 1 {
-		app.notFound(w)
+	if body.LowLevel == nil || *body.LowLevel < 0 || *body.LowLevel > inventory.FULL {
+		app.badRequest(w, fmt.Errorf("lowLevel must be between 0 and %d", inventory.FULL))
 		return
 	}
 
-	flavorID, err := strconv.Atoi(r.URL.Query().Get(":flavorID"))
-	if err != nil || flavorID < 1 {
+	_, err = app.stores.Get(int(storeID))
+	if err == models.ErrNoRecord {
 		app.notFound(w)
 		return
+	} else if err != nil {
+		app.serverError(w, err)
+		return
 	}
 
-	type activationRequestBody struct {
-		StoreID  int64     `json:"store_id"`
-		FlavorID int64     `json:"flavor_id"`
-		Position int       `json:"position"`
-		Created  time.Time `json:"created,omitempty"`
+	err = app.inventory.SetLowLevel(storeID, *body.LowLevel)
+	if err != nil {
+		app.serverError(w, err)
+		return
 	}
 
-	var req activationRequestBody
+	app.jsonResponse(w, body)
+}
 
-	err = json.NewDecoder(r.Body).Decode(&req)
+// Flavor handlers
+func (app *application) createFlavor(w http.ResponseWriter, r *http.Request) {
+	var flavor = &models.Flavor{}
+	err := json.NewDecoder(r.Body).Decode(&flavor)
 
 	if err != nil {
 		app.serverError(w, err)
 		return
 	}
+	defer r.Body.Close()
 
-	if req.FlavorID != int64(flavorID) {
-		app.errorLog.Output(2, fmt.Sprintf("Request body flavor_id (%d) must match URL query :flavorID (%d)", req.FlavorID, flavorID))
-		app.clientError(w, http.StatusBadRequest)
+	flavor, err = app.flavors.Insert(flavor)
+	if err != nil {
+		app.serverError(w, err)
 		return
 	}
 
-	if req.StoreID != int64(storeID) {
-		app.errorLog.Output(2, fmt.Sprintf("Request body store_id (%d) must match URL query :storeID (%d)", req.StoreID, storeID))
-		app.clientError(w, http.StatusBadRequest)
+	app.jsonResponse(w, flavor)
+}
+
+func (app *application) getFlavor(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.URL.Query().Get(":id"))
+	if err != nil || id < 1 {
+		app.notFound(w)
 		return
 	}
 
-	// Is it even an available Flavor?
-	f, err := app.flavors.Get(flavorID)
+	flavor, err := app.flavors.Get(id)
+
 	if err == models.ErrNoRecord {
-		app.clientError(w, http.StatusNotFound)
+		app.notFound(w)
+		return
+	} else if err != nil {
+		app.serverError(w, err)
 		return
 	}
-	req.FlavorID = f.ID
 
-	// Is it a valid store?
-	s, err := app.stores.Get(storeID)
-	if err == models.ErrNoRecord {
-		app.clientError(w, http.StatusNotFound)
+	app.jsonResponse(w, flavor)
+}
+
+// updateFlavor replaces the name, description and Ingredients of the Flavor
+func (app *application) updateFlavor(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.URL.Query().Get(":id"))
+	if err != nil || id < 1 {
+		app.notFound(w)
 		return
 	}
-	req.StoreID = s.ID
 
-	// Make the association link
-	err = app.stores.ActivateFlavor(s.ID, f.ID, req.Position)
+	var flavor = &models.Flavor{}
+	err = json.NewDecoder(r.Body).Decode(&flavor)
 	if err != nil {
-		app.serverError(w, err)
+		app.badRequest(w, err)
 		return
 	}
-	req.Created = time.Now()
+	defer r.Body.Close()
 
-	app.jsonResponse(w, req)
+	app.saveFlavor(w, id, flavor)
 }
 
-func (app *application) deactivateStoreFlavor(w http.ResponseWriter, r *http.Request) {
-	storeID, err := strconv.Atoi(r.URL.Query().Get(":storeID"))
-	if err != nil || storeID < 1 {
+// partialUpdateFlavor updates the fields of the Flavor given in the request body. Ingredients,
+// if given, replace all of the Flavor's Ingredients.
+func (app *application) partialUpdateFlavor(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.URL.Query().Get(":id"))
+	if err != nil || id < 1 {
 		app.notFound(w)
 		return
 	}
 
-	flavorID, err := strconv.Atoi(r.URL.Query().Get(":flavorID"))
-	if err != nil || flavorID < 1 {
+	flavor, err := app.flavors.Get(id)
+	if err == models.ErrNoRecord {
 		app.notFound(w)
 		return
+	} else if err != nil {
+		app.serverError(w, err)
+		return
 	}
 
-	_, err = app.stores.DeactivateFlavor(int64(storeID), int64(flavorID))
+	err = json.NewDecoder(r.Body).Decode(&flavor)
 	if err != nil {
-		app.errorLog.Output(2, err.Error())
-		app.clientError(w, http.StatusBadRequest)
+		app.badRequest(w, err)
+		return
 	}
+	defer r.Body.Close()
 
-	app.noContentResponse(w)
+	app.saveFlavor(w, id, flavor)
 }
 
-// Flavor handlers
-func (app *application) createFlavor(w http.ResponseWriter, r *http.Request) {
-	var flavor = &models.Flavor{}
-	err := json.NewDecoder(r.Body).Decode(&flavor)
-
-	if err != nil {
-		app.serverError(w, err)
+func (app *application) saveFlavor(w http.ResponseWriter, id int, flavor *models.Flavor) {
+	flavor.Name = strings.TrimSpace(flavor.Name)
+	if flavor.Name == "" {
+		app.badRequest(w, errors.New("a flavor must have a name"))
 		return
 	}
-	defer r.Body.Close()
 
-	flavor, err = app.flavors.Insert(flavor)
-	if err != nil {
+	for _, i := range flavor.Ingredients {
+		if strings.TrimSpace(i.Name) == "" {
+			app.badRequest(w, errors.New("each ingredient must have a name"))
+			return
+		}
+	}
+
+	flavor, err := app.flavors.Update(id, flavor)
+	if err == models.ErrNoRecord {
+		app.notFound(w)
+		return
+	} else if err != nil {
 		app.serverError(w, err)
 		return
 	}
@@ -686,24 +2140,29 @@ func (app *application) createFlavor(w http.ResponseWriter, r *http.Request) {
 	app.jsonResponse(w, flavor)
 }
 
-func (app *application) getFlavor(w http.ResponseWriter, r *http.Request) {
+// deleteFlavor deletes the Flavor, unless it is active at a Store or has been served before.
+func (app *application) deleteFlavor(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.Atoi(r.URL.Query().Get(":id"))
 	if err != nil || id < 1 {
 		app.notFound(w)
 		return
 	}
 
-	flavor, err := app.flavors.Get(id)
-
-	if err == models.ErrNoRecord {
-		app.notFound(w)
+	ok, err := app.flavors.Delete(id)
+	if err == models.ErrActiveFlavor || err == models.ErrFlavorInUse {
+		app.clientError(w, http.StatusConflict)
 		return
 	} else if err != nil {
 		app.serverError(w, err)
 		return
 	}
 
-	app.jsonResponse(w, flavor)
+	if !ok {
+		app.notFound(w)
+		return
+	}
+
+	app.noContentResponse(w)
 }
 
 func (app *application) listFlavor(w http.ResponseWriter, r *http.Request) {
@@ -830,3 +2289,196 @@ func (app *application) listIngredient(w http.ResponseWriter, r *http.Request) {
 
 	app.jsonResponse(w, response)
 }
+
+// storeReport summarizes the Flavors rotated through each Store between the `from` and `to`
+// query params, optionally only those of the Flavor identified by the `flavorId` query param.
+func (app *application) storeReport(w http.ResponseWriter, r *http.Request) {
+	from, to, err := reportRange(r)
+	if err != nil {
+		app.badRequest(w, err)
+		return
+	}
+
+	flavorID, err := queryID(r, "flavorId")
+	if err != nil {
+		app.badRequest(w, err)
+		return
+	}
+
+	rotations, err := app.stores.ListRotations(from, to, 0, flavorID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	stores := report.ByStore(rotations)
+
+	app.jsonResponse(w, reportResponse(from, to, len(stores), stores))
+}
+
+// flavorReport summarizes the rotations of each Flavor between the `from` and `to` query params,
+// optionally only those at the Store identified by the `storeId` query param.
+func (app *application) flavorReport(w http.ResponseWriter, r *http.Request) {
+	from, to, err := reportRange(r)
+	if err != nil {
+		app.badRequest(w, err)
+		return
+	}
+
+	storeID, err := queryID(r, "storeId")
+	if err != nil {
+		app.badRequest(w, err)
+		return
+	}
+
+	rotations, err := app.stores.ListRotations(from, to, storeID, 0)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	flavors := report.ByFlavor(rotations)
+
+	app.jsonResponse(w, reportResponse(from, to, len(flavors), flavors))
+}
+
+// storeFlavorReport summarizes the rotations of each Flavor at the Store between the `from` and
+// `to` query params, with a summary of every rotation at the Store.
+func (app *application) storeFlavorReport(w http.ResponseWriter, r *http.Request) {
+	store, rotations, from, to, ok := app.storeRotations(w, r)
+	if !ok {
+		return
+	}
+
+	flavors := report.ByFlavor(rotations)
+
+	response := reportResponse(from, to, len(flavors), flavors)
+	response["store"] = &report.StoreStats{
+		StoreID:   store.ID,
+		StoreName: store.Name,
+		Stats:     report.Summarize(rotations),
+	}
+
+	app.jsonResponse(w, response)
+}
+
+// storeTimeline lists the Flavors which occupied each Position at the Store between the `from`
+// and `to` query params.
+func (app *application) storeTimeline(w http.ResponseWriter, r *http.Request) {
+	_, rotations, from, to, ok := app.storeRotations(w, r)
+	if !ok {
+		return
+	}
+
+	timeline := report.Timeline(rotations)
+
+	app.jsonResponse(w, reportResponse(from, to, len(timeline), timeline))
+}
+
+// storeRotations gets the Store identified by the `:storeID` param and the Rotations at it in the
+// requested range, writing an error response and returning false if they can't be found.
+func (app *application) storeRotations(w http.ResponseWriter, r *http.Request) (*models.Store, []*models.Rotation, time.Time, time.Time, bool) {
+	storeID, err := strconv.ParseInt(r.URL.Query().Get(":storeID"), 10, 64)
+	if err != nil || storeID < 1 {
+		app.notFound(w)
+		return nil, nil, time.Time{}, time.Time{}, false
+	}
+
+	from, to, err := reportRange(r)
+	if err != nil {
+		app.badRequest(w, err)
+		return nil, nil, time.Time{}, time.Time{}, false
+	}
+
+	store, err := app.stores.Get(int(storeID))
+	if err == models.ErrNoRecord {
+		app.notFound(w)
+		return nil, nil, time.Time{}, time.Time{}, false
+	} else if err != nil {
+		app.serverError(w, err)
+		return nil, nil, time.Time{}, time.Time{}, false
+	}
+
+	rotations, err := app.stores.ListRotations(from, to, storeID, 0)
+	if err != nil {
+		app.serverError(w, err)
+		return nil, nil, time.Time{}, time.Time{}, false
+	}
+
+	return store, rotations, from, to, true
+}
+
+// reportResponse is the response to a report request covering `from` to `to`
+func reportResponse(from time.Time, to time.Time, count int, items interface{}) map[string]interface{} {
+	meta := make(map[string]interface{})
+	meta["totalRecords"] = count
+	meta["count"] = count
+	meta["from"] = from
+	meta["to"] = to
+
+	response := make(map[string]interface{})
+	response["meta"] = meta
+	response["items"] = items
+
+	return response
+}
+
+// queryID parses the ID in the query param, which is 0 if it's not given
+func queryID(r *http.Request, param string) (int64, error) {
+	v := r.URL.Query().Get(param)
+	if v == "" {
+		return 0, nil
+	}
+
+	id, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || id < 1 {
+		return 0, fmt.Errorf("invalid %s: %q", param, v)
+	}
+
+	return id, nil
+}
+
+// listCooler lists the Flavors active at every Store, ordered by Position. It's public, so the
+// website and in-store screens can poll it, and cached by clients until the lineup changes.
+func (app *application) listCooler(w http.ResponseWriter, r *http.Request) {
+	lineups, err := app.stores.ListLineups(0)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	meta := make(map[string]interface{})
+	meta["totalRecords"] = len(lineups)
+	meta["count"] = len(lineups)
+
+	response := make(map[string]interface{})
+	response["meta"] = meta
+	response["items"] = lineups
+
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	app.cachedJSONResponse(w, r, response, COOLER_MAX_AGE)
+}
+
+// getCooler gets the Flavors active at the Store, ordered by Position. Like listCooler, it's
+// public and cached by clients until the lineup changes.
+func (app *application) getCooler(w http.ResponseWriter, r *http.Request) {
+	storeID, err := strconv.ParseInt(r.URL.Query().Get(":storeID"), 10, 64)
+	if err != nil || storeID < 1 {
+		app.notFound(w)
+		return
+	}
+
+	lineups, err := app.stores.ListLineups(storeID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	if len(lineups) == 0 {
+		app.notFound(w)
+		return
+	}
+
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	app.cachedJSONResponse(w, r, lineups[0], COOLER_MAX_AGE)
+}
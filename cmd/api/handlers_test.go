@@ -2,17 +2,26 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 
 	"github.com/jcorry/morellis/pkg/models"
+	"github.com/jcorry/morellis/pkg/models/mysql"
+	"github.com/jcorry/morellis/pkg/report"
 	"github.com/jcorry/morellis/pkg/sms"
 	"github.com/jcorry/morellis/pkg/sms/smsfakes"
 )
@@ -431,6 +440,43 @@ func TestActivateStoreFlavor(t *testing.T) {
 	}
 }
 
+func TestActivateStoreFlavorConflict(t *testing.T) {
+	tests := []struct {
+		name     string
+		position int
+		err      error
+		wantCode int
+	}{
+		{"activated", 3, nil, http.StatusOK},
+		{"concurrent change", 3, models.ErrFlavorConflict, http.StatusConflict},
+		{"position occupied", 3, models.ErrDuplicateFlavor, http.StatusConflict},
+		{"database error", 3, errors.New("connection lost"), http.StatusInternalServerError},
+		{"disabled position", 2, &models.LayoutConflictError{StoreID: 1, Position: 2}, http.StatusConflict},
+		{"position outside the layout", 5, &models.LayoutConflictError{StoreID: 1, Position: 5}, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			app, fakes := newFakeApplication()
+			fakes.stores.GetReturns(&models.Store{ID: 1, Name: "Morelli's Ice Cream"}, nil)
+			fakes.stores.ActivateFlavorReturns(tt.err)
+
+			fakes.flavors.GetReturns(&models.Flavor{ID: 2, Name: "Vanilla"}, nil)
+
+			body := fmt.Sprintf(`{"store_id":1,"flavor_id":2,"position":%d}`, tt.position)
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/store/1/flavor/2?:storeID=1&:flavorID=2", strings.NewReader(body))
+			res := NewFakeResponse(t)
+			app.activateStoreFlavor(res, req)
+
+			require.Equal(t, tt.wantCode, res.status)
+			storeID, _, position := fakes.stores.ActivateFlavorArgsForCall(0)
+			require.Equal(t, int64(1), storeID)
+			require.Equal(t, tt.position, position)
+		})
+	}
+}
+
 func TestDeactivateStoreFlavor(t *testing.T) {
 	app := newTestApplication(t)
 	ts := newTestServer(t, app.routes())
@@ -574,3 +620,976 @@ func TestListStore(t *testing.T) {
 		t.Errorf("want %d, got %d", 200, code)
 	}
 }
+
+func TestSmsStatus(t *testing.T) {
+	reqUrl, err := url.Parse(fmt.Sprintf("%s/webhooks/v1/sms/status", os.Getenv("HOST")))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		form       url.Values
+		message    *models.Message
+		messageErr error
+		wantCode   int
+		wantEvents int
+	}{
+		{"delivered", url.Values{"MessageSid": {"SM123"}, "MessageStatus": {"delivered"}}, &models.Message{ID: 42}, nil, http.StatusNoContent, 1},
+		{"undelivered", url.Values{"MessageSid": {"SM123"}, "MessageStatus": {"undelivered"}, "ErrorCode": {"30003"}}, &models.Message{ID: 42}, nil, http.StatusNoContent, 1},
+		{"unknown message", url.Values{"MessageSid": {"SM999"}, "MessageStatus": {"sent"}}, nil, models.ErrNoRecord, http.StatusNotFound, 0},
+		{"no status", url.Values{"MessageSid": {"SM123"}}, &models.Message{ID: 42}, nil, http.StatusBadRequest, 0},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			app, fakes := newFakeApplication()
+			fakes.messages.GetBySIDReturns(tt.message, tt.messageErr)
+
+			sig := sms.GetExpectedTwilioSignature(os.Getenv("HOST"), os.Getenv("TWILIO_AUTH_TOKEN"), reqUrl.String(), tt.form)
+
+			req := httptest.NewRequest(http.MethodPost, reqUrl.String(), strings.NewReader(tt.form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.Header.Set("X-Twilio-Signature", sig)
+
+			res := NewFakeResponse(t)
+			app.smsStatus(res, req)
+
+			require.Equal(t, tt.wantCode, res.status)
+			require.Equal(t, tt.wantEvents, fakes.messages.AddEventCallCount())
+			if tt.wantEvents > 0 {
+				id, status, code := fakes.messages.AddEventArgsForCall(0)
+				require.Equal(t, int64(42), id)
+				require.Equal(t, tt.form.Get("MessageStatus"), status)
+				require.Equal(t, tt.form.Get("ErrorCode"), code)
+			}
+		})
+	}
+}
+
+func TestAddUserIngredient(t *testing.T) {
+	userUUID := uuid.New()
+
+	tests := []struct {
+		name      string
+		body      string
+		storeErr  error
+		wantCode  int
+		wantScope models.SubscriptionScope
+	}{
+		{"company wide", `{"ingredientId": 3}`, nil, http.StatusOK, models.SubscriptionScope{}},
+		{"one store", `{"ingredientId": 3, "storeId": 1}`, nil, http.StatusOK, models.SubscriptionScope{StoreIDs: []int64{1}}},
+		{"several stores", `{"ingredientId": 3, "storeId": 1, "storeIds": [2]}`, nil, http.StatusOK, models.SubscriptionScope{StoreIDs: []int64{1, 2}}},
+		{"area", `{"ingredientId": 3, "area": {"lat": 33.7339, "lng": -84.3496, "radiusMiles": 5}}`, nil, http.StatusOK, models.SubscriptionScope{Area: &models.Area{Lat: 33.7339, Lng: -84.3496, RadiusMiles: 5}}},
+		{"unknown store", `{"ingredientId": 3, "storeId": 9}`, models.ErrNoRecord, http.StatusBadRequest, models.SubscriptionScope{}},
+		{"no radius", `{"ingredientId": 3, "area": {"lat": 33.7339, "lng": -84.3496}}`, nil, http.StatusBadRequest, models.SubscriptionScope{}},
+		{"keyword", `{"keyword": " pecans "}`, nil, http.StatusOK, models.SubscriptionScope{}},
+		{"no ingredient or keyword", `{"keyword": " "}`, nil, http.StatusBadRequest, models.SubscriptionScope{}},
+		{"keyword without words", `{"keyword": "!!"}`, nil, http.StatusBadRequest, models.SubscriptionScope{}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			app, fakes := newFakeApplication()
+			fakes.users.GetByUUIDReturns(&models.User{ID: 7, UUID: userUUID}, nil)
+			fakes.users.AddIngredientStub = func(userID int64, i *models.Ingredient, keyword string, scope models.SubscriptionScope) (*models.UserIngredient, error) {
+				return &models.UserIngredient{UserIngredientID: 10, Ingredient: i, Keyword: keyword, Scope: scope}, nil
+			}
+			fakes.ingredients.GetReturns(&models.Ingredient{ID: 3, Name: "coconut"}, nil)
+			fakes.stores.GetReturns(&models.Store{ID: 1}, tt.storeErr)
+
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/user/%s/ingredient?:uuid=%s", userUUID, userUUID), strings.NewReader(tt.body))
+			res := NewFakeResponse(t)
+			app.addUserIngredient(res, req)
+
+			require.Equal(t, tt.wantCode, res.status)
+			if tt.wantCode != http.StatusOK {
+				require.Equal(t, 0, fakes.users.AddIngredientCallCount())
+				return
+			}
+
+			require.Equal(t, 1, fakes.users.AddIngredientCallCount())
+			userID, ingredient, keyword, scope := fakes.users.AddIngredientArgsForCall(0)
+			require.Equal(t, int64(7), userID)
+			require.Equal(t, tt.wantScope, scope)
+			if keyword != "" {
+				require.Nil(t, ingredient)
+				require.Equal(t, "pecans", keyword)
+				return
+			}
+			require.Equal(t, int64(3), ingredient.ID)
+		})
+	}
+}
+
+func TestRemoveUserFlavor(t *testing.T) {
+	userUUID := uuid.New()
+
+	tests := []struct {
+		name       string
+		id         string
+		wantCode   int
+		wantRemove int
+	}{
+		{"subscribed", "20", http.StatusNoContent, 1},
+		{"another user's subscription", "21", http.StatusNotFound, 0},
+		{"invalid id", "foo", http.StatusNotFound, 0},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			app, fakes := newFakeApplication()
+			fakes.users.GetByUUIDReturns(&models.User{ID: 7, UUID: userUUID}, nil)
+			fakes.users.GetFlavorsReturns([]*models.UserFlavor{{UserFlavorID: 20, Flavor: &models.Flavor{ID: 1}}}, nil)
+
+			req := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/api/v1/user/%s/flavor/%s?:uuid=%s&:id=%s", userUUID, tt.id, userUUID, tt.id), nil)
+			res := NewFakeResponse(t)
+			app.removeUserFlavor(res, req)
+
+			require.Equal(t, tt.wantCode, res.status)
+			require.Equal(t, tt.wantRemove, fakes.users.RemoveUserFlavorCallCount())
+		})
+	}
+}
+
+func TestPartialUpdateFlavor(t *testing.T) {
+	existing := func() *models.Flavor {
+		return &models.Flavor{
+			ID:          12,
+			Name:        "Coconut Jalapeno",
+			Description: "Coconut with jalapenos",
+			Ingredients: []models.Ingredient{{ID: 1, Name: "coconut"}, {ID: 2, Name: "jalapeno"}},
+		}
+	}
+
+	tests := []struct {
+		name            string
+		body            string
+		wantCode        int
+		wantName        string
+		wantIngredients int
+	}{
+		{"description only", `{"description": "Hot and cold"}`, http.StatusOK, "Coconut Jalapeno", 2},
+		{"ingredients replaced", `{"ingredients": [{"name": "coconut"}]}`, http.StatusOK, "Coconut Jalapeno", 1},
+		{"empty name", `{"name": " "}`, http.StatusBadRequest, "", 0},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			app, fakes := newFakeApplication()
+			fakes.flavors.GetReturns(existing(), nil)
+			fakes.flavors.UpdateStub = func(id int, f *models.Flavor) (*models.Flavor, error) {
+				return f, nil
+			}
+
+			req := httptest.NewRequest(http.MethodPatch, "/api/v1/flavor/12?:id=12", strings.NewReader(tt.body))
+			res := NewFakeResponse(t)
+			app.partialUpdateFlavor(res, req)
+
+			require.Equal(t, tt.wantCode, res.status)
+			if tt.wantCode != http.StatusOK {
+				require.Equal(t, 0, fakes.flavors.UpdateCallCount())
+				return
+			}
+
+			id, f := fakes.flavors.UpdateArgsForCall(0)
+			require.Equal(t, 12, id)
+			require.Equal(t, tt.wantName, f.Name)
+			require.Len(t, f.Ingredients, tt.wantIngredients)
+		})
+	}
+}
+
+func TestDeleteFlavor(t *testing.T) {
+	tests := []struct {
+		name      string
+		deleted   bool
+		deleteErr error
+		wantCode  int
+	}{
+		{"deleted", true, nil, http.StatusNoContent},
+		{"not found", false, nil, http.StatusNotFound},
+		{"active at a store", false, models.ErrActiveFlavor, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			app, fakes := newFakeApplication()
+			fakes.flavors.DeleteReturns(tt.deleted, tt.deleteErr)
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/v1/flavor/12?:id=12", nil)
+			res := NewFakeResponse(t)
+			app.deleteFlavor(res, req)
+
+			require.Equal(t, tt.wantCode, res.status)
+		})
+	}
+}
+
+func TestRefreshAuth(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		consumeErr error
+		wantCode   int
+	}{
+		{"valid refresh token", `{"refreshToken":"abc"}`, nil, http.StatusOK},
+		{"unknown refresh token", `{"refreshToken":"abc"}`, mysql.ErrNoRefreshTokenFound, http.StatusUnauthorized},
+		{"no refresh token", `{}`, nil, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			app, fakes := newFakeApplication()
+			if tt.consumeErr != nil {
+				fakes.users.ConsumeRefreshTokenReturns(nil, tt.consumeErr)
+			} else {
+				fakes.users.ConsumeRefreshTokenReturns(&models.User{ID: 7, UUID: uuid.New()}, nil)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/refresh", strings.NewReader(tt.body))
+			res := NewFakeResponse(t)
+			app.refreshAuth(res, req)
+
+			require.Equal(t, tt.wantCode, res.status)
+			if tt.wantCode != http.StatusOK {
+				require.Equal(t, 0, fakes.users.SaveRefreshTokenCallCount())
+				return
+			}
+
+			var body struct {
+				Token        string `json:"token"`
+				RefreshToken string `json:"refreshToken"`
+			}
+			require.NoError(t, json.Unmarshal(res.body, &body))
+			require.NotEmpty(t, body.Token)
+
+			require.Equal(t, 1, fakes.users.SaveRefreshTokenCallCount())
+			token, userID, ttl := fakes.users.SaveRefreshTokenArgsForCall(0)
+			require.Equal(t, body.RefreshToken, token)
+			require.NotEqual(t, "abc", token)
+			require.Equal(t, int64(7), userID)
+			require.Equal(t, REFRESH_TOKEN_TTL, ttl)
+		})
+	}
+}
+
+func TestLogout(t *testing.T) {
+	app, fakes := newFakeApplication()
+
+	claims := &Claims{UUID: uuid.New().String()}
+	claims.Id = "jti"
+	claims.ExpiresAt = time.Now().Add(time.Minute * 30).Unix()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/logout", strings.NewReader(`{"refreshToken":"abc"}`))
+	req = req.WithContext(context.WithValue(req.Context(), ContextKeyUser, claims))
+	res := NewFakeResponse(t)
+	app.logout(res, req)
+
+	require.Equal(t, http.StatusNoContent, res.status)
+
+	require.Equal(t, 1, fakes.users.RevokeTokenCallCount())
+	jti, ttl := fakes.users.RevokeTokenArgsForCall(0)
+	require.Equal(t, "jti", jti)
+	require.InDelta(t, (time.Minute * 30).Seconds(), ttl.Seconds(), 2)
+
+	require.Equal(t, 1, fakes.users.ConsumeRefreshTokenCallCount())
+	require.Equal(t, "abc", fakes.users.ConsumeRefreshTokenArgsForCall(0))
+}
+
+func TestAuthByCode(t *testing.T) {
+	user := &models.User{ID: 7, UUID: uuid.New(), Phone: "4045551212"}
+
+	tests := []struct {
+		name        string
+		body        string
+		phoneErr    error
+		codeOK      bool
+		wantCode    int
+		wantSuccess bool
+	}{
+		{"valid code", `{"phone":"404-555-1212","code":"123456"}`, nil, true, http.StatusOK, true},
+		{"wrong code", `{"phone":"404-555-1212","code":"654321"}`, nil, false, http.StatusUnauthorized, false},
+		{"unknown phone", `{"phone":"404-555-0000","code":"123456"}`, models.ErrNoRecord, false, http.StatusUnauthorized, false},
+		{"missing code", `{"phone":"404-555-1212"}`, nil, false, http.StatusBadRequest, false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			app, fakes := newFakeApplication()
+			if tt.phoneErr != nil {
+				fakes.users.GetByPhoneReturns(nil, tt.phoneErr)
+			} else {
+				fakes.users.GetByPhoneReturns(user, nil)
+			}
+			fakes.users.ConsumeAuthCodeReturns(tt.codeOK, nil)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/code", strings.NewReader(tt.body))
+			res := NewFakeResponse(t)
+			app.authByCode(res, req)
+
+			require.Equal(t, tt.wantCode, res.status)
+
+			if tt.wantCode == http.StatusBadRequest {
+				require.Equal(t, 0, fakes.loginAttempts.InsertCallCount())
+				return
+			}
+
+			require.Equal(t, 1, fakes.loginAttempts.InsertCallCount())
+			attempt := fakes.loginAttempts.InsertArgsForCall(0)
+			require.Equal(t, models.LOGIN_METHOD_CODE, attempt.Method)
+			require.Equal(t, tt.wantSuccess, attempt.Success)
+			require.Equal(t, req.RemoteAddr, attempt.RemoteAddr)
+
+			if tt.phoneErr == nil {
+				userID, code, maxAttempts := fakes.users.ConsumeAuthCodeArgsForCall(0)
+				require.Equal(t, user.ID, userID)
+				require.Equal(t, AUTH_CODE_MAX_ATTEMPTS, maxAttempts)
+				require.NotEmpty(t, code)
+			}
+
+			require.Equal(t, tt.wantSuccess, fakes.users.SaveRefreshTokenCallCount() == 1)
+		})
+	}
+}
+
+func TestRequestPasswordReset(t *testing.T) {
+	user := &models.User{ID: 7, UUID: uuid.New(), Email: models.NullString{String: "alice@example.com"}}
+
+	tests := []struct {
+		name     string
+		body     string
+		emailErr error
+		wantCode int
+		wantSent bool
+	}{
+		{"known email", `{"email":"alice@example.com"}`, nil, http.StatusNoContent, true},
+		{"unknown email", `{"email":"mallory@example.com"}`, models.ErrNoRecord, http.StatusNoContent, false},
+		{"no email", `{}`, nil, http.StatusBadRequest, false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			app, fakes := newFakeApplication()
+			app.baseUrl = "https://morellis.test"
+			if tt.emailErr != nil {
+				fakes.users.GetByEmailReturns(nil, tt.emailErr)
+			} else {
+				fakes.users.GetByEmailReturns(user, nil)
+			}
+
+			sent := make(chan struct{}, 1)
+			fakes.mailer.SendStub = func(ctx context.Context, to, subject, body string) error {
+				sent <- struct{}{}
+				return nil
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/password-reset", strings.NewReader(tt.body))
+			res := NewFakeResponse(t)
+			app.requestPasswordReset(res, req)
+
+			require.Equal(t, tt.wantCode, res.status)
+
+			if !tt.wantSent {
+				require.Equal(t, 0, fakes.users.SavePasswordResetTokenCallCount())
+				return
+			}
+
+			select {
+			case <-sent:
+			case <-time.After(time.Second):
+				t.Fatal("password reset email not sent")
+			}
+
+			token, userID, ttl := fakes.users.SavePasswordResetTokenArgsForCall(0)
+			require.Equal(t, user.ID, userID)
+			require.Equal(t, PASSWORD_RESET_TOKEN_TTL, ttl)
+
+			_, to, _, body := fakes.mailer.SendArgsForCall(0)
+			require.Equal(t, "alice@example.com", to)
+			require.Contains(t, body, "https://morellis.test/password-reset/"+token)
+		})
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		status     string
+		consumeErr error
+		wantCode   int
+		wantUpdate int
+		wantVerify int
+	}{
+		{"valid token", `{"password":"correct horse"}`, "verified", nil, http.StatusNoContent, 1, 0},
+		{"valid token for unverified user", `{"password":"correct horse"}`, "unverified", nil, http.StatusNoContent, 1, 1},
+		{"unknown token", `{"password":"correct horse"}`, "verified", mysql.ErrNoPasswordResetTokenFound, http.StatusNotFound, 0, 0},
+		{"short password", `{"password":"horse"}`, "verified", nil, http.StatusBadRequest, 0, 0},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			app, fakes := newFakeApplication()
+			if tt.consumeErr != nil {
+				fakes.users.ConsumePasswordResetTokenReturns(nil, tt.consumeErr)
+			} else {
+				fakes.users.ConsumePasswordResetTokenReturns(&models.User{ID: 7, Status: tt.status}, nil)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/password-reset/abc?:token=abc", strings.NewReader(tt.body))
+			res := NewFakeResponse(t)
+			app.resetPassword(res, req)
+
+			require.Equal(t, tt.wantCode, res.status)
+			require.Equal(t, tt.wantUpdate, fakes.users.UpdatePasswordCallCount())
+			if tt.wantUpdate > 0 {
+				require.Equal(t, "abc", fakes.users.ConsumePasswordResetTokenArgsForCall(0))
+				userID, password := fakes.users.UpdatePasswordArgsForCall(0)
+				require.Equal(t, int64(7), userID)
+				require.Equal(t, "correct horse", password)
+			}
+
+			require.Equal(t, tt.wantVerify, fakes.users.UpdateCallCount())
+			if tt.wantVerify > 0 {
+				require.Equal(t, models.USER_STATUS_VERIFIED.Slug(), fakes.users.UpdateArgsForCall(0).Status)
+			}
+		})
+	}
+}
+
+func TestEmailedLinks(t *testing.T) {
+	user := &models.User{ID: 7, Status: models.USER_STATUS_UNVERIFIED.Slug(), Email: models.NullString{String: "alice@example.com"}}
+
+	app, fakes := newFakeApplication()
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+	app.baseUrl = ts.URL
+
+	// link gets the URL in the last email sent
+	link := func() string {
+		_, _, _, body := fakes.mailer.SendArgsForCall(fakes.mailer.SendCallCount() - 1)
+		return regexp.MustCompile(`https?://\S+`).FindString(body)
+	}
+
+	t.Run("verify email", func(t *testing.T) {
+		require.NoError(t, app.sendVerificationEmail(context.TODO(), user))
+		fakes.users.ConsumeVerificationTokenReturns(user, nil)
+
+		res, err := http.Get(link())
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		require.Equal(t, http.StatusNoContent, res.StatusCode)
+		token, _, _ := fakes.users.SaveVerificationTokenArgsForCall(0)
+		require.Equal(t, token, fakes.users.ConsumeVerificationTokenArgsForCall(0))
+	})
+
+	t.Run("reset password", func(t *testing.T) {
+		require.NoError(t, app.sendPasswordResetEmail(context.TODO(), user))
+		fakes.users.ConsumePasswordResetTokenReturns(user, nil)
+
+		res, err := http.Get(link())
+		require.NoError(t, err)
+		body, err := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		require.NoError(t, err)
+
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Contains(t, string(body), `<form method="post">`)
+		// The token is only used once the form is posted
+		require.Equal(t, 0, fakes.users.ConsumePasswordResetTokenCallCount())
+
+		res, err = http.PostForm(link(), url.Values{"password": {"correct horse"}})
+		require.NoError(t, err)
+		body, err = ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		require.NoError(t, err)
+
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Contains(t, string(body), "Your password has been reset.")
+		token, _, _ := fakes.users.SavePasswordResetTokenArgsForCall(0)
+		require.Equal(t, token, fakes.users.ConsumePasswordResetTokenArgsForCall(0))
+		_, password := fakes.users.UpdatePasswordArgsForCall(0)
+		require.Equal(t, "correct horse", password)
+	})
+}
+
+func TestVerifyEmail(t *testing.T) {
+	tests := []struct {
+		name       string
+		consumeErr error
+		wantCode   int
+	}{
+		{"valid token", nil, http.StatusNoContent},
+		{"unknown token", mysql.ErrNoVerificationTokenFound, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			app, fakes := newFakeApplication()
+			if tt.consumeErr != nil {
+				fakes.users.ConsumeVerificationTokenReturns(nil, tt.consumeErr)
+			} else {
+				fakes.users.ConsumeVerificationTokenReturns(&models.User{ID: 7, Status: models.USER_STATUS_UNVERIFIED.Slug()}, nil)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/verify-email/abc?:token=abc", nil)
+			res := NewFakeResponse(t)
+			app.verifyEmail(res, req)
+
+			require.Equal(t, tt.wantCode, res.status)
+
+			if tt.consumeErr != nil {
+				require.Equal(t, 0, fakes.users.UpdateCallCount())
+				return
+			}
+
+			require.Equal(t, 1, fakes.users.UpdateCallCount())
+			u := fakes.users.UpdateArgsForCall(0)
+			require.Equal(t, int64(7), u.ID)
+			require.Equal(t, models.USER_STATUS_VERIFIED.Slug(), u.Status)
+		})
+	}
+}
+
+func TestRecordBarrelLevel(t *testing.T) {
+	activated := time.Now().Add(-time.Hour * 4)
+	store := &models.Store{ID: 3, Name: "Morelli's Ice Cream"}
+	staffUser := &models.User{ID: 9, Phone: "4045551212"}
+
+	tests := []struct {
+		name      string
+		body      string
+		levels    []models.BarrelLevel
+		barrelErr error
+		wantCode  int
+		wantAlert bool
+	}{
+		{"above the low level", `{"level":50}`, nil, nil, http.StatusOK, false},
+		{"falls below the low level", `{"level":15}`, []models.BarrelLevel{{Level: 30, Created: activated.Add(time.Hour)}}, nil, http.StatusOK, true},
+		{"already low", `{"level":10}`, []models.BarrelLevel{{Level: 15, Created: activated.Add(time.Hour)}}, nil, http.StatusOK, false},
+		{"no barrel in the position", `{"level":50}`, nil, models.ErrNoRecord, http.StatusNotFound, false},
+		{"invalid level", `{"level":101}`, nil, nil, http.StatusBadRequest, false},
+		{"no level", `{}`, nil, nil, http.StatusBadRequest, false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			app, fakes := newFakeApplication()
+			fakes.stores.GetReturns(store, nil)
+
+			if tt.barrelErr != nil {
+				fakes.inventory.GetBarrelReturns(nil, tt.barrelErr)
+			} else {
+				fakes.inventory.GetBarrelReturns(&models.Barrel{ID: 40, StoreID: 3, Position: 2, Flavor: &models.Flavor{ID: 5, Name: "Vanilla"}, Activated: activated, Levels: tt.levels}, nil)
+			}
+			fakes.inventory.GetLowLevelReturns(models.DEFAULT_LOW_BARREL_LEVEL, nil)
+			fakes.inventory.RecordLevelStub = func(barrelID int64, level int, userID int64) (*models.BarrelLevel, error) {
+				return &models.BarrelLevel{ID: 1, BarrelID: barrelID, Level: level, UserID: userID, Created: time.Now()}, nil
+			}
+
+			fakes.users.GetByUUIDReturns(&models.User{ID: 7}, nil)
+			fakes.users.GetStoreStaffReturns([]*models.User{staffUser}, nil)
+
+			sent := make(chan string, 1)
+			fakes.sender.SendStub = func(ctx context.Context, number, message string) (string, error) {
+				sent <- message
+				return "", nil
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/store/3/position/2/level?:storeID=3&:position=2", strings.NewReader(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), ContextKeyUser, &Claims{UUID: uuid.New().String()}))
+			res := NewFakeResponse(t)
+			app.recordBarrelLevel(res, req)
+
+			require.Equal(t, tt.wantCode, res.status)
+			if tt.wantCode != http.StatusOK {
+				require.Equal(t, 0, fakes.inventory.RecordLevelCallCount())
+				return
+			}
+
+			require.Equal(t, 1, fakes.inventory.RecordLevelCallCount())
+			barrelID, _, userID := fakes.inventory.RecordLevelArgsForCall(0)
+			require.Equal(t, int64(40), barrelID)
+			require.Equal(t, int64(7), userID)
+
+			var body BarrelBody
+			require.NoError(t, json.Unmarshal(res.body, &body))
+			require.Equal(t, 2, body.Position)
+			require.NotNil(t, body.EmptyAt)
+
+			if !tt.wantAlert {
+				require.Equal(t, 0, fakes.users.GetStoreStaffCallCount())
+				return
+			}
+
+			select {
+			case message := <-sent:
+				require.Contains(t, message, "Vanilla in position 2 is at 15%")
+			case <-time.After(time.Second):
+				t.Fatal("low barrel alert not sent")
+			}
+
+			require.Equal(t, store.ID, fakes.users.GetStoreStaffArgsForCall(0))
+			_, number, _ := fakes.sender.SendArgsForCall(0)
+			require.Equal(t, staffUser.Phone, number)
+		})
+	}
+}
+
+func TestFlavorReport(t *testing.T) {
+	activated := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	deactivated := activated.Add(time.Hour * 24)
+	vanilla := &models.Flavor{ID: 5, Name: "Vanilla"}
+
+	tests := []struct {
+		name         string
+		query        string
+		wantCode     int
+		wantFrom     time.Time
+		wantTo       time.Time
+		wantStoreID  int64
+		wantDefaults bool
+	}{
+		{"default range", "", http.StatusOK, time.Time{}, time.Time{}, 0, true},
+		{"date range", "?from=2021-03-01&to=2021-03-31", http.StatusOK, activated.Add(-time.Hour * 12), time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC), 0, false},
+		{"RFC 3339 range at a store", "?from=2021-03-01T12:00:00Z&to=2021-03-02T12:00:00Z&storeId=3", http.StatusOK, activated, deactivated, 3, false},
+		{"invalid from", "?from=March", http.StatusBadRequest, time.Time{}, time.Time{}, 0, false},
+		{"from after to", "?from=2021-03-31&to=2021-03-01", http.StatusBadRequest, time.Time{}, time.Time{}, 0, false},
+		{"invalid store", "?storeId=three", http.StatusBadRequest, time.Time{}, time.Time{}, 0, false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			app, fakes := newFakeApplication()
+			fakes.stores.ListRotationsReturns([]*models.Rotation{
+				{ID: 1, StoreID: 3, Position: 1, Flavor: vanilla, Activated: activated, Deactivated: &deactivated},
+				{ID: 2, StoreID: 3, Position: 2, Flavor: vanilla, Activated: activated},
+			}, nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/report/flavor"+tt.query, nil)
+			res := NewFakeResponse(t)
+			app.flavorReport(res, req)
+
+			require.Equal(t, tt.wantCode, res.status)
+			if tt.wantCode != http.StatusOK {
+				require.Equal(t, 0, fakes.stores.ListRotationsCallCount())
+				return
+			}
+
+			require.Equal(t, 1, fakes.stores.ListRotationsCallCount())
+			from, to, storeID, flavorID := fakes.stores.ListRotationsArgsForCall(0)
+			if tt.wantDefaults {
+				require.WithinDuration(t, time.Now(), to, time.Minute)
+				require.Equal(t, REPORT_RANGE, to.Sub(from))
+			} else {
+				require.True(t, tt.wantFrom.Equal(from), "from %s", from)
+				require.True(t, tt.wantTo.Equal(to), "to %s", to)
+			}
+			require.Equal(t, tt.wantStoreID, storeID)
+			require.Equal(t, int64(0), flavorID)
+
+			var body struct {
+				Items []*report.FlavorStats `json:"items"`
+			}
+			require.NoError(t, json.Unmarshal(res.body, &body))
+			require.Len(t, body.Items, 1)
+			require.Equal(t, 2, body.Items[0].Rotations)
+			require.Equal(t, 1, body.Items[0].Active)
+			require.Equal(t, float64(24), body.Items[0].AverageHours)
+		})
+	}
+}
+
+func TestStoreTimeline(t *testing.T) {
+	activated := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	deactivated := activated.Add(time.Hour * 24)
+
+	tests := []struct {
+		name     string
+		storeErr error
+		wantCode int
+	}{
+		{"store found", nil, http.StatusOK},
+		{"store not found", models.ErrNoRecord, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			app, fakes := newFakeApplication()
+			fakes.stores.GetReturns(&models.Store{ID: 3, Name: "Morelli's Ice Cream"}, tt.storeErr)
+			fakes.stores.ListRotationsReturns([]*models.Rotation{
+				{ID: 2, StoreID: 3, Position: 1, Flavor: &models.Flavor{ID: 6, Name: "Pecan"}, Activated: deactivated},
+				{ID: 1, StoreID: 3, Position: 1, Flavor: &models.Flavor{ID: 5, Name: "Vanilla"}, Activated: activated, Deactivated: &deactivated},
+				{ID: 3, StoreID: 3, Position: 2, Flavor: &models.Flavor{ID: 5, Name: "Vanilla"}, Activated: activated},
+			}, nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/store/3/timeline?:storeID=3&from=2021-03-01&to=2021-03-31", nil)
+			res := NewFakeResponse(t)
+			app.storeTimeline(res, req)
+
+			require.Equal(t, tt.wantCode, res.status)
+			if tt.wantCode != http.StatusOK {
+				require.Equal(t, 0, fakes.stores.ListRotationsCallCount())
+				return
+			}
+
+			_, _, storeID, _ := fakes.stores.ListRotationsArgsForCall(0)
+			require.Equal(t, int64(3), storeID)
+
+			var body struct {
+				Items []*report.PositionTimeline `json:"items"`
+			}
+			require.NoError(t, json.Unmarshal(res.body, &body))
+			require.Len(t, body.Items, 2)
+			require.Equal(t, 1, body.Items[0].Position)
+			require.Len(t, body.Items[0].Rotations, 2)
+			require.Equal(t, "Vanilla", body.Items[0].Rotations[0].Flavor.Name)
+			require.Equal(t, "Pecan", body.Items[0].Rotations[1].Flavor.Name)
+			require.Nil(t, body.Items[0].Rotations[1].Deactivated)
+		})
+	}
+}
+
+func TestListCooler(t *testing.T) {
+	vanilla := &models.Flavor{ID: 5, Name: "Vanilla", Ingredients: []models.Ingredient{{ID: 1, Name: "vanilla bean"}}}
+	pecan := &models.Flavor{ID: 6, Name: "Pecan", Ingredients: []models.Ingredient{}}
+	store := &models.Store{ID: 3, Name: "Morelli's Ice Cream"}
+
+	app, fakes := newFakeApplication()
+	fakes.stores.ListLineupsReturns([]*models.Lineup{{Store: store, Flavors: []*models.LineupFlavor{
+		{Position: 1, Flavor: vanilla},
+		{Position: 2, Flavor: pecan},
+	}}}, nil)
+
+	get := func(ifNoneMatch string) *FakeResponse {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/cooler", nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		res := NewFakeResponse(t)
+		app.listCooler(res, req)
+		return res
+	}
+
+	res := get("")
+	require.Equal(t, http.StatusOK, res.status)
+	require.Equal(t, int64(0), fakes.stores.ListLineupsArgsForCall(0))
+	require.Equal(t, "public, max-age=30", res.Header().Get("Cache-Control"))
+
+	etag := res.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	var body struct {
+		Items []*models.Lineup `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(res.body, &body))
+	require.Len(t, body.Items, 1)
+	require.Len(t, body.Items[0].Flavors, 2)
+	require.Equal(t, "Vanilla", body.Items[0].Flavors[0].Flavor.Name)
+	require.Equal(t, "vanilla bean", body.Items[0].Flavors[0].Flavor.Ingredients[0].Name)
+
+	t.Run("unchanged lineup", func(t *testing.T) {
+		res := get(etag)
+		require.Equal(t, http.StatusNotModified, res.status)
+		require.Empty(t, res.body)
+		require.Equal(t, etag, res.Header().Get("ETag"))
+	})
+
+	t.Run("changed lineup", func(t *testing.T) {
+		fakes.stores.ListLineupsReturns([]*models.Lineup{{Store: store, Flavors: []*models.LineupFlavor{
+			{Position: 1, Flavor: pecan},
+		}}}, nil)
+
+		res := get(etag)
+		require.Equal(t, http.StatusOK, res.status)
+		require.NotEqual(t, etag, res.Header().Get("ETag"))
+	})
+}
+
+func TestGetCooler(t *testing.T) {
+	tests := []struct {
+		name     string
+		lineups  []*models.Lineup
+		wantCode int
+	}{
+		{"store found", []*models.Lineup{{Store: &models.Store{ID: 3}, Flavors: []*models.LineupFlavor{}}}, http.StatusOK},
+		{"store not found", []*models.Lineup{}, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			app, fakes := newFakeApplication()
+			fakes.stores.ListLineupsReturns(tt.lineups, nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/cooler/3?:storeID=3", nil)
+			res := NewFakeResponse(t)
+			app.getCooler(res, req)
+
+			require.Equal(t, tt.wantCode, res.status)
+			require.Equal(t, int64(3), fakes.stores.ListLineupsArgsForCall(0))
+			if tt.wantCode == http.StatusOK {
+				require.NotEmpty(t, res.Header().Get("ETag"))
+			}
+		})
+	}
+}
+
+func TestReplaceStoreFlavors(t *testing.T) {
+	store := &models.Store{ID: 3, Name: "Morelli's Ice Cream"}
+	vanilla := &models.Flavor{ID: 5, Name: "Vanilla"}
+	pecan := &models.Flavor{ID: 6, Name: "Pecan"}
+	mint := &models.Flavor{ID: 7, Name: "Mint"}
+	flavorsByID := map[int]*models.Flavor{5: vanilla, 6: pecan, 7: mint}
+
+	tests := []struct {
+		name       string
+		body       string
+		diff       *models.LineupDiff
+		err        error
+		wantCode   int
+		wantNotify []int64
+	}{
+		{
+			name: "new and moved flavors",
+			body: `{"1":5,"2":7,"3":7,"4":5}`,
+			diff: &models.LineupDiff{
+				Activated: []*models.LineupFlavor{
+					{Position: 2, Flavor: &models.Flavor{ID: 7}},
+					{Position: 3, Flavor: &models.Flavor{ID: 7}},
+					{Position: 4, Flavor: &models.Flavor{ID: 5}},
+				},
+				Deactivated: []*models.LineupFlavor{{Position: 2, Flavor: pecan}},
+				Unchanged:   []*models.LineupFlavor{{Position: 1, Flavor: vanilla}},
+			},
+			wantCode:   http.StatusOK,
+			wantNotify: []int64{7},
+		},
+		{"unknown flavor", `{"1":9}`, nil, nil, http.StatusBadRequest, nil},
+		{"invalid position", `{"0":5}`, nil, &models.LayoutConflictError{StoreID: 3, Position: 0}, http.StatusConflict, nil},
+		{"position outside the layout", `{"5":5}`, nil, &models.LayoutConflictError{StoreID: 3, Position: 5}, http.StatusConflict, nil},
+		{"invalid body", `[5,7]`, nil, nil, http.StatusBadRequest, nil},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			app, fakes := newFakeApplication()
+			fakes.stores.GetReturns(store, nil)
+			fakes.stores.ReplaceLineupReturns(tt.diff, tt.err)
+
+			fakes.flavors.GetStub = func(id int) (*models.Flavor, error) {
+				if f, ok := flavorsByID[id]; ok {
+					return f, nil
+				}
+				return nil, models.ErrNoRecord
+			}
+
+			notified := make(chan int64, 3)
+			fakes.users.GetFlavorSubscribersStub = func(flavorID int64) ([]*models.User, error) {
+				notified <- flavorID
+				return nil, nil
+			}
+
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/store/3/flavor?:storeID=3", strings.NewReader(tt.body))
+			res := NewFakeResponse(t)
+			app.replaceStoreFlavors(res, req)
+
+			require.Equal(t, tt.wantCode, res.status)
+			if tt.wantCode == http.StatusBadRequest {
+				require.Equal(t, 0, fakes.stores.ReplaceLineupCallCount())
+			}
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+
+			storeID, lineup := fakes.stores.ReplaceLineupArgsForCall(0)
+			require.Equal(t, int64(3), storeID)
+			require.Equal(t, map[int]int64{1: 5, 2: 7, 3: 7, 4: 5}, lineup)
+
+			var body models.LineupDiff
+			require.NoError(t, json.Unmarshal(res.body, &body))
+			require.Len(t, body.Activated, 3)
+			require.Equal(t, "Mint", body.Activated[0].Flavor.Name)
+
+			// Flavors already active at the Store, and repeated Flavors, aren't notified again
+			for _, want := range tt.wantNotify {
+				select {
+				case flavorID := <-notified:
+					require.Equal(t, want, flavorID)
+				case <-time.After(time.Second):
+					t.Fatalf("flavor %d not notified", want)
+				}
+			}
+			select {
+			case flavorID := <-notified:
+				t.Fatalf("flavor %d notified", flavorID)
+			case <-time.After(time.Millisecond * 50):
+			}
+		})
+	}
+}
+
+func TestUpdateStoreLayout(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		err      error
+		wantCode int
+	}{
+		{"labeled and disabled slots", `{"positions":8,"slots":[{"position":1,"label":"A1"},{"position":8,"disabled":true}]}`, nil, http.StatusOK},
+		{"too many positions", `{"positions":49}`, nil, http.StatusBadRequest},
+		{"slot outside the positions", `{"positions":8,"slots":[{"position":9,"label":"C1"}]}`, nil, http.StatusBadRequest},
+		{"duplicate slot", `{"positions":8,"slots":[{"position":1,"label":"A1"},{"position":1,"disabled":true}]}`, nil, http.StatusBadRequest},
+		{"duplicate label", `{"positions":8,"slots":[{"position":1,"label":"A1"},{"position":2,"label":"A1"}]}`, nil, http.StatusBadRequest},
+		{"long label", `{"positions":8,"slots":[{"position":1,"label":"The one by the door"}]}`, nil, http.StatusBadRequest},
+		{"conflicts with the lineup", `{"positions":8,"slots":[{"position":2,"disabled":true}]}`, &models.LayoutConflictError{StoreID: 3, Position: 2}, http.StatusConflict},
+		{"database error", `{"positions":8}`, errors.New("connection lost"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			app, fakes := newFakeApplication()
+			fakes.stores.GetReturns(&models.Store{ID: 3}, nil)
+			fakes.stores.SaveLayoutReturns(tt.err)
+
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/store/3/layout?:storeID=3", strings.NewReader(tt.body))
+			res := NewFakeResponse(t)
+			app.updateStoreLayout(res, req)
+
+			require.Equal(t, tt.wantCode, res.status)
+			if tt.wantCode == http.StatusBadRequest {
+				require.Equal(t, 0, fakes.stores.SaveLayoutCallCount())
+			}
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+
+			require.Equal(t, 1, fakes.stores.SaveLayoutCallCount())
+			saved := fakes.stores.SaveLayoutArgsForCall(0)
+			require.Equal(t, int64(3), saved.StoreID)
+			require.Len(t, saved.Slots, 8)
+
+			var body models.Layout
+			require.NoError(t, json.Unmarshal(res.body, &body))
+			require.Equal(t, 8, body.Positions)
+			require.Equal(t, "A1", body.Slots[0].Label)
+			require.True(t, body.Slots[7].Disabled)
+			require.False(t, body.Slots[6].Disabled)
+		})
+	}
+}
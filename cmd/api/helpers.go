@@ -1,18 +1,27 @@
 package main
 
 import (
+	"context"
 	"crypto/rand"
-	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"math/big"
 	"net/http"
 	"reflect"
 	"runtime/debug"
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
+
+	"github.com/jcorry/morellis/pkg/inventory"
 	"github.com/jcorry/morellis/pkg/models"
+	"github.com/jcorry/morellis/pkg/sms"
 
 	"github.com/dgrijalva/jwt-go"
 )
@@ -20,35 +29,57 @@ import (
 type Claims struct {
 	UUID        string                  `json:"uuid"`
 	Permissions []models.UserPermission `json:"userPermissions"`
+	Roles       []models.UserRole       `json:"userRoles,omitempty"`
 	jwt.StandardClaims
 }
 
-var (
-	verifyKey *rsa.PublicKey
-	signKey   *rsa.PrivateKey
+const (
+	// ACCESS_TOKEN_TTL is how long a JWT is valid for
+	ACCESS_TOKEN_TTL = time.Hour
+	// REFRESH_TOKEN_TTL is how long a refresh token can be exchanged for a new JWT
+	REFRESH_TOKEN_TTL = time.Hour * 24 * 30
+	// AUTH_REQUEST_LIMIT is how many logins a phone number may request every AUTH_REQUEST_WINDOW
+	AUTH_REQUEST_LIMIT  = 3
+	AUTH_REQUEST_WINDOW = time.Minute * 15
+	// AUTH_CODE_DIGITS is the length of the one time codes texted to Users
+	AUTH_CODE_DIGITS = 6
+	// AUTH_CODE_TTL is how long a one time code can be used to log in
+	AUTH_CODE_TTL = time.Minute * 5
+	// AUTH_CODE_MAX_ATTEMPTS is how many wrong codes may be entered before the code is deleted
+	AUTH_CODE_MAX_ATTEMPTS = 5
+	// PASSWORD_RESET_TOKEN_TTL is how long a password reset link can be used
+	PASSWORD_RESET_TOKEN_TTL = time.Hour
+	// VERIFICATION_TOKEN_TTL is how long an email verification link can be used
+	VERIFICATION_TOKEN_TTL = time.Hour * 48
+	// MIN_PASSWORD_LENGTH is the length of the shortest password a User may choose
+	MIN_PASSWORD_LENGTH = 8
+	// REPORT_RANGE is how far back reports look if no `from` date is requested
+	REPORT_RANGE = time.Hour * 24 * 30
+	// COOLER_MAX_AGE is how long clients may cache the public lineup of Flavors at each Store
+	COOLER_MAX_AGE = time.Second * 30
 )
 
-func init() {
-	privKey, err := getSignKey()
-	fatal(err)
-
-	signKey = privKey
-	verifyKey = &privKey.PublicKey
-}
-
 func generateToken(user *models.User) (string, error) {
 	claims := Claims{
 		user.UUID.String(),
 		user.Permissions,
+		user.Roles,
 		jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(time.Hour * 12).Unix(),
+			Id:        uuid.New().String(),
+			ExpiresAt: time.Now().Add(ACCESS_TOKEN_TTL).Unix(),
 			Issuer:    "morellisicecream.com",
 		},
 	}
 
+	sk := signingKeys.Signing()
+	if sk == nil {
+		return "", fmt.Errorf("No signing key found")
+	}
+
 	t := jwt.NewWithClaims(jwt.GetSigningMethod("RS256"), claims)
+	t.Header["kid"] = sk.ID
 
-	tokenString, err := t.SignedString(signKey)
+	tokenString, err := t.SignedString(sk.Key)
 
 	if err != nil {
 		return "", err
@@ -59,12 +90,7 @@ func generateToken(user *models.User) (string, error) {
 
 func verifyToken(tokenString string) (*Claims, error) {
 	c := &Claims{}
-	token, err := jwt.ParseWithClaims(tokenString, c, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
-			return nil, fmt.Errorf("No RSA signing method found")
-		}
-		return verifyKey, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, c, signingKeys.keyFunc)
 
 	if err != nil {
 		return nil, err
@@ -77,6 +103,48 @@ func verifyToken(tokenString string) (*Claims, error) {
 	return nil, fmt.Errorf("Unable to verify token")
 }
 
+// issueTokens responds with a new JWT for the User, and a refresh token which can be exchanged
+// for another once it expires.
+func (app *application) issueTokens(w http.ResponseWriter, user *models.User) {
+	token, err := generateToken(user)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	claims, err := verifyToken(token)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	refreshToken, err := randomToken()
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	err = app.users.SaveRefreshToken(refreshToken, user.ID, REFRESH_TOKEN_TTL)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	response := struct {
+		Token          string    `json:"token"`
+		Expires        time.Time `json:"expires"`
+		RefreshToken   string    `json:"refreshToken"`
+		RefreshExpires time.Time `json:"refreshExpires"`
+	}{
+		token,
+		time.Unix(claims.ExpiresAt, 0),
+		refreshToken,
+		time.Now().Add(REFRESH_TOKEN_TTL),
+	}
+
+	app.jsonResponse(w, response)
+}
+
 func (app *application) badRequest(w http.ResponseWriter, err error) {
 	trace := fmt.Sprintf("%s\n%s", err.Error(), debug.Stack())
 	app.errorLog.Output(2, trace)
@@ -116,22 +184,295 @@ func (app *application) jsonResponse(w http.ResponseWriter, data interface{}) {
 	w.Write(jsonData)
 }
 
+// cachedJSONResponse writes the data as JSON, tagged with an ETag of its content, which clients
+// may cache for maxAge. A client whose If-None-Match header has the ETag already has the data, so
+// it's answered with no content.
+func (app *application) cachedJSONResponse(w http.ResponseWriter, r *http.Request, data interface{}, maxAge time.Duration) {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	sum := sha256.Sum256(jsonData)
+	etag := fmt.Sprintf(`"%s"`, hex.EncodeToString(sum[:16]))
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds())))
+
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsonData)
+}
+
+// etagMatches reports whether the If-None-Match header lists the ETag
+func etagMatches(ifNoneMatch string, etag string) bool {
+	for _, t := range strings.Split(ifNoneMatch, ",") {
+		t = strings.TrimPrefix(strings.TrimSpace(t), "W/")
+		if t == etag || t == "*" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// smsReply replies to the inbound message. Where the provider supports it the reply is the
+// response to the webhook, otherwise it is sent as a separate message and the webhook is
+// answered with no content. Numbers which have opted out get no reply, unless it's the
+// `confirmation` of a STOP, START or HELP, which carriers require to be sent regardless. Those
+// are sent straight through the provider, since the outbox would suppress them.
+func (app *application) smsReply(w http.ResponseWriter, inbound *sms.InboundMessage, body string, confirmation bool) {
+	if !confirmation {
+		suppressed, err := app.suppressions.IsSuppressed(inbound.From)
+		if err != nil {
+			app.serverError(w, err)
+			return
+		}
+		if suppressed {
+			app.infoLog.Printf("Not replying to %s, who has opted out", inbound.From)
+			app.noContentResponse(w)
+			return
+		}
+	}
+
+	if replier, ok := app.provider.(sms.Replier); ok {
+		contentType, b, err := replier.Reply(body)
+		if err != nil {
+			app.serverError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(http.StatusOK)
+		w.Write(b)
+		return
+	}
+
+	sender := app.sender
+	if confirmation {
+		sender = app.provider
+	}
+
+	_, err := sender.Send(context.Background(), inbound.From, body)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.noContentResponse(w)
+}
+
 func (app *application) noContentResponse(w http.ResponseWriter) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
-func getSignKey() (*rsa.PrivateKey, error) {
-	if signKey != nil {
-		return signKey, nil
+// background runs fn in a new goroutine, recovering and logging any panic so that
+// work done after a response has been written can't take down the server.
+func (app *application) background(fn func()) {
+	go func() {
+		defer func() {
+			if err := recover(); err != nil {
+				app.errorLog.Output(2, fmt.Sprintf("%s\n%s", err, debug.Stack()))
+			}
+		}()
+
+		fn()
+	}()
+}
+
+// userByPhone gets the User with the phone number, creating a new verified User if
+// there isn't one. SMS is the only way customers sign up, so a message from an unknown
+// number is a new customer.
+func (app *application) userByPhone(phone string) (*models.User, error) {
+	user, err := app.users.GetByPhone(phone)
+	if err != models.ErrNoRecord {
+		return user, err
 	}
 
-	key, err := rsa.GenerateKey(rand.Reader, 4096)
-	fatal(err)
+	return app.users.Insert(uuid.New(), models.NullString{}, models.NullString{}, models.NullString{}, phone, int(models.USER_STATUS_VERIFIED), uuid.New().String())
+}
+
+// authLinkMessage saves a new single use auth token and one time code for the User and returns
+// a message containing the URL at which they can exchange the token for a JWT, and the code
+// they can enter in the app instead. Each phone number may only request AUTH_REQUEST_LIMIT
+// logins every AUTH_REQUEST_WINDOW.
+func (app *application) authLinkMessage(user *models.User) (string, error) {
+	n, err := app.users.CountAuthRequest(user.Phone, AUTH_REQUEST_WINDOW)
+	if err != nil {
+		return "", err
+	}
+	if n > AUTH_REQUEST_LIMIT {
+		return "You've asked to log in too many times. Please try again in a few minutes.", nil
+	}
 
-	err = key.Validate()
-	fatal(err)
+	token, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+
+	err = app.users.SaveAuthToken(token, int(user.ID))
+	if err != nil {
+		return "", err
+	}
+
+	code, err := authCode()
+	if err != nil {
+		return "", err
+	}
+
+	err = app.users.SaveAuthCode(user.ID, code, AUTH_CODE_TTL)
+	if err != nil {
+		return "", err
+	}
+
+	url := fmt.Sprintf(`%s/auth/%s`, app.baseUrl, token)
+
+	return fmt.Sprintf("access the 🍦 app at: %s\nor enter the code %s", url, code), nil
+}
+
+// alertLowBarrel texts the staff at the Store that the Barrel is running low
+func (app *application) alertLowBarrel(ctx context.Context, store *models.Store, barrel *models.Barrel, estimate inventory.BarrelEstimate) {
+	staff, err := app.users.GetStoreStaff(store.ID)
+	if err != nil {
+		app.errorLog.Output(2, err.Error())
+		return
+	}
+
+	message := fmt.Sprintf("Low barrel at %s: %s in position %d is at %d%%", store.Name, barrel.Flavor.Name, barrel.Position, estimate.Level)
+	if estimate.EmptyAt != nil && estimate.Level > 0 {
+		message = fmt.Sprintf("%s, about %s left", message, time.Until(*estimate.EmptyAt).Round(time.Minute))
+	}
+
+	for _, user := range staff {
+		_, err = app.sender.Send(ctx, user.Phone, message)
+		if err != nil {
+			app.errorLog.Output(2, err.Error())
+		}
+	}
+}
+
+// randomToken returns a random URL safe token
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// sendVerificationEmail saves a new email verification token for the User and emails them the
+// URL at which they can use it to verify their email address.
+func (app *application) sendVerificationEmail(ctx context.Context, user *models.User) error {
+	token, err := randomToken()
+	if err != nil {
+		return err
+	}
+
+	err = app.users.SaveVerificationToken(token, user.ID, VERIFICATION_TOKEN_TTL)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("Please verify your email address for the Morelli's app at:\n\n%s/verify-email/%s\n\nThe link expires in %s.", app.baseUrl, token, VERIFICATION_TOKEN_TTL)
+
+	return app.mailer.Send(ctx, user.Email.String, "Verify your email address", body)
+}
+
+// sendPasswordResetEmail saves a new password reset token for the User and emails them the URL
+// at which they can use it to choose a new password.
+func (app *application) sendPasswordResetEmail(ctx context.Context, user *models.User) error {
+	token, err := randomToken()
+	if err != nil {
+		return err
+	}
+
+	err = app.users.SavePasswordResetToken(token, user.ID, PASSWORD_RESET_TOKEN_TTL)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("Someone asked to reset the password for your Morelli's account. If it was you, choose a new password at:\n\n%s/password-reset/%s\n\nThe link expires in %s. If it wasn't you, you can ignore this email.", app.baseUrl, token, PASSWORD_RESET_TOKEN_TTL)
+
+	return app.mailer.Send(ctx, user.Email.String, "Reset your password", body)
+}
+
+// authCode returns a random one time code of AUTH_CODE_DIGITS digits
+func authCode() (string, error) {
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(AUTH_CODE_DIGITS), nil)
+
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%0*d", AUTH_CODE_DIGITS, n), nil
+}
+
+// auditLogin records the attempt to log in made by the request. Failing to record it is logged
+// but doesn't fail the request.
+func (app *application) auditLogin(r *http.Request, attempt *models.LoginAttempt) {
+	attempt.RemoteAddr = r.RemoteAddr
+
+	_, err := app.loginAttempts.Insert(attempt)
+	if err != nil {
+		app.errorLog.Output(2, err.Error())
+	}
+
+	if !attempt.Success {
+		app.infoLog.Printf("Failed %s login from %s: %s", attempt.Method, attempt.RemoteAddr, attempt.Reason)
+	}
+}
+
+// reportRange returns the date range a report covers, from the `from` and `to` query params.
+// Each may be a date, covering the whole day, or an RFC 3339 time. The range ends now, and
+// begins REPORT_RANGE before its end, if they're not given.
+func reportRange(r *http.Request) (time.Time, time.Time, error) {
+	params := r.URL.Query()
+	to := time.Now()
+	var err error
+
+	if t := params.Get("to"); t != "" {
+		to, err = parseReportTime(t, true)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid to: %w", err)
+		}
+	}
+
+	from := to.Add(-REPORT_RANGE)
+
+	if f := params.Get("from"); f != "" {
+		from, err = parseReportTime(f, false)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid from: %w", err)
+		}
+	}
+
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, errors.New("from must be before to")
+	}
+
+	return from, to, nil
+}
+
+// parseReportTime parses a date or an RFC 3339 time. A date is the start of the day, or the end
+// of it if `end` is true.
+func parseReportTime(s string, end bool) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		if end {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
 
-	return key, nil
+	return time.Parse(time.RFC3339, s)
 }
 
 func fatal(err error) {
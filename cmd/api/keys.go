@@ -0,0 +1,368 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+const (
+	// RSA_KEY_BITS is the size of the RSA keys generated to sign JWTs
+	RSA_KEY_BITS = 4096
+	// DEFAULT_KEY_ID identifies a key loaded from the environment if JWT_KEY_ID isn't set
+	DEFAULT_KEY_ID = "default"
+	// KEY_ID_FORMAT formats the time a key was generated as its key ID
+	KEY_ID_FORMAT = "20060102T150405.000Z"
+)
+
+// signingKeys are the keys JWTs are signed and verified with, loaded from the environment by
+// main.
+var signingKeys *KeySet
+
+// SigningKey is an RSA key used to sign JWTs, identified in their `kid` header by ID.
+type SigningKey struct {
+	ID      string
+	Key     *rsa.PrivateKey
+	Created time.Time
+}
+
+// KeySet holds the keys JWTs are signed and verified with. The newest key signs new JWTs, and
+// older keys verify the JWTs they signed until they are retired.
+//
+// If the KeySet has a directory, each key is stored there as a PEM file named by its key ID, so
+// keys survive restarts and are shared by every process reading the directory.
+type KeySet struct {
+	mu   sync.RWMutex
+	dir  string
+	bits int
+	keys []*SigningKey
+}
+
+// NewKeySet returns an empty KeySet storing its keys in dir, which may be empty to keep them in
+// memory, and generating keys of `bits` bits.
+func NewKeySet(dir string, bits int) *KeySet {
+	return &KeySet{dir: dir, bits: bits}
+}
+
+// loadKeySet returns the KeySet configured by the environment. Keys are read from the PEM files
+// in JWT_KEYS_DIR, or from the PEM encoded JWT_PRIVATE_KEY identified by JWT_KEY_ID. If neither
+// is set, a key is generated which only lasts until the process exits.
+func loadKeySet() (*KeySet, error) {
+	ks := NewKeySet(os.Getenv("JWT_KEYS_DIR"), RSA_KEY_BITS)
+
+	if ks.dir != "" {
+		err := ks.Load()
+		if err != nil {
+			return nil, err
+		}
+		if len(ks.keys) == 0 {
+			_, err = ks.Rotate()
+		}
+		return ks, err
+	}
+
+	if pemKey := os.Getenv("JWT_PRIVATE_KEY"); pemKey != "" {
+		key, err := parsePrivateKey([]byte(pemKey))
+		if err != nil {
+			return nil, err
+		}
+
+		id := os.Getenv("JWT_KEY_ID")
+		if id == "" {
+			id = DEFAULT_KEY_ID
+		}
+
+		ks.Add(&SigningKey{ID: id, Key: key, Created: time.Now()})
+		return ks, nil
+	}
+
+	_, err := ks.Rotate()
+	return ks, err
+}
+
+// Load replaces the keys in the KeySet with those stored in its directory
+func (ks *KeySet) Load() error {
+	files, err := filepath.Glob(filepath.Join(ks.dir, "*.pem"))
+	if err != nil {
+		return err
+	}
+
+	keys := []*SigningKey{}
+
+	for _, f := range files {
+		info, err := os.Stat(f)
+		if err != nil {
+			return err
+		}
+
+		b, err := ioutil.ReadFile(f)
+		if err != nil {
+			return err
+		}
+
+		key, err := parsePrivateKey(b)
+		if err != nil {
+			return fmt.Errorf("%s: %w", f, err)
+		}
+
+		// Keys generated by Rotate are identified by the time they were generated
+		id := strings.TrimSuffix(filepath.Base(f), ".pem")
+		created, err := time.Parse(KEY_ID_FORMAT, id)
+		if err != nil {
+			created = info.ModTime()
+		}
+
+		keys = append(keys, &SigningKey{
+			ID:      id,
+			Key:     key,
+			Created: created,
+		})
+	}
+
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[i].Created.Before(keys[j].Created)
+	})
+
+	ks.mu.Lock()
+	ks.keys = keys
+	ks.mu.Unlock()
+
+	return nil
+}
+
+// Add adds the key to the KeySet. It signs new JWTs if it is the newest key.
+func (ks *KeySet) Add(key *SigningKey) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	ks.keys = append(ks.keys, key)
+	sort.SliceStable(ks.keys, func(i, j int) bool {
+		return ks.keys[i].Created.Before(ks.keys[j].Created)
+	})
+}
+
+// Rotate generates a new key to sign new JWTs with, storing it in the KeySet's directory.
+func (ks *KeySet) Rotate() (*SigningKey, error) {
+	key, err := rsa.GenerateKey(rand.Reader, ks.bits)
+	if err != nil {
+		return nil, err
+	}
+
+	created := time.Now()
+	sk := &SigningKey{
+		ID:      created.UTC().Format(KEY_ID_FORMAT),
+		Key:     key,
+		Created: created,
+	}
+
+	if ks.dir != "" {
+		b := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+		err = ioutil.WriteFile(filepath.Join(ks.dir, sk.ID+".pem"), b, 0600)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ks.Add(sk)
+
+	return sk, nil
+}
+
+// Retire removes the keys which stopped signing JWTs more than `after` ago, once every JWT they
+// signed has expired.
+func (ks *KeySet) Retire(after time.Duration) error {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	keys := []*SigningKey{}
+
+	for i, k := range ks.keys {
+		if i < len(ks.keys)-1 && time.Since(ks.keys[i+1].Created) > after {
+			if ks.dir != "" {
+				err := os.Remove(filepath.Join(ks.dir, k.ID+".pem"))
+				if err != nil && !os.IsNotExist(err) {
+					return err
+				}
+			}
+			continue
+		}
+		keys = append(keys, k)
+	}
+
+	ks.keys = keys
+
+	return nil
+}
+
+// Signing returns the key new JWTs are signed with
+func (ks *KeySet) Signing() *SigningKey {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	if len(ks.keys) == 0 {
+		return nil
+	}
+
+	return ks.keys[len(ks.keys)-1]
+}
+
+// Verifying returns the public key identified by kid
+func (ks *KeySet) Verifying(kid string) (*rsa.PublicKey, bool) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	for _, k := range ks.keys {
+		if k.ID == kid {
+			return &k.Key.PublicKey, true
+		}
+	}
+
+	return nil, false
+}
+
+// JSONWebKey is the public half of a SigningKey, as published in a JWKS
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	ID        string `json:"kid"`
+	Modulus   string `json:"n"`
+	Exponent  string `json:"e"`
+}
+
+// JWKS returns the public keys JWTs signed by the KeySet can be verified with
+func (ks *KeySet) JWKS() []JSONWebKey {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	jwks := []JSONWebKey{}
+
+	for _, k := range ks.keys {
+		jwks = append(jwks, JSONWebKey{
+			KeyType:   "RSA",
+			Use:       "sig",
+			Algorithm: "RS256",
+			ID:        k.ID,
+			Modulus:   base64.RawURLEncoding.EncodeToString(k.Key.PublicKey.N.Bytes()),
+			Exponent:  base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.Key.PublicKey.E)).Bytes()),
+		})
+	}
+
+	return jwks
+}
+
+// keyFunc returns the key a JWT is verified with, identified by its `kid` header. JWTs issued
+// without one are verified with the current signing key.
+func (ks *KeySet) keyFunc(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+		return nil, fmt.Errorf("No RSA signing method found")
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		sk := ks.Signing()
+		if sk == nil {
+			return nil, fmt.Errorf("No signing key found")
+		}
+		return &sk.Key.PublicKey, nil
+	}
+
+	key, ok := ks.Verifying(kid)
+	if !ok {
+		return nil, fmt.Errorf("No key found for kid %q", kid)
+	}
+
+	return key, nil
+}
+
+// parsePrivateKey parses a PEM encoded PKCS #1 or PKCS #8 RSA private key
+func parsePrivateKey(b []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM encoded key found")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("not an RSA private key")
+	}
+
+	return rsaKey, nil
+}
+
+// runKeyRotation rotates the signing keys once the signing key is older than `every`, checking
+// every `interval` until ctx is cancelled. Keys rotated by other processes sharing the keys
+// directory are loaded first, and keys are retired once the JWTs they signed have expired.
+func (app *application) runKeyRotation(ctx context.Context, interval time.Duration, every time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		app.rotateKeys(every)
+	}
+}
+
+// rotateKeys rotates the signing keys if the signing key is older than `every`
+func (app *application) rotateKeys(every time.Duration) {
+	err := signingKeys.Load()
+	if err != nil {
+		app.errorLog.Output(2, err.Error())
+		return
+	}
+
+	if sk := signingKeys.Signing(); sk == nil || time.Since(sk.Created) > every {
+		sk, err = signingKeys.Rotate()
+		if err != nil {
+			app.errorLog.Output(2, err.Error())
+			return
+		}
+		app.infoLog.Printf("Rotated JWT signing key to %s", sk.ID)
+	}
+
+	err = signingKeys.Retire(ACCESS_TOKEN_TTL + time.Minute)
+	if err != nil {
+		app.errorLog.Output(2, err.Error())
+	}
+}
+
+// jwks publishes the public keys JWTs are verified with, so other services can verify them
+func (app *application) jwks(w http.ResponseWriter, r *http.Request) {
+	response := struct {
+		Keys []JSONWebKey `json:"keys"`
+	}{
+		signingKeys.JWKS(),
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	app.jsonResponse(w, response)
+}
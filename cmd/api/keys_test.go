@@ -0,0 +1,143 @@
+package main
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jcorry/morellis/pkg/models"
+)
+
+const TEST_KEY_BITS = 1024
+
+// withSigningKeys replaces the signingKeys for the duration of the test
+func withSigningKeys(t *testing.T, ks *KeySet) {
+	prev := signingKeys
+	signingKeys = ks
+	t.Cleanup(func() {
+		signingKeys = prev
+	})
+}
+
+func TestKeySet_Rotate(t *testing.T) {
+	ks := NewKeySet("", TEST_KEY_BITS)
+	withSigningKeys(t, ks)
+
+	first, err := ks.Rotate()
+	require.NoError(t, err)
+
+	token, err := generateToken(&models.User{UUID: uuid.New()})
+	require.NoError(t, err)
+
+	second, err := ks.Rotate()
+	require.NoError(t, err)
+	require.NotEqual(t, first.ID, second.ID)
+	require.Equal(t, second, ks.Signing())
+
+	t.Run("tokens signed with the previous key are valid", func(t *testing.T) {
+		_, err := verifyToken(token)
+		require.NoError(t, err)
+	})
+
+	t.Run("new tokens are signed with the new key", func(t *testing.T) {
+		newToken, err := generateToken(&models.User{UUID: uuid.New()})
+		require.NoError(t, err)
+
+		parsed, _, err := new(jwt.Parser).ParseUnverified(newToken, &Claims{})
+		require.NoError(t, err)
+		require.Equal(t, second.ID, parsed.Header["kid"])
+	})
+
+	t.Run("retired keys don't verify tokens", func(t *testing.T) {
+		require.NoError(t, ks.Retire(0))
+		require.Len(t, ks.JWKS(), 1)
+
+		_, err := verifyToken(token)
+		require.Error(t, err)
+	})
+}
+
+func TestKeySet_Load(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keys")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ks := NewKeySet(dir, TEST_KEY_BITS)
+	first, err := ks.Rotate()
+	require.NoError(t, err)
+	second, err := ks.Rotate()
+	require.NoError(t, err)
+
+	// Another process sharing the directory loads the same keys
+	loaded := NewKeySet(dir, TEST_KEY_BITS)
+	require.NoError(t, loaded.Load())
+
+	require.Len(t, loaded.JWKS(), 2)
+	require.Equal(t, second.ID, loaded.Signing().ID)
+	require.Equal(t, second.Key.N, loaded.Signing().Key.N)
+
+	_, ok := loaded.Verifying(first.ID)
+	require.True(t, ok)
+
+	require.NoError(t, ks.Retire(0))
+	require.NoError(t, loaded.Load())
+	require.Len(t, loaded.JWKS(), 1)
+}
+
+func TestKeySet_Retire(t *testing.T) {
+	ks := NewKeySet("", TEST_KEY_BITS)
+	_, err := ks.Rotate()
+	require.NoError(t, err)
+	_, err = ks.Rotate()
+	require.NoError(t, err)
+
+	// The previous key stopped signing just now, so its tokens are still valid
+	require.NoError(t, ks.Retire(time.Hour))
+	require.Len(t, ks.JWKS(), 2)
+
+	// The signing key is never retired
+	require.NoError(t, ks.Retire(0))
+	require.Len(t, ks.JWKS(), 1)
+}
+
+func TestJwks(t *testing.T) {
+	ks := NewKeySet("", TEST_KEY_BITS)
+	withSigningKeys(t, ks)
+
+	sk, err := ks.Rotate()
+	require.NoError(t, err)
+
+	app, _ := newFakeApplication()
+
+	req := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	res := NewFakeResponse(t)
+	app.jwks(res, req)
+
+	require.Equal(t, http.StatusOK, res.status)
+
+	var body struct {
+		Keys []JSONWebKey `json:"keys"`
+	}
+	require.NoError(t, json.Unmarshal(res.body, &body))
+	require.Len(t, body.Keys, 1)
+	require.Equal(t, sk.ID, body.Keys[0].ID)
+
+	n, err := base64.RawURLEncoding.DecodeString(body.Keys[0].Modulus)
+	require.NoError(t, err)
+	e, err := base64.RawURLEncoding.DecodeString(body.Keys[0].Exponent)
+	require.NoError(t, err)
+
+	key := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	require.Equal(t, sk.Key.PublicKey, *key)
+}
@@ -1,12 +1,14 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"time"
+	_ "time/tzdata"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/golang-migrate/migrate/v4"
@@ -16,21 +18,31 @@ import (
 	"github.com/joho/godotenv"
 
 	_ "github.com/go-sql-driver/mysql"
+	"github.com/jcorry/morellis/pkg/email"
 	"github.com/jcorry/morellis/pkg/models"
 	repo "github.com/jcorry/morellis/pkg/models/mysql"
+	"github.com/jcorry/morellis/pkg/notify"
 	"github.com/jcorry/morellis/pkg/sms"
 )
 
 type application struct {
-	errorLog    *log.Logger
-	infoLog     *log.Logger
-	users       models.UserRepository
-	stores      models.StoreRepository
-	flavors     models.FlavorRepository
-	ingredients models.IngredientRepository
-	sender      sms.Messager
-	baseUrl     string
-	mapsApiKey  string
+	errorLog      *log.Logger
+	infoLog       *log.Logger
+	users         models.UserRepository
+	messages      models.MessageRepository
+	suppressions  models.SuppressionRepository
+	notifications models.NotificationRepository
+	stores        models.StoreRepository
+	flavors       models.FlavorRepository
+	ingredients   models.IngredientRepository
+	loginAttempts models.LoginAttemptRepository
+	inventory     models.InventoryRepository
+	provider      sms.Provider
+	sender        sms.Messager
+	mailer        email.Mailer
+	notifier      *notify.Dispatcher
+	baseUrl       string
+	mapsApiKey    string
 }
 
 func main() {
@@ -46,6 +58,12 @@ func main() {
 	infoLog := log.New(os.Stdout, "INFO\t", log.Ldate|log.Ltime)
 	errorLog := log.New(os.Stdout, "ERROR\t", log.Ldate|log.Ltime|log.Lshortfile)
 
+	// Load the keys JWTs are signed with
+	signingKeys, err = loadKeySet()
+	if err != nil {
+		errorLog.Fatal(err)
+	}
+
 	db, err := openDB(dsn)
 
 	if err != nil {
@@ -80,20 +98,60 @@ func main() {
 		DB:       0,
 	})
 
-	// Initialize Twilio Client
-	client := &http.Client{}
-	sender := sms.NewTwilioMessager(client, os.Getenv("TWILIO_SID"), os.Getenv("TWILIO_AUTH_TOKEN"), os.Getenv("TWILIO_NUMBER"))
+	// Initialize the SMS provider
+	provider, err := newSMSProvider(&http.Client{}, os.Getenv("SMS_PROVIDER"))
+	if err != nil {
+		errorLog.Fatal(err)
+	}
+
+	users := &repo.UserModel{DB: db, Redis: rdb}
+	messages := &repo.MessageModel{DB: db}
+	suppressions := &repo.SuppressionModel{DB: db}
+	notifications := &repo.NotificationModel{DB: db}
+
+	// Outbound messages are queued in the outbox and sent by the worker pool. Numbers
+	// which have opted out are refused as the worker sends to the provider.
+	sender := notify.NewOutbox(messages)
+	worker := notify.NewWorker(messages, sms.NewSuppressingMessager(provider, suppressions), errorLog)
+	go worker.Run(context.Background())
+
+	// Notifications held back by Users' notification policies are sent as their windows open
+	notifier := notify.NewDispatcher(users, notifications, sender, errorLog)
+	go notifier.Run(context.Background(), time.Minute)
 
 	app := &application{
-		errorLog:    errorLog,
-		infoLog:     infoLog,
-		users:       &repo.UserModel{DB: db, Redis: rdb},
-		stores:      &repo.StoreModel{DB: db},
-		flavors:     &repo.FlavorModel{DB: db},
-		ingredients: &repo.IngredientModel{DB: db},
-		mapsApiKey:  mapsApiKey,
-		sender:      sender,
-		baseUrl:     os.Getenv("HOST"),
+		errorLog:      errorLog,
+		infoLog:       infoLog,
+		users:         users,
+		messages:      messages,
+		suppressions:  suppressions,
+		notifications: notifications,
+		provider:      provider,
+		stores:        &repo.StoreModel{DB: db},
+		flavors:       &repo.FlavorModel{DB: db},
+		ingredients:   &repo.IngredientModel{DB: db},
+		loginAttempts: &repo.LoginAttemptModel{DB: db},
+		inventory:     &repo.InventoryModel{DB: db},
+		mapsApiKey:    mapsApiKey,
+		sender:        sender,
+		mailer:        email.NewSMTPMailer(os.Getenv("SMTP_ADDRESS"), os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"), os.Getenv("SMTP_FROM")),
+		notifier:      notifier,
+		baseUrl:       os.Getenv("HOST"),
+	}
+
+	// Users who chose a daily or weekly notification frequency are sent digests as they come due
+	go app.runDigestScheduler(context.Background(), time.Minute)
+
+	// Signing keys stored in JWT_KEYS_DIR are rotated every JWT_KEY_ROTATION, eg. "720h"
+	if rotation := os.Getenv("JWT_KEY_ROTATION"); rotation != "" {
+		every, err := time.ParseDuration(rotation)
+		if err != nil {
+			errorLog.Fatal(err)
+		}
+		if os.Getenv("JWT_KEYS_DIR") == "" {
+			errorLog.Fatal("JWT_KEY_ROTATION requires JWT_KEYS_DIR")
+		}
+		go app.runKeyRotation(context.Background(), time.Minute, every)
 	}
 
 	c := cors.New(cors.Options{
@@ -120,6 +178,24 @@ func main() {
 	errorLog.Fatal(err)
 }
 
+// newSMSProvider configures the SMS provider named by `name`, one of "twilio" or "vonage",
+// from the environment. Twilio is used if no provider is named.
+func newSMSProvider(c *http.Client, name string) (sms.Provider, error) {
+	statusCallback := fmt.Sprintf("%s/webhooks/v1/sms/status", os.Getenv("HOST"))
+
+	switch name {
+	case "", "twilio":
+		return sms.NewTwilioMessager(c, os.Getenv("TWILIO_SID"), os.Getenv("TWILIO_AUTH_TOKEN"), os.Getenv("TWILIO_NUMBER")).
+			WithWebhookHost(os.Getenv("HOST")).
+			WithStatusCallback(statusCallback), nil
+	case "vonage":
+		return sms.NewVonageMessager(c, os.Getenv("VONAGE_API_KEY"), os.Getenv("VONAGE_API_SECRET"), os.Getenv("VONAGE_SIGNATURE_SECRET"), os.Getenv("VONAGE_NUMBER")).
+			WithStatusCallback(statusCallback), nil
+	}
+
+	return nil, fmt.Errorf("unknown SMS_PROVIDER %q", name)
+}
+
 // openDB opens a DB connection using for a dsn
 func openDB(dsn string) (*sql.DB, error) {
 	db, err := sql.Open("mysql", dsn)
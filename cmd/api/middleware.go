@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/dgrijalva/jwt-go"
@@ -14,16 +15,33 @@ var ContextKeyUser = "AuthUser"
 type PermissionsCheck struct {
 	handler     http.Handler
 	permissions []string
+	storeScoped bool
 }
 
 func NewPermissionsCheck(handler http.Handler, permissions []string) *PermissionsCheck {
-	return &PermissionsCheck{handler, permissions}
+	return &PermissionsCheck{handler, permissions, false}
+}
+
+// NewStorePermissionsCheck returns a PermissionsCheck for routes about a single Store, identified
+// by the :storeID or :id route param, which also honours the roles granted at that Store.
+func NewStorePermissionsCheck(handler http.Handler, permissions []string) *PermissionsCheck {
+	return &PermissionsCheck{handler, permissions, true}
 }
 
 func (pc *PermissionsCheck) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	claims := r.Context().Value(ContextKeyUser).(*Claims)
 	reqUUID := r.URL.Query().Get(":uuid")
-	if !checkPermissions(claims, pc.permissions, reqUUID) {
+
+	var storeID int64
+	if pc.storeScoped {
+		param := r.URL.Query().Get(":storeID")
+		if param == "" {
+			param = r.URL.Query().Get(":id")
+		}
+		storeID, _ = strconv.ParseInt(param, 10, 64)
+	}
+
+	if !checkPermissions(claims, pc.permissions, reqUUID, storeID) {
 		w.WriteHeader(http.StatusUnauthorized)
 		return
 	}
@@ -38,13 +56,7 @@ func (app *application) jwtVerification(next http.Handler) http.Handler {
 			bearerToken := strings.Split(authHeader, " ")
 			if len(bearerToken) == 2 {
 				claims := &Claims{}
-				token, err := jwt.ParseWithClaims(bearerToken[1], claims, func(token *jwt.Token) (interface{}, error) {
-					if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
-						return nil, fmt.Errorf("No RSA signing method found")
-					}
-
-					return verifyKey, nil
-				})
+				token, err := jwt.ParseWithClaims(bearerToken[1], claims, signingKeys.keyFunc)
 
 				if err != nil {
 					app.errorLog.Output(2, err.Error())
@@ -53,6 +65,16 @@ func (app *application) jwtVerification(next http.Handler) http.Handler {
 				}
 
 				if token.Valid {
+					revoked, err := app.users.IsTokenRevoked(claims.Id)
+					if err != nil {
+						app.serverError(w, err)
+						return
+					}
+					if revoked {
+						w.WriteHeader(http.StatusUnauthorized)
+						return
+					}
+
 					ctx := context.WithValue(r.Context(), ContextKeyUser, claims)
 					next.ServeHTTP(w, r.WithContext(ctx))
 					return
@@ -93,20 +115,21 @@ func (app *application) recoverPanic(next http.Handler) http.Handler {
 	})
 }
 
-func checkPermissions(c *Claims, permissions []string, reqUUID string) bool {
-	for _, userPermission := range c.Permissions {
-		for _, requiredPermission := range permissions {
-			if userPermission.Permission.Name == requiredPermission {
-				if requiredPermission == "user:read" || requiredPermission == "user:write" {
-					return true
-				}
+// checkPermissions reports whether the permissions and roles in the Claims satisfy any of
+// `permissions` under the defaultPolicy, for a request about the User identified by reqUUID and
+// the Store identified by storeID. Roles granted at a single Store only apply when storeID
+// identifies that Store.
+func checkPermissions(c *Claims, permissions []string, reqUUID string, storeID int64) bool {
+	granted := make([]string, 0, len(c.Permissions)+len(c.Roles))
+	for _, up := range c.Permissions {
+		granted = append(granted, up.Permission.Name)
+	}
 
-				if (requiredPermission == "self:read" || requiredPermission == "self:write") &&
-					c.UUID == reqUUID {
-					return true
-				}
-			}
+	for _, ur := range c.Roles {
+		if ur.StoreID == 0 || (storeID > 0 && ur.StoreID == storeID) {
+			granted = append(granted, ur.Role)
 		}
 	}
-	return false
+
+	return defaultPolicy.Allows(granted, permissions, c.UUID, reqUUID)
 }
@@ -235,3 +235,83 @@ func UserRouter(handler http.Handler) *pat.PatternServeMux {
 	mux.Get("/testing/:uuid", handler)
 	return mux
 }
+
+func TestNewStorePermissionsCheck(t *testing.T) {
+	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+	})
+
+	tests := []struct {
+		name       string
+		roles      []models.UserRole
+		path       string
+		permission []string
+		wantCode   int
+	}{
+		{"Staff at the Store", []models.UserRole{{Role: models.ROLE_STAFF, StoreID: 3}}, "/store/3/flavor/12", []string{"store:write", "store:activate"}, 200},
+		{"Staff at another Store", []models.UserRole{{Role: models.ROLE_STAFF, StoreID: 4}}, "/store/3/flavor/12", []string{"store:write", "store:activate"}, 401},
+		{"Staff editing the Store", []models.UserRole{{Role: models.ROLE_STAFF, StoreID: 3}}, "/store/3", []string{"store:write"}, 401},
+		{"Manager editing the Store", []models.UserRole{{Role: models.ROLE_MANAGER, StoreID: 3}}, "/store/3", []string{"store:write"}, 200},
+		{"Manager editing another Store", []models.UserRole{{Role: models.ROLE_MANAGER, StoreID: 3}}, "/store/4", []string{"store:write"}, 401},
+		{"Staff at every Store", []models.UserRole{{Role: models.ROLE_STAFF}}, "/store/4/flavor/12", []string{"store:write", "store:activate"}, 200},
+		{"Admin", []models.UserRole{{Role: models.ROLE_ADMIN}}, "/store/4", []string{"store:write"}, 200},
+		{"No Roles", nil, "/store/3/flavor/12", []string{"store:write", "store:activate"}, 401},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handlerToTest := NewStorePermissionsCheck(nextHandler, tt.permission)
+
+			mux := pat.New()
+			mux.Get("/store/:id", handlerToTest)
+			mux.Get("/store/:storeID/flavor/:flavorID", handlerToTest)
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			claims := Claims{
+				UUID:  uuid.New().String(),
+				Roles: tt.roles,
+			}
+			ctx := context.WithValue(req.Context(), ContextKeyUser, &claims)
+
+			w := httptest.NewRecorder()
+
+			mux.ServeHTTP(w, req.WithContext(ctx))
+			if w.Result().StatusCode != tt.wantCode {
+				t.Errorf("Want code %d, Got code %d", tt.wantCode, w.Result().StatusCode)
+			}
+		})
+	}
+}
+
+func TestJwtVerificationRejectsRevokedToken(t *testing.T) {
+	tests := []struct {
+		name     string
+		revoked  bool
+		wantCode int
+	}{
+		{"Valid token", false, 200},
+		{"Revoked token", true, 401},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, fakes := newFakeApplication()
+			fakes.users.IsTokenRevokedReturns(tt.revoked, nil)
+
+			token, err := generateToken(&models.User{UUID: uuid.New()})
+			require.NoError(t, err)
+
+			nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+			req := httptest.NewRequest(http.MethodGet, "/testing", nil)
+			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+			w := httptest.NewRecorder()
+
+			app.jwtVerification(nextHandler).ServeHTTP(w, req)
+			require.Equal(t, tt.wantCode, w.Result().StatusCode)
+
+			require.Equal(t, 1, fakes.users.IsTokenRevokedCallCount())
+			require.NotEmpty(t, fakes.users.IsTokenRevokedArgsForCall(0))
+		})
+	}
+}
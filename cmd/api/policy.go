@@ -0,0 +1,142 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/jcorry/morellis/pkg/models"
+)
+
+// PERMISSION_ALL grants every other known permission
+const PERMISSION_ALL = "all"
+
+// PERMISSIONS are the permissions known to the Policy, as stored in the `permission` table.
+var PERMISSIONS = []string{
+	"store:read",
+	"store:write",
+	"user:read",
+	"user:write",
+	"flavor:read",
+	"flavor:write",
+	"ingredient:read",
+	"ingredient:write",
+	"self:write",
+	"self:read",
+	"store:activate",
+	"inventory:read",
+	"inventory:write",
+	"report:read",
+	PERMISSION_ALL,
+}
+
+// ROLES are the permissions granted by each role a User may be given. A role granted at a
+// single Store only grants its permissions for requests about that Store.
+var ROLES = map[string][]string{
+	models.ROLE_STAFF:   {"store:read", "flavor:read", "ingredient:read", "store:activate", "inventory:write"},
+	models.ROLE_MANAGER: {models.ROLE_STAFF, "store:write", "report:read"},
+	models.ROLE_ADMIN:   {PERMISSION_ALL},
+}
+
+// defaultPolicy is the Policy used by NewPermissionsCheck
+var defaultPolicy = NewPolicy(PERMISSIONS, ROLES)
+
+// Policy decides whether the permissions granted to a User satisfy those a route requires.
+//
+// Permissions are named "<resource>:<action>". A granted role grants each of its permissions,
+// "all" grants every known permission, and a wildcard like "store:*" grants every action on
+// the resource. "write" on a resource also grants "read". Permissions on the "self" resource
+// are only granted for requests about the User's own account.
+type Policy struct {
+	known map[string]bool
+	roles map[string][]string
+}
+
+// NewPolicy configures and returns a Policy which knows the `known` permissions, and the
+// permissions granted by each role in `roles`. Roles may include other roles.
+func NewPolicy(known []string, roles map[string][]string) *Policy {
+	p := &Policy{
+		known: make(map[string]bool, len(known)),
+		roles: roles,
+	}
+
+	for _, k := range known {
+		p.known[k] = true
+	}
+
+	return p
+}
+
+// Allows reports whether any of the `granted` permissions or roles satisfies any of the
+// `required` permissions, for a request made by the User identified by actorUUID about the
+// User identified by subjectUUID. subjectUUID is empty if the request isn't about a User.
+func (p *Policy) Allows(granted []string, required []string, actorUUID string, subjectUUID string) bool {
+	granted = p.expand(granted, map[string]bool{})
+
+	for _, r := range required {
+		if !p.known[r] {
+			continue
+		}
+
+		if resource, _ := splitPermission(r); resource == "self" && (subjectUUID == "" || subjectUUID != actorUUID) {
+			continue
+		}
+
+		for _, g := range granted {
+			if grants(g, r) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// HasRole reports whether role is one of the roles known to the Policy
+func (p *Policy) HasRole(role string) bool {
+	_, ok := p.roles[role]
+	return ok
+}
+
+// expand replaces the roles in `granted` with the permissions they grant
+func (p *Policy) expand(granted []string, seen map[string]bool) []string {
+	var permissions []string
+
+	for _, g := range granted {
+		if seen[g] {
+			continue
+		}
+		seen[g] = true
+
+		if role, ok := p.roles[g]; ok {
+			permissions = append(permissions, p.expand(role, seen)...)
+			continue
+		}
+
+		permissions = append(permissions, g)
+	}
+
+	return permissions
+}
+
+// grants reports whether the granted permission satisfies the required one
+func grants(granted string, required string) bool {
+	if granted == required || granted == PERMISSION_ALL {
+		return true
+	}
+
+	gr, ga := splitPermission(granted)
+	rr, ra := splitPermission(required)
+	if gr != rr {
+		return false
+	}
+
+	return ga == "*" || (ga == "write" && ra == "read")
+}
+
+func splitPermission(permission string) (resource string, action string) {
+	parts := strings.SplitN(permission, ":", 2)
+	if len(parts) < 2 {
+		return parts[0], ""
+	}
+
+	return parts[0], parts[1]
+}
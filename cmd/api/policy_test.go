@@ -0,0 +1,46 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy_Allows(t *testing.T) {
+	policy := NewPolicy(PERMISSIONS, map[string][]string{
+		"editor":  {"flavor:write", "store:read"},
+		"manager": {"editor", "store:write"},
+	})
+
+	tests := []struct {
+		name        string
+		granted     []string
+		required    []string
+		subjectUUID string
+		want        bool
+	}{
+		{"exact", []string{"store:write"}, []string{"store:write"}, "", true},
+		{"missing", []string{"store:read"}, []string{"store:write"}, "", false},
+		{"any of required", []string{"user:read"}, []string{"user:read", "self:read"}, "other", true},
+		{"write grants read", []string{"flavor:write"}, []string{"flavor:read"}, "", true},
+		{"read doesn't grant write", []string{"flavor:read"}, []string{"flavor:write"}, "", false},
+		{"all", []string{"all"}, []string{"ingredient:write"}, "", true},
+		{"all doesn't grant unknown", []string{"all"}, []string{"foo:bar"}, "", false},
+		{"resource wildcard", []string{"store:*"}, []string{"store:write"}, "", true},
+		{"resource wildcard on another resource", []string{"store:*"}, []string{"flavor:write"}, "", false},
+		{"self", []string{"self:read"}, []string{"self:read"}, "actor", true},
+		{"self about another user", []string{"self:read"}, []string{"self:read"}, "other", false},
+		{"self about no user", []string{"self:write"}, []string{"self:write"}, "", false},
+		{"all about another user", []string{"all"}, []string{"self:read"}, "other", false},
+		{"role", []string{"editor"}, []string{"flavor:write"}, "", true},
+		{"nested role", []string{"manager"}, []string{"flavor:read"}, "", true},
+		{"role without permission", []string{"editor"}, []string{"store:write"}, "", false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, policy.Allows(tt.granted, tt.required, "actor", tt.subjectUUID))
+		})
+	}
+}
@@ -10,10 +10,27 @@ func (app *application) routes() http.Handler {
 	mux := pat.New()
 	// Auth route
 	mux.Post("/api/v1/auth", http.HandlerFunc(app.createAuth))
+	mux.Post("/api/v1/auth/code", http.HandlerFunc(app.authByCode))
+	mux.Post("/api/v1/auth/refresh", http.HandlerFunc(app.refreshAuth))
+	mux.Post("/api/v1/auth/password-reset", http.HandlerFunc(app.requestPasswordReset))
+	mux.Post("/api/v1/auth/password-reset/:token", http.HandlerFunc(app.resetPassword))
+	mux.Post("/api/v1/auth/verify-email/:token", http.HandlerFunc(app.verifyEmail))
+	mux.Post("/api/v1/auth/logout", app.jwtVerification(http.HandlerFunc(app.logout)))
 	mux.Get("/auth/:token", http.HandlerFunc(app.authByToken))
+	// Links emailed to Users
+	mux.Get("/verify-email/:token", http.HandlerFunc(app.verifyEmail))
+	mux.Get("/password-reset/:token", http.HandlerFunc(app.passwordResetForm))
+	mux.Post("/password-reset/:token", http.HandlerFunc(app.resetPassword))
+	mux.Get("/.well-known/jwks.json", http.HandlerFunc(app.jwks))
+
+	// Public routes
+	mux.Get("/api/v1/cooler", http.HandlerFunc(app.listCooler))
+	mux.Get("/api/v1/cooler/:storeID", http.HandlerFunc(app.getCooler))
 
 	// Webhooks
 	mux.Post("/webhooks/v1/sms/auth", http.HandlerFunc(app.smsAuthRequest))
+	mux.Post("/webhooks/v1/sms/inbound", http.HandlerFunc(app.smsInbound))
+	mux.Post("/webhooks/v1/sms/status", http.HandlerFunc(app.smsStatus))
 
 	// User routes
 	mux.Post("/api/v1/user", app.jwtVerification(NewPermissionsCheck(http.HandlerFunc(app.createUser), []string{"user:write", "self:write"})))
@@ -22,20 +39,54 @@ func (app *application) routes() http.Handler {
 	mux.Get("/api/v1/user", app.jwtVerification(NewPermissionsCheck(http.HandlerFunc(app.listUser), []string{"user:read", "self:read"})))
 	mux.Del("/api/v1/user/:uuid", app.jwtVerification(NewPermissionsCheck(http.HandlerFunc(app.deleteUser), []string{"user:write", "self:write"})))
 	mux.Get("/api/v1/user/:uuid/ingredient", app.jwtVerification(NewPermissionsCheck(http.HandlerFunc(app.listUserIngredient), []string{"user:read", "self:read"})))
+	mux.Post("/api/v1/user/:uuid/ingredient", app.jwtVerification(NewPermissionsCheck(http.HandlerFunc(app.addUserIngredient), []string{"user:write", "self:write"})))
+	mux.Get("/api/v1/user/:uuid/flavor", app.jwtVerification(NewPermissionsCheck(http.HandlerFunc(app.listUserFlavor), []string{"user:read", "self:read"})))
+	mux.Post("/api/v1/user/:uuid/flavor", app.jwtVerification(NewPermissionsCheck(http.HandlerFunc(app.addUserFlavor), []string{"user:write", "self:write"})))
+	mux.Del("/api/v1/user/:uuid/flavor/:id", app.jwtVerification(NewPermissionsCheck(http.HandlerFunc(app.removeUserFlavor), []string{"user:write", "self:write"})))
+	mux.Get("/api/v1/user/:uuid/notification-policy", app.jwtVerification(NewPermissionsCheck(http.HandlerFunc(app.getNotificationPolicy), []string{"user:read", "self:read"})))
+	mux.Put("/api/v1/user/:uuid/notification-policy", app.jwtVerification(NewPermissionsCheck(http.HandlerFunc(app.updateNotificationPolicy), []string{"user:write", "self:write"})))
+	mux.Get("/api/v1/user/:uuid/role", app.jwtVerification(NewPermissionsCheck(http.HandlerFunc(app.listUserRole), []string{"user:read", "self:read"})))
+	mux.Post("/api/v1/user/:uuid/role", app.jwtVerification(NewPermissionsCheck(http.HandlerFunc(app.addUserRole), []string{"user:write"})))
+	mux.Del("/api/v1/user/:uuid/role/:id", app.jwtVerification(NewPermissionsCheck(http.HandlerFunc(app.removeUserRole), []string{"user:write"})))
+	mux.Post("/api/v1/user/:uuid/email-verification", app.jwtVerification(NewPermissionsCheck(http.HandlerFunc(app.sendUserVerification), []string{"user:write", "self:write"})))
+	mux.Get("/api/v1/user/:uuid/message", app.jwtVerification(NewPermissionsCheck(http.HandlerFunc(app.listUserMessage), []string{"user:read", "self:read"})))
+
+	// Suppression routes
+	mux.Get("/api/v1/suppression", app.jwtVerification(NewPermissionsCheck(http.HandlerFunc(app.listSuppression), []string{"user:read"})))
+	mux.Post("/api/v1/suppression", app.jwtVerification(NewPermissionsCheck(http.HandlerFunc(app.createSuppression), []string{"user:write"})))
+	mux.Del("/api/v1/suppression/:phone", app.jwtVerification(NewPermissionsCheck(http.HandlerFunc(app.deleteSuppression), []string{"user:write"})))
 
-	// Store routes
+	// Store routes. Stores, flavors and ingredients may be read by any authenticated User. Roles
+	// granted at a single Store apply to the routes about that Store.
 	mux.Get("/api/v1/store", app.jwtVerification(http.HandlerFunc(app.listStore)))
-	mux.Post("/api/v1/store", app.jwtVerification(http.HandlerFunc(app.createStore)))
-	mux.Patch("/api/v1/store/:id", app.jwtVerification(http.HandlerFunc(app.partialUpdateStore)))
-	mux.Put("/api/v1/store/:id", app.jwtVerification(http.HandlerFunc(app.updateStore)))
+	mux.Post("/api/v1/store", app.jwtVerification(NewPermissionsCheck(http.HandlerFunc(app.createStore), []string{"store:write"})))
+	mux.Patch("/api/v1/store/:id", app.jwtVerification(NewStorePermissionsCheck(http.HandlerFunc(app.partialUpdateStore), []string{"store:write"})))
+	mux.Put("/api/v1/store/:id", app.jwtVerification(NewStorePermissionsCheck(http.HandlerFunc(app.updateStore), []string{"store:write"})))
 	mux.Get("/api/v1/store/:id", app.jwtVerification(http.HandlerFunc(app.getStore)))
-	mux.Post("/api/v1/store/:storeID/flavor/:flavorID", app.jwtVerification(http.HandlerFunc(app.activateStoreFlavor)))
-	mux.Del("/api/v1/store/:storeID/flavor/:flavorID", app.jwtVerification(http.HandlerFunc(app.deactivateStoreFlavor)))
+	mux.Put("/api/v1/store/:storeID/flavor", app.jwtVerification(NewStorePermissionsCheck(http.HandlerFunc(app.replaceStoreFlavors), []string{"store:write", "store:activate"})))
+	mux.Post("/api/v1/store/:storeID/flavor/:flavorID", app.jwtVerification(NewStorePermissionsCheck(http.HandlerFunc(app.activateStoreFlavor), []string{"store:write", "store:activate"})))
+	mux.Del("/api/v1/store/:storeID/flavor/:flavorID", app.jwtVerification(NewStorePermissionsCheck(http.HandlerFunc(app.deactivateStoreFlavor), []string{"store:write", "store:activate"})))
+	mux.Get("/api/v1/store/:storeID/layout", app.jwtVerification(http.HandlerFunc(app.getStoreLayout)))
+	mux.Put("/api/v1/store/:storeID/layout", app.jwtVerification(NewStorePermissionsCheck(http.HandlerFunc(app.updateStoreLayout), []string{"store:write"})))
+
+	// Inventory routes
+	mux.Get("/api/v1/store/:storeID/inventory", app.jwtVerification(NewStorePermissionsCheck(http.HandlerFunc(app.listStoreInventory), []string{"inventory:read"})))
+	mux.Put("/api/v1/store/:storeID/inventory/threshold", app.jwtVerification(NewStorePermissionsCheck(http.HandlerFunc(app.updateInventoryThreshold), []string{"store:write"})))
+	mux.Post("/api/v1/store/:storeID/position/:position/level", app.jwtVerification(NewStorePermissionsCheck(http.HandlerFunc(app.recordBarrelLevel), []string{"inventory:write"})))
+
+	// Report routes
+	mux.Get("/api/v1/report/store", app.jwtVerification(NewPermissionsCheck(http.HandlerFunc(app.storeReport), []string{"report:read"})))
+	mux.Get("/api/v1/report/flavor", app.jwtVerification(NewPermissionsCheck(http.HandlerFunc(app.flavorReport), []string{"report:read"})))
+	mux.Get("/api/v1/store/:storeID/report", app.jwtVerification(NewStorePermissionsCheck(http.HandlerFunc(app.storeFlavorReport), []string{"report:read"})))
+	mux.Get("/api/v1/store/:storeID/timeline", app.jwtVerification(NewStorePermissionsCheck(http.HandlerFunc(app.storeTimeline), []string{"report:read"})))
 
 	// Flavor routes
-	mux.Post("/api/v1/flavor", app.jwtVerification(http.HandlerFunc(app.createFlavor)))
+	mux.Post("/api/v1/flavor", app.jwtVerification(NewPermissionsCheck(http.HandlerFunc(app.createFlavor), []string{"flavor:write"})))
 	mux.Get("/api/v1/flavor", app.jwtVerification(http.HandlerFunc(app.listFlavor)))
 	mux.Get("/api/v1/flavor/:id", app.jwtVerification(http.HandlerFunc(app.getFlavor)))
+	mux.Put("/api/v1/flavor/:id", app.jwtVerification(NewPermissionsCheck(http.HandlerFunc(app.updateFlavor), []string{"flavor:write"})))
+	mux.Patch("/api/v1/flavor/:id", app.jwtVerification(NewPermissionsCheck(http.HandlerFunc(app.partialUpdateFlavor), []string{"flavor:write"})))
+	mux.Del("/api/v1/flavor/:id", app.jwtVerification(NewPermissionsCheck(http.HandlerFunc(app.deleteFlavor), []string{"flavor:write"})))
 
 	mux.Get("/api/v1/ingredient", app.jwtVerification(http.HandlerFunc(app.listIngredient)))
 
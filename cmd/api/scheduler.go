@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/jcorry/morellis/pkg/models"
+)
+
+// runDigestScheduler sends the daily and weekly digests which have come due every `interval`
+// until ctx is cancelled.
+func (app *application) runDigestScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		app.sendDigests(ctx)
+	}
+}
+
+// sendDigests sends the daily and weekly digests which are due now.
+func (app *application) sendDigests(ctx context.Context) {
+	for _, frequency := range []models.Frequency{models.FREQUENCY_DAILY, models.FREQUENCY_WEEKLY} {
+		sent, err := app.notifier.SendDigests(ctx, frequency)
+		if err != nil {
+			app.errorLog.Output(2, err.Error())
+		}
+		if sent > 0 {
+			app.infoLog.Printf("Sent %d %s digests", sent, frequency)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+CREATE TABLE `message` (
+    `id` int(11) unsigned NOT NULL AUTO_INCREMENT,
+    `user_id` int(11) unsigned DEFAULT NULL,
+    `phone` varchar(24) NOT NULL,
+    `body` varchar(1600) NOT NULL,
+    `status` varchar(16) NOT NULL DEFAULT 'pending',
+    `attempts` smallint(6) unsigned NOT NULL DEFAULT '0',
+    `next_attempt` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `last_error` text,
+    `sid` varchar(64) DEFAULT NULL,
+    `created` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_message_status_next_attempt` (`status`,`next_attempt`),
+    KEY `idx_message_user_id` (`user_id`),
+    KEY `idx_message_sid` (`sid`),
+    CONSTRAINT `fk_message_user_id_user_id` FOREIGN KEY (`user_id`) REFERENCES `user` (`id`) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
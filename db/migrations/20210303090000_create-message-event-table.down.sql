@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS `message_event`;
+
+ALTER TABLE `message` DROP COLUMN `delivery_status`;
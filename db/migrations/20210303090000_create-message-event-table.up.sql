@@ -0,0 +1,12 @@
+ALTER TABLE `message` ADD COLUMN `delivery_status` varchar(16) DEFAULT NULL AFTER `sid`;
+
+CREATE TABLE `message_event` (
+    `id` int(11) unsigned NOT NULL AUTO_INCREMENT,
+    `message_id` int(11) unsigned NOT NULL,
+    `status` varchar(16) NOT NULL,
+    `error_code` varchar(16) DEFAULT NULL,
+    `created` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_message_event_message_id` (`message_id`),
+    CONSTRAINT `fk_message_event_message_id_message_id` FOREIGN KEY (`message_id`) REFERENCES `message` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
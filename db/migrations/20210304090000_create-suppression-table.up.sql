@@ -0,0 +1,8 @@
+CREATE TABLE `suppression` (
+    `id` int(11) unsigned NOT NULL AUTO_INCREMENT,
+    `phone` varchar(24) NOT NULL,
+    `keyword` varchar(16) NOT NULL DEFAULT '',
+    `created` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_suppression_phone` (`phone`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS `notification`;
+
+DROP TABLE IF EXISTS `notification_policy`;
+
+ALTER TABLE `store` DROP COLUMN `timezone`;
@@ -0,0 +1,28 @@
+ALTER TABLE `store` ADD COLUMN `timezone` varchar(64) NOT NULL DEFAULT 'America/New_York' AFTER `lng`;
+
+CREATE TABLE `notification_policy` (
+    `user_id` int(11) unsigned NOT NULL,
+    `max_per_day` smallint(6) unsigned NOT NULL DEFAULT '0',
+    `min_interval_minutes` smallint(6) unsigned NOT NULL DEFAULT '0',
+    `quiet_start` varchar(5) NOT NULL DEFAULT '',
+    `quiet_end` varchar(5) NOT NULL DEFAULT '',
+    `updated` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`user_id`),
+    CONSTRAINT `fk_notification_policy_user_id_user_id` FOREIGN KEY (`user_id`) REFERENCES `user` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `notification` (
+    `id` int(11) unsigned NOT NULL AUTO_INCREMENT,
+    `user_id` int(11) unsigned NOT NULL,
+    `store_id` int(11) unsigned NOT NULL,
+    `flavor_id` int(11) unsigned NOT NULL,
+    `body` varchar(1600) NOT NULL,
+    `message_ref` varchar(64) DEFAULT NULL,
+    `created` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `sent` datetime DEFAULT NULL,
+    PRIMARY KEY (`id`),
+    KEY `idx_notification_user_id_sent` (`user_id`,`sent`),
+    CONSTRAINT `fk_notification_user_id_user_id` FOREIGN KEY (`user_id`) REFERENCES `user` (`id`) ON DELETE CASCADE,
+    CONSTRAINT `fk_notification_store_id_store_id` FOREIGN KEY (`store_id`) REFERENCES `store` (`id`),
+    CONSTRAINT `fk_notification_flavor_id_flavor_id` FOREIGN KEY (`flavor_id`) REFERENCES `flavor` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1 @@
+ALTER TABLE `user` ADD COLUMN `notification_frequency` varchar(16) NOT NULL DEFAULT 'immediate' AFTER `phone`;
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS `ingredient_user_store`;
+
+ALTER TABLE `ingredient_user`
+    DROP COLUMN `radius_miles`,
+    DROP COLUMN `lng`,
+    DROP COLUMN `lat`;
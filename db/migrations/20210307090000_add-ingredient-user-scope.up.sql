@@ -0,0 +1,13 @@
+ALTER TABLE `ingredient_user`
+    ADD COLUMN `lat` decimal(9,6) DEFAULT NULL AFTER `keyword`,
+    ADD COLUMN `lng` decimal(9,6) DEFAULT NULL AFTER `lat`,
+    ADD COLUMN `radius_miles` decimal(6,2) DEFAULT NULL AFTER `lng`;
+
+CREATE TABLE `ingredient_user_store` (
+    `ingredient_user_id` int(11) unsigned NOT NULL,
+    `store_id` int(11) unsigned NOT NULL,
+    PRIMARY KEY (`ingredient_user_id`,`store_id`),
+    KEY `fk_ingredient_user_store_store_id` (`store_id`),
+    CONSTRAINT `fk_ingredient_user_store_ingredient_user_id` FOREIGN KEY (`ingredient_user_id`) REFERENCES `ingredient_user` (`id`) ON DELETE CASCADE,
+    CONSTRAINT `fk_ingredient_user_store_store_id` FOREIGN KEY (`store_id`) REFERENCES `store` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS `flavor_user`;
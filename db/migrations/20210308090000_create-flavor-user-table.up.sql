@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS `flavor_user` (
+    `id` int(11) unsigned NOT NULL AUTO_INCREMENT,
+    `flavor_id` int(11) unsigned NOT NULL,
+    `user_id` int(11) unsigned NOT NULL,
+    `created` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `deleted` int(11) DEFAULT '0',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_flavor_user_flavor_id_user_id` (`flavor_id`,`user_id`,`deleted`),
+    KEY `fk_flavor_user_user_id` (`user_id`),
+    CONSTRAINT `fk_flavor_user_flavor_id` FOREIGN KEY (`flavor_id`) REFERENCES `flavor` (`id`),
+    CONSTRAINT `fk_flavor_user_user_id` FOREIGN KEY (`user_id`) REFERENCES `user` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
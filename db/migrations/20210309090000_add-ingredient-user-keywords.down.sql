@@ -0,0 +1,12 @@
+ALTER TABLE `ingredient_user` DROP INDEX `uk_ingredient_user_keyword_user_id`;
+
+DELETE FROM `ingredient_user` WHERE `ingredient_id` IS NULL;
+
+ALTER TABLE `ingredient_user` DROP FOREIGN KEY `fk_ingredient_user_ingredient_id`;
+
+ALTER TABLE `ingredient_user`
+    MODIFY COLUMN `ingredient_id` int(11) unsigned NOT NULL,
+    MODIFY COLUMN `keyword` varchar(16) DEFAULT NULL;
+
+ALTER TABLE `ingredient_user`
+    ADD CONSTRAINT `fk_ingredient_user_ingredient_id` FOREIGN KEY (`ingredient_id`) REFERENCES `ingredient` (`id`);
@@ -0,0 +1,12 @@
+ALTER TABLE `ingredient_user` DROP FOREIGN KEY `fk_ingredient_user_ingredient_id`;
+
+ALTER TABLE `ingredient_user`
+    MODIFY COLUMN `ingredient_id` int(11) unsigned DEFAULT NULL,
+    MODIFY COLUMN `keyword` varchar(64) DEFAULT NULL;
+
+ALTER TABLE `ingredient_user`
+    ADD CONSTRAINT `fk_ingredient_user_ingredient_id` FOREIGN KEY (`ingredient_id`) REFERENCES `ingredient` (`id`);
+
+UPDATE `ingredient_user` SET `keyword` = NULL WHERE `keyword` = '';
+
+ALTER TABLE `ingredient_user` ADD UNIQUE KEY `uk_ingredient_user_keyword_user_id` (`keyword`,`user_id`,`deleted`);
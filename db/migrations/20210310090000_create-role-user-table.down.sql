@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS `role_user`;
+
+DELETE FROM `permission_user` WHERE `permission_id` = 12;
+DELETE FROM `permission` WHERE `id` = 12;
@@ -0,0 +1,15 @@
+INSERT INTO `permission` (`id`, `name`) VALUES (12, 'store:activate');
+
+CREATE TABLE `role_user` (
+    `id` int(11) unsigned NOT NULL AUTO_INCREMENT,
+    `user_id` int(11) unsigned NOT NULL,
+    `role` varchar(32) NOT NULL,
+    `store_id` int(11) unsigned DEFAULT NULL,
+    `store_scope` int(11) unsigned AS (IFNULL(`store_id`, 0)) VIRTUAL,
+    `created` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_role_user_user_id_role_store_scope` (`user_id`,`role`,`store_scope`),
+    KEY `fk_role_user_store_id_store_id` (`store_id`),
+    CONSTRAINT `fk_role_user_user_id_user_id` FOREIGN KEY (`user_id`) REFERENCES `user` (`id`) ON DELETE CASCADE,
+    CONSTRAINT `fk_role_user_store_id_store_id` FOREIGN KEY (`store_id`) REFERENCES `store` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,14 @@
+CREATE TABLE `login_attempt` (
+    `id` int(11) unsigned NOT NULL AUTO_INCREMENT,
+    `user_id` int(11) unsigned DEFAULT NULL,
+    `phone` varchar(24) NOT NULL DEFAULT '',
+    `method` varchar(16) NOT NULL,
+    `success` tinyint(1) NOT NULL DEFAULT 0,
+    `reason` varchar(255) NOT NULL DEFAULT '',
+    `remote_addr` varchar(64) NOT NULL DEFAULT '',
+    `created` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_login_attempt_user_id_created` (`user_id`,`created`),
+    KEY `idx_login_attempt_phone_created` (`phone`,`created`),
+    CONSTRAINT `fk_login_attempt_user_id_user_id` FOREIGN KEY (`user_id`) REFERENCES `user` (`id`) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS `inventory_threshold`;
+DROP TABLE IF EXISTS `barrel_level`;
+
+DELETE FROM `permission_user` WHERE `permission_id` IN (13, 14);
+DELETE FROM `permission` WHERE `id` IN (13, 14);
@@ -0,0 +1,21 @@
+INSERT INTO `permission` (`id`, `name`) VALUES (13, 'inventory:read'), (14, 'inventory:write');
+
+CREATE TABLE `barrel_level` (
+    `id` int(11) unsigned NOT NULL AUTO_INCREMENT,
+    `flavor_store_id` int(11) unsigned NOT NULL,
+    `level` tinyint(3) unsigned NOT NULL,
+    `user_id` int(11) unsigned DEFAULT NULL,
+    `created` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_barrel_level_flavor_store_id_created` (`flavor_store_id`,`created`),
+    CONSTRAINT `fk_barrel_level_flavor_store_id_flavor_store_id` FOREIGN KEY (`flavor_store_id`) REFERENCES `flavor_store` (`id`) ON DELETE CASCADE,
+    CONSTRAINT `fk_barrel_level_user_id_user_id` FOREIGN KEY (`user_id`) REFERENCES `user` (`id`) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `inventory_threshold` (
+    `store_id` int(11) unsigned NOT NULL,
+    `low_level` tinyint(3) unsigned NOT NULL,
+    `updated` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`store_id`),
+    CONSTRAINT `fk_inventory_threshold_store_id_store_id` FOREIGN KEY (`store_id`) REFERENCES `store` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
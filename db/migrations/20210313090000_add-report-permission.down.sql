@@ -0,0 +1,4 @@
+ALTER TABLE `flavor_store` DROP INDEX `idx_flavor_store_activated`;
+
+DELETE FROM `permission_user` WHERE `permission_id` = 15;
+DELETE FROM `permission` WHERE `id` = 15;
@@ -0,0 +1,3 @@
+INSERT INTO `permission` (`id`, `name`) VALUES (15, 'report:read');
+
+ALTER TABLE `flavor_store` ADD INDEX `idx_flavor_store_activated` (`activated`);
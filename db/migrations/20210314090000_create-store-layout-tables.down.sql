@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `store_position`;
+DROP TABLE IF EXISTS `store_layout`;
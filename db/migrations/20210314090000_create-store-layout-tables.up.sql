@@ -0,0 +1,16 @@
+CREATE TABLE `store_layout` (
+    `store_id` int(11) unsigned NOT NULL,
+    `positions` tinyint(3) unsigned NOT NULL DEFAULT 12,
+    `updated` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`store_id`),
+    CONSTRAINT `fk_store_layout_store_id_store_id` FOREIGN KEY (`store_id`) REFERENCES `store` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `store_position` (
+    `store_id` int(11) unsigned NOT NULL,
+    `position` tinyint(3) unsigned NOT NULL,
+    `label` varchar(16) DEFAULT NULL,
+    `disabled` tinyint(1) NOT NULL DEFAULT 0,
+    PRIMARY KEY (`store_id`, `position`),
+    CONSTRAINT `fk_store_position_store_id_store_id` FOREIGN KEY (`store_id`) REFERENCES `store` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
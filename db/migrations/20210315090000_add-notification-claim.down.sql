@@ -0,0 +1,4 @@
+ALTER TABLE `notification`
+    DROP KEY `idx_notification_claimed`,
+    DROP COLUMN `claimed_at`,
+    DROP COLUMN `claimed`;
@@ -0,0 +1,4 @@
+ALTER TABLE `notification`
+    ADD COLUMN `claimed` varchar(36) DEFAULT NULL AFTER `message_ref`,
+    ADD COLUMN `claimed_at` datetime DEFAULT NULL AFTER `claimed`,
+    ADD KEY `idx_notification_claimed` (`claimed`);
@@ -0,0 +1,10 @@
+package email
+
+import "context"
+
+// Mailer provides an interface for sending email messages
+//
+//go:generate counterfeiter . Mailer
+type Mailer interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
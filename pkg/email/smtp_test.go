@@ -0,0 +1,109 @@
+package email_test
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jcorry/morellis/pkg/email"
+)
+
+// fakeSMTPServer accepts a single SMTP session on a local port and records the envelope and
+// data of the message it is sent.
+type fakeSMTPServer struct {
+	ln   net.Listener
+	from string
+	to   []string
+	data string
+	done chan struct{}
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := &fakeSMTPServer{ln: ln, done: make(chan struct{})}
+	t.Cleanup(func() {
+		ln.Close()
+	})
+
+	go s.serve()
+
+	return s
+}
+
+func (s *fakeSMTPServer) serve() {
+	defer close(s.done)
+
+	conn, err := s.ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	tp := textproto.NewConn(conn)
+	tp.PrintfLine("220 localhost fake SMTP")
+
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch verb {
+		case "EHLO", "HELO":
+			tp.PrintfLine("250 localhost")
+		case "MAIL":
+			s.from = strings.Trim(strings.TrimPrefix(line, "MAIL FROM:"), "<>")
+			tp.PrintfLine("250 OK")
+		case "RCPT":
+			s.to = append(s.to, strings.Trim(strings.TrimPrefix(line, "RCPT TO:"), "<>"))
+			tp.PrintfLine("250 OK")
+		case "DATA":
+			tp.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			b, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			s.data = string(b)
+			tp.PrintfLine("250 OK")
+		case "QUIT":
+			tp.PrintfLine("221 Bye")
+			return
+		default:
+			tp.PrintfLine("502 Command not implemented")
+		}
+	}
+}
+
+func TestSMTPMailer_Send(t *testing.T) {
+	server := newFakeSMTPServer(t)
+
+	m := email.NewSMTPMailer(server.ln.Addr().String(), "", "", "noreply@morellis.test")
+	err := m.Send(context.TODO(), "alice@example.com", "Reset your password", "Follow this link\nto reset it")
+	require.NoError(t, err)
+
+	<-server.done
+
+	require.Equal(t, "noreply@morellis.test", server.from)
+	require.Equal(t, []string{"alice@example.com"}, server.to)
+
+	headers, err := textproto.NewReader(bufio.NewReader(strings.NewReader(server.data))).ReadMIMEHeader()
+	require.NoError(t, err)
+	require.Equal(t, "alice@example.com", headers.Get("To"))
+	require.Equal(t, "Reset your password", headers.Get("Subject"))
+	require.Contains(t, headers.Get("Content-Type"), "text/plain")
+	require.Contains(t, server.data, "Follow this link\nto reset it")
+}
+
+func TestSMTPMailer_SendInvalidRecipient(t *testing.T) {
+	m := email.NewSMTPMailer("127.0.0.1:0", "", "", "noreply@morellis.test")
+	err := m.Send(context.TODO(), "alice@example.com\r\nBcc: mallory@example.com", "Hi", "Hi")
+	require.Error(t, err)
+}
@@ -0,0 +1,132 @@
+// Package inventory estimates how quickly the Barrels active at a Store are being served, and
+// when they'll be empty, from the fill levels recorded by staff.
+package inventory
+
+import (
+	"sort"
+	"time"
+
+	"github.com/jcorry/morellis/pkg/models"
+)
+
+// FULL is the fill level of a Barrel when it's activated
+const FULL = 100
+
+// BarrelEstimate is the latest fill level of a Barrel, the rate it's being served in percent per hour,
+// and when it's expected to be empty. Rate is 0 and EmptyAt is nil until there are enough levels
+// to estimate them.
+type BarrelEstimate struct {
+	Level    int        `json:"level"`
+	Measured time.Time  `json:"measured"`
+	Rate     float64    `json:"ratePerHour"`
+	EmptyAt  *time.Time `json:"emptyAt,omitempty"`
+	Low      bool       `json:"low"`
+}
+
+// FlavorEstimate totals the BarrelEstimates of the Barrels of a Flavor active at a Store
+type FlavorEstimate struct {
+	Flavor  *models.Flavor `json:"flavor"`
+	Barrels int            `json:"barrels"`
+	Level   int            `json:"level"`
+	Rate    float64        `json:"ratePerHour"`
+	EmptyAt *time.Time     `json:"emptyAt,omitempty"`
+}
+
+// Levels returns the levels of the Barrel, oldest first, beginning with the FULL level it was
+// activated at.
+func Levels(b *models.Barrel) []models.BarrelLevel {
+	levels := []models.BarrelLevel{{BarrelID: b.ID, Level: FULL, Created: b.Activated}}
+
+	return append(levels, b.Levels...)
+}
+
+// Estimate estimates the rate the Barrel is being served from the levels recorded since it was
+// last filled, and when it will be empty at that rate. A level higher than the one before it
+// means the Barrel was topped up, so only later levels are used. `lowLevel` is the level below
+// which the Barrel is low.
+func Estimate(b *models.Barrel, lowLevel int) BarrelEstimate {
+	levels := Levels(b)
+
+	// Levels recorded since the Barrel was last filled
+	start := 0
+	for i := 1; i < len(levels); i++ {
+		if levels[i].Level > levels[i-1].Level {
+			start = i
+		}
+	}
+
+	first := levels[start]
+	last := levels[len(levels)-1]
+
+	e := BarrelEstimate{
+		Level:    last.Level,
+		Measured: last.Created,
+		Low:      last.Level < lowLevel,
+	}
+
+	hours := last.Created.Sub(first.Created).Hours()
+	if hours <= 0 || first.Level <= last.Level {
+		return e
+	}
+
+	e.Rate = float64(first.Level-last.Level) / hours
+	emptyAt := last.Created.Add(time.Duration(float64(last.Level) / e.Rate * float64(time.Hour)))
+	e.EmptyAt = &emptyAt
+
+	return e
+}
+
+// CrossedLow reports whether recording `level` takes the Barrel below `lowLevel`, from at or
+// above it, so staff are alerted once as each Barrel runs low.
+func CrossedLow(b *models.Barrel, level int, lowLevel int) bool {
+	levels := Levels(b)
+	previous := levels[len(levels)-1].Level
+
+	return level < lowLevel && previous >= lowLevel
+}
+
+// ByFlavor totals the BarrelEstimates of the Barrels by Flavor, ordered by when they'll be empty,
+// soonest first, then by Flavor name. Each Flavor is expected to be empty when its total level
+// is served at its total rate, from the latest level measured.
+func ByFlavor(barrels []*models.Barrel, estimates []BarrelEstimate) []*FlavorEstimate {
+	byID := map[int64]*FlavorEstimate{}
+	measured := map[int64]time.Time{}
+	flavors := []*FlavorEstimate{}
+
+	for i, b := range barrels {
+		f, ok := byID[b.Flavor.ID]
+		if !ok {
+			f = &FlavorEstimate{Flavor: b.Flavor}
+			byID[b.Flavor.ID] = f
+			flavors = append(flavors, f)
+		}
+
+		f.Barrels++
+		f.Level += estimates[i].Level
+		f.Rate += estimates[i].Rate
+
+		if estimates[i].Measured.After(measured[b.Flavor.ID]) {
+			measured[b.Flavor.ID] = estimates[i].Measured
+		}
+	}
+
+	for _, f := range flavors {
+		if f.Rate > 0 {
+			emptyAt := measured[f.Flavor.ID].Add(time.Duration(float64(f.Level) / f.Rate * float64(time.Hour)))
+			f.EmptyAt = &emptyAt
+		}
+	}
+
+	sort.SliceStable(flavors, func(i, j int) bool {
+		a, b := flavors[i], flavors[j]
+		if (a.EmptyAt == nil) != (b.EmptyAt == nil) {
+			return a.EmptyAt != nil
+		}
+		if a.EmptyAt != nil && !a.EmptyAt.Equal(*b.EmptyAt) {
+			return a.EmptyAt.Before(*b.EmptyAt)
+		}
+		return a.Flavor.Name < b.Flavor.Name
+	})
+
+	return flavors
+}
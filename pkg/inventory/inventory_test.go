@@ -0,0 +1,149 @@
+package inventory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jcorry/morellis/pkg/models"
+)
+
+func barrel(id int64, flavor *models.Flavor, activated time.Time, levels ...models.BarrelLevel) *models.Barrel {
+	return &models.Barrel{ID: id, Flavor: flavor, Activated: activated, Levels: levels}
+}
+
+func level(l int, at time.Time) models.BarrelLevel {
+	return models.BarrelLevel{Level: l, Created: at}
+}
+
+func TestEstimate(t *testing.T) {
+	activated := time.Date(2021, 3, 12, 11, 0, 0, 0, time.UTC)
+	hour := func(h float64) time.Time {
+		return activated.Add(time.Duration(h * float64(time.Hour)))
+	}
+	vanilla := &models.Flavor{ID: 1, Name: "Vanilla"}
+
+	tests := []struct {
+		name        string
+		barrel      *models.Barrel
+		wantLevel   int
+		wantRate    float64
+		wantEmptyAt *time.Time
+		wantLow     bool
+	}{
+		{
+			name:      "no levels",
+			barrel:    barrel(1, vanilla, activated),
+			wantLevel: FULL,
+		},
+		{
+			name:        "served since activation",
+			barrel:      barrel(1, vanilla, activated, level(80, hour(2))),
+			wantLevel:   80,
+			wantRate:    10,
+			wantEmptyAt: timePtr(hour(10)),
+		},
+		{
+			name:        "topped up",
+			barrel:      barrel(1, vanilla, activated, level(40, hour(2)), level(90, hour(3)), level(60, hour(4))),
+			wantLevel:   60,
+			wantRate:    30,
+			wantEmptyAt: timePtr(hour(6)),
+		},
+		{
+			name:      "just topped up",
+			barrel:    barrel(1, vanilla, activated, level(40, hour(2)), level(90, hour(3))),
+			wantLevel: 90,
+		},
+		{
+			name:        "low",
+			barrel:      barrel(1, vanilla, activated, level(15, hour(17))),
+			wantLevel:   15,
+			wantRate:    5,
+			wantEmptyAt: timePtr(hour(20)),
+			wantLow:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			e := Estimate(tt.barrel, 20)
+
+			require.Equal(t, tt.wantLevel, e.Level)
+			require.InDelta(t, tt.wantRate, e.Rate, 0.0001)
+			require.Equal(t, tt.wantLow, e.Low)
+			if tt.wantEmptyAt == nil {
+				require.Nil(t, e.EmptyAt)
+			} else {
+				require.NotNil(t, e.EmptyAt)
+				require.WithinDuration(t, *tt.wantEmptyAt, *e.EmptyAt, time.Second)
+			}
+		})
+	}
+}
+
+func TestCrossedLow(t *testing.T) {
+	activated := time.Now().Add(-time.Hour * 5)
+	vanilla := &models.Flavor{ID: 1, Name: "Vanilla"}
+
+	tests := []struct {
+		name   string
+		barrel *models.Barrel
+		level  int
+		want   bool
+	}{
+		{"first low level", barrel(1, vanilla, activated), 10, true},
+		{"above the low level", barrel(1, vanilla, activated, level(50, activated.Add(time.Hour))), 30, false},
+		{"falls below the low level", barrel(1, vanilla, activated, level(30, activated.Add(time.Hour))), 15, true},
+		{"already low", barrel(1, vanilla, activated, level(15, activated.Add(time.Hour))), 10, false},
+		{"at the low level", barrel(1, vanilla, activated, level(30, activated.Add(time.Hour))), 20, false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, CrossedLow(tt.barrel, tt.level, 20))
+		})
+	}
+}
+
+func TestByFlavor(t *testing.T) {
+	now := time.Date(2021, 3, 12, 15, 0, 0, 0, time.UTC)
+	vanilla := &models.Flavor{ID: 1, Name: "Vanilla"}
+	pecan := &models.Flavor{ID: 2, Name: "Pecan"}
+	coconut := &models.Flavor{ID: 3, Name: "Coconut"}
+
+	barrels := []*models.Barrel{
+		barrel(1, vanilla, now.Add(-time.Hour*4)),
+		barrel(2, pecan, now.Add(-time.Hour*4)),
+		barrel(3, vanilla, now.Add(-time.Hour*4)),
+		barrel(4, coconut, now.Add(-time.Hour*4)),
+	}
+	estimates := []BarrelEstimate{
+		{Level: 60, Measured: now, Rate: 10},
+		{Level: 50, Measured: now, Rate: 25},
+		{Level: 40, Measured: now.Add(-time.Hour), Rate: 10},
+		{Level: 100, Measured: now.Add(-time.Hour * 4)},
+	}
+
+	flavors := ByFlavor(barrels, estimates)
+	require.Len(t, flavors, 3)
+
+	require.Equal(t, pecan, flavors[0].Flavor)
+	require.WithinDuration(t, now.Add(time.Hour*2), *flavors[0].EmptyAt, time.Second)
+
+	require.Equal(t, vanilla, flavors[1].Flavor)
+	require.Equal(t, 2, flavors[1].Barrels)
+	require.Equal(t, 100, flavors[1].Level)
+	require.InDelta(t, 20, flavors[1].Rate, 0.0001)
+	require.WithinDuration(t, now.Add(time.Hour*5), *flavors[1].EmptyAt, time.Second)
+
+	require.Equal(t, coconut, flavors[2].Flavor)
+	require.Nil(t, flavors[2].EmptyAt)
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
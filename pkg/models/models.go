@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"reflect"
 	"time"
 
@@ -17,9 +18,15 @@ var (
 	ErrDuplicateEmail          = errors.New("models: Duplicate email")
 	ErrDuplicatePhone          = errors.New("models: Duplicate phone")
 	ErrDuplicateFlavor         = errors.New("models: Only one flavor may be active at a position at a time.")
+	ErrFlavorConflict          = errors.New("models: Flavor activation conflicted with a concurrent change")
+	ErrInvalidPosition         = errors.New("models: Not an available Position at the Store")
+	ErrActiveFlavor            = errors.New("models: Flavor is active at a Store")
+	ErrFlavorInUse             = errors.New("models: Flavor has been served and can't be deleted")
 	ErrInvalidPermission       = errors.New("models: Not a valid Permission")
 	ErrDuplicateUserPermission = errors.New("models: User already has that Permission")
 	ErrDuplicateUserIngredient = errors.New("models: User already has that Ingredient")
+	ErrDuplicateUserFlavor     = errors.New("models: User already has that Flavor")
+	ErrDuplicateUserRole       = errors.New("models: User already has that Role")
 	ErrInvalidUser             = errors.New("models: Not a valid User")
 	ErrNoneAffected            = errors.New("models: No rows affected")
 )
@@ -90,9 +97,12 @@ type User struct {
 	LastName    NullString       `json:"lastName"`
 	Email       NullString       `json:"email"`
 	Phone       string           `json:"phone"`
+	Frequency   Frequency        `json:"notificationFrequency"`
 	Status      string           `json:"status"`
 	Permissions []UserPermission `json:"permissions"`
+	Roles       []UserRole       `json:"roles,omitempty"`
 	Ingredients []UserIngredient `json:"ingredients,omitempty"`
+	Flavors     []UserFlavor     `json:"flavors,omitempty"`
 	Password    string           `json:"password,omitempty"`
 	Created     time.Time        `json:"created"`
 }
@@ -100,7 +110,67 @@ type User struct {
 type UserIngredient struct {
 	UserIngredientID int64 `json:"userIngredientId,omitempty"`
 	*Ingredient      `json:"ingredient"`
-	Created          time.Time `json:"created"`
+	Keyword          string            `json:"keyword,omitempty"`
+	Scope            SubscriptionScope `json:"scope"`
+	Created          time.Time         `json:"created"`
+}
+
+// UserFlavor is a User's subscription to a Flavor, notifying them whenever it is activated.
+type UserFlavor struct {
+	UserFlavorID int64 `json:"userFlavorId,omitempty"`
+	*Flavor      `json:"flavor"`
+	Created      time.Time `json:"created"`
+}
+
+// SubscriptionScope limits a UserIngredient to Flavors activated at the Stores identified by
+// StoreIDs, or at Stores within Area. The zero SubscriptionScope includes every Store.
+type SubscriptionScope struct {
+	StoreIDs []int64 `json:"storeIds,omitempty"`
+	Area     *Area   `json:"area,omitempty"`
+}
+
+// Includes reports whether the Store is within the SubscriptionScope
+func (s SubscriptionScope) Includes(store *Store) bool {
+	if len(s.StoreIDs) == 0 && s.Area == nil {
+		return true
+	}
+
+	for _, id := range s.StoreIDs {
+		if id == store.ID {
+			return true
+		}
+	}
+
+	return s.Area != nil && s.Area.Contains(store.Lat, store.Lng)
+}
+
+// EARTH_RADIUS_MILES is the mean radius of the Earth, used to find great-circle distances
+const EARTH_RADIUS_MILES float64 = 3958.8
+
+// Area is the circle of RadiusMiles around the point at Lat, Lng
+type Area struct {
+	Lat         float64 `json:"lat"`
+	Lng         float64 `json:"lng"`
+	RadiusMiles float64 `json:"radiusMiles"`
+}
+
+// Contains reports whether the point at lat, lng is within the Area
+func (a *Area) Contains(lat float64, lng float64) bool {
+	return DistanceMiles(a.Lat, a.Lng, lat, lng) <= a.RadiusMiles
+}
+
+// DistanceMiles returns the great-circle distance in miles between two points, using the
+// haversine formula.
+func DistanceMiles(lat1 float64, lng1 float64, lat2 float64, lng2 float64) float64 {
+	rad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := rad(lat2 - lat1)
+	dLng := rad(lng2 - lng1)
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(rad(lat1))*math.Cos(rad(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+
+	return 2 * EARTH_RADIUS_MILES * math.Asin(math.Sqrt(h))
 }
 
 type UserPermission struct {
@@ -113,6 +183,44 @@ type Permission struct {
 	Name string `json:"name"`
 }
 
+// UserRole grants a User the permissions of the Role at the Store identified by StoreID, or at
+// every Store if StoreID is 0.
+type UserRole struct {
+	UserRoleID int64     `json:"userRoleId,omitempty"`
+	Role       string    `json:"role"`
+	StoreID    int64     `json:"storeId,omitempty"`
+	Created    time.Time `json:"created"`
+}
+
+const (
+	// ROLE_STAFF manage the active Flavors at their Store
+	ROLE_STAFF string = "staff"
+	// ROLE_MANAGER also manage their Store's details
+	ROLE_MANAGER string = "manager"
+	// ROLE_ADMIN manage all Stores, Flavors and Users
+	ROLE_ADMIN string = "admin"
+)
+
+// Frequency is how often a User is sent the Notifications for Flavors matching their
+// subscriptions: as each Flavor is activated, or summarized in a daily or weekly digest.
+type Frequency string
+
+const (
+	FREQUENCY_IMMEDIATE Frequency = "immediate"
+	FREQUENCY_DAILY     Frequency = "daily"
+	FREQUENCY_WEEKLY    Frequency = "weekly"
+)
+
+// Valid reports whether the Frequency is one of the known frequencies
+func (f Frequency) Valid() bool {
+	switch f {
+	case FREQUENCY_IMMEDIATE, FREQUENCY_DAILY, FREQUENCY_WEEKLY:
+		return true
+	}
+
+	return false
+}
+
 type UserStatus int
 
 const (
@@ -152,21 +260,272 @@ func (status UserStatus) GetID(slug string) UserStatus {
 
 // Store is an instance of a Morelli's store
 type Store struct {
+	ID       int64     `json:"id"`
+	Name     string    `json:"name"`
+	Phone    string    `json:"phone"`
+	Email    string    `json:"email"`
+	URL      string    `json:"url"`
+	Address  string    `json:"address"`
+	City     string    `json:"city"`
+	State    string    `json:"state"`
+	Zip      string    `json:"zip"`
+	Lat      float64   `json:"lat"`
+	Lng      float64   `json:"lng"`
+	Timezone string    `json:"timezone"`
+	Created  time.Time `json:"created"`
+	Updated  time.Time `json:"-"`
+}
+
+func (s *Store) AddressString() string {
+	return fmt.Sprintf("%s %s, %s %s", s.Address, s.City, s.State, s.Zip)
+}
+
+// Notification is a Flavor activation a User is to be told about. Notifications are held as
+// pending until the User's NotificationPolicy allows them to be sent; several pending
+// Notifications are sent together as one digest message, identified by MessageRef.
+type Notification struct {
+	ID         int64     `json:"id"`
+	UserID     int64     `json:"-"`
+	Phone      string    `json:"-"`
+	StoreID    int64     `json:"storeId"`
+	StoreName  string    `json:"storeName"`
+	Timezone   string    `json:"-"`
+	FlavorID   int64     `json:"flavorId"`
+	FlavorName string    `json:"flavorName"`
+	Body       string    `json:"body"`
+	MessageRef string    `json:"-"`
+	Created    time.Time `json:"created"`
+	Sent       time.Time `json:"sent,omitempty"`
+}
+
+// NOTIFICATION_CLAIM_TIMEOUT is how long pending Notifications claimed to be sent stay claimed
+// if they're neither sent nor released, as when the process sending them exits.
+const NOTIFICATION_CLAIM_TIMEOUT = 10 * time.Minute
+
+// NotificationPolicy limits how often a User is sent Notifications. Zero values are
+// unlimited. Quiet hours are "15:04" clock times local to the Store, and may span midnight.
+type NotificationPolicy struct {
+	UserID             int64  `json:"-"`
+	MaxPerDay          int    `json:"maxPerDay"`
+	MinIntervalMinutes int    `json:"minIntervalMinutes"`
+	QuietStart         string `json:"quietStart"`
+	QuietEnd           string `json:"quietEnd"`
+}
+
+const (
+	DEFAULT_MAX_PER_DAY          int    = 3
+	DEFAULT_MIN_INTERVAL_MINUTES int    = 60
+	DEFAULT_QUIET_START          string = "21:00"
+	DEFAULT_QUIET_END            string = "09:00"
+	DEFAULT_TIMEZONE             string = "America/New_York"
+)
+
+// DefaultNotificationPolicy is the NotificationPolicy for Users who haven't saved their own.
+func DefaultNotificationPolicy(userID int64) *NotificationPolicy {
+	return &NotificationPolicy{
+		UserID:             userID,
+		MaxPerDay:          DEFAULT_MAX_PER_DAY,
+		MinIntervalMinutes: DEFAULT_MIN_INTERVAL_MINUTES,
+		QuietStart:         DEFAULT_QUIET_START,
+		QuietEnd:           DEFAULT_QUIET_END,
+	}
+}
+
+// Message is an outbound SMS held in the outbox until it has been delivered to the provider.
+// DeliveryStatus is the most recent status the provider reported for it after it was sent.
+type Message struct {
+	ID             int64          `json:"id"`
+	UserID         int64          `json:"-"`
+	Phone          string         `json:"phone"`
+	Body           string         `json:"body"`
+	Status         MessageStatus  `json:"status"`
+	Attempts       int            `json:"attempts"`
+	NextAttempt    time.Time      `json:"nextAttempt"`
+	LastError      string         `json:"lastError,omitempty"`
+	SID            string         `json:"sid,omitempty"`
+	DeliveryStatus string         `json:"deliveryStatus,omitempty"`
+	Events         []MessageEvent `json:"events,omitempty"`
+	Created        time.Time      `json:"created"`
+	Updated        time.Time      `json:"updated"`
+}
+
+// Suppression is a phone number which has opted out of receiving messages. Keyword is the
+// word the customer texted to opt out, or empty if they were suppressed by an admin.
+type Suppression struct {
 	ID      int64     `json:"id"`
-	Name    string    `json:"name"`
 	Phone   string    `json:"phone"`
-	Email   string    `json:"email"`
-	URL     string    `json:"url"`
-	Address string    `json:"address"`
-	City    string    `json:"city"`
-	State   string    `json:"state"`
-	Zip     string    `json:"zip"`
-	Lat     float64   `json:"lat"`
-	Lng     float64   `json:"lng"`
+	Keyword string    `json:"keyword"`
 	Created time.Time `json:"created"`
-	Updated time.Time `json:"-"`
 }
 
-func (s *Store) AddressString() string {
-	return fmt.Sprintf("%s %s, %s %s", s.Address, s.City, s.State, s.Zip)
+// MessageEvent is a delivery status reported by the provider for a sent Message.
+type MessageEvent struct {
+	ID        int64     `json:"id"`
+	MessageID int64     `json:"messageId"`
+	Status    string    `json:"status"`
+	ErrorCode string    `json:"errorCode,omitempty"`
+	Created   time.Time `json:"created"`
+}
+
+type MessageStatus string
+
+const (
+	// MESSAGE_STATUS_PENDING messages are waiting to be sent, or retried after a failure.
+	MESSAGE_STATUS_PENDING MessageStatus = "pending"
+	// MESSAGE_STATUS_SENDING messages have been claimed by a worker.
+	MESSAGE_STATUS_SENDING MessageStatus = "sending"
+	// MESSAGE_STATUS_SENT messages were accepted by the provider.
+	MESSAGE_STATUS_SENT MessageStatus = "sent"
+	// MESSAGE_STATUS_DEAD messages exhausted their attempts and will not be retried.
+	MESSAGE_STATUS_DEAD MessageStatus = "dead"
+)
+
+// LoginMethod is how a User attempted to log in
+type LoginMethod string
+
+const (
+	// LOGIN_METHOD_LINK logins follow the link texted to the User
+	LOGIN_METHOD_LINK LoginMethod = "link"
+	// LOGIN_METHOD_CODE logins enter the code texted to the User
+	LOGIN_METHOD_CODE LoginMethod = "code"
+	// LOGIN_METHOD_PASSWORD logins use the User's email and password
+	LOGIN_METHOD_PASSWORD LoginMethod = "password"
+)
+
+// LoginAttempt is an audit record of a successful or failed attempt to log in. UserID is 0 if
+// the attempt didn't identify a User.
+type LoginAttempt struct {
+	ID         int64       `json:"id"`
+	UserID     int64       `json:"userId,omitempty"`
+	Phone      string      `json:"phone,omitempty"`
+	Method     LoginMethod `json:"method"`
+	Success    bool        `json:"success"`
+	Reason     string      `json:"reason,omitempty"`
+	RemoteAddr string      `json:"remoteAddr"`
+	Created    time.Time   `json:"created"`
+}
+
+// DEFAULT_LOW_BARREL_LEVEL is the fill level, in percent, below which a barrel is low at Stores
+// which haven't set their own threshold.
+const DEFAULT_LOW_BARREL_LEVEL = 20
+
+// Barrel is the barrel of a Flavor active in a Position at a Store, with the fill levels
+// recorded by staff since it was activated, oldest first.
+type Barrel struct {
+	ID        int64         `json:"id"`
+	StoreID   int64         `json:"storeId"`
+	Position  int           `json:"position"`
+	Flavor    *Flavor       `json:"flavor"`
+	Activated time.Time     `json:"activated"`
+	Levels    []BarrelLevel `json:"levels,omitempty"`
+}
+
+// BarrelLevel is the fill level of a Barrel, in percent, recorded by the User identified by
+// UserID.
+type BarrelLevel struct {
+	ID       int64     `json:"id"`
+	BarrelID int64     `json:"barrelId"`
+	Level    int       `json:"level"`
+	UserID   int64     `json:"-"`
+	Created  time.Time `json:"created"`
+}
+
+// Rotation is a run of a Flavor in a Position at a Store, from when it was activated until it
+// was deactivated. Deactivated is nil while the Flavor is still active.
+type Rotation struct {
+	ID          int64      `json:"id"`
+	StoreID     int64      `json:"storeId"`
+	StoreName   string     `json:"storeName"`
+	Position    int        `json:"position"`
+	Flavor      *Flavor    `json:"flavor"`
+	Activated   time.Time  `json:"activated"`
+	Deactivated *time.Time `json:"deactivated"`
+}
+
+// Lineup is the Flavors active at a Store, ordered by the Position they occupy, and the Layout of
+// the Store's cooler.
+type Lineup struct {
+	Store   *Store          `json:"store"`
+	Layout  *Layout         `json:"layout"`
+	Flavors []*LineupFlavor `json:"flavors"`
+}
+
+// LineupFlavor is a Flavor active in a Position at a Store
+type LineupFlavor struct {
+	Position  int       `json:"position"`
+	Activated time.Time `json:"activated"`
+	Flavor    *Flavor   `json:"flavor"`
+}
+
+// LineupDiff is the change made to the Lineup of a Store: the Flavors activated and deactivated
+// in each Position, and those left where they were.
+type LineupDiff struct {
+	Activated   []*LineupFlavor `json:"activated"`
+	Deactivated []*LineupFlavor `json:"deactivated"`
+	Unchanged   []*LineupFlavor `json:"unchanged"`
+}
+
+const (
+	// DEFAULT_POSITIONS is the number of Positions in the cooler at Stores which haven't set
+	// their own Layout.
+	DEFAULT_POSITIONS = 12
+	// MAX_POSITIONS is the most Positions a Store's cooler may have
+	MAX_POSITIONS = 48
+	// MAX_SLOT_LABEL_LENGTH is the length of the longest label a Slot may have
+	MAX_SLOT_LABEL_LENGTH = 16
+)
+
+// Layout is the arrangement of the cooler at a Store: its number of Positions, and a Slot for each
+// of them, ordered by Position.
+type Layout struct {
+	StoreID   int64  `json:"storeId"`
+	Positions int    `json:"positions"`
+	Slots     []Slot `json:"slots"`
+}
+
+// Slot is a Position in a Store's cooler, with the label staff know it by, if any. Flavors can't
+// be activated in a disabled Slot.
+type Slot struct {
+	Position int    `json:"position"`
+	Label    string `json:"label,omitempty"`
+	Disabled bool   `json:"disabled"`
+}
+
+// NewLayout returns the Layout of a cooler with the number of Positions, with a Slot for each of
+// them. Positions without a Slot in `slots` are enabled and unlabeled, and Slots outside the
+// Positions are ignored.
+func NewLayout(storeID int64, positions int, slots []Slot) *Layout {
+	l := &Layout{StoreID: storeID, Positions: positions, Slots: make([]Slot, positions)}
+
+	for i := range l.Slots {
+		l.Slots[i].Position = i + 1
+	}
+
+	for _, slot := range slots {
+		if slot.Position >= 1 && slot.Position <= positions {
+			l.Slots[slot.Position-1] = slot
+		}
+	}
+
+	return l
+}
+
+// Available reports whether a Flavor can be activated in the Position
+func (l *Layout) Available(position int) bool {
+	if position < 1 || position > len(l.Slots) {
+		return false
+	}
+
+	return !l.Slots[position-1].Disabled
+}
+
+// LayoutConflictError is returned when a change to a Store's Layout or lineup would leave a
+// Flavor active in a Position which isn't available in the Store's Layout.
+type LayoutConflictError struct {
+	StoreID  int64
+	Position int
+}
+
+func (e *LayoutConflictError) Error() string {
+	return fmt.Sprintf("models: Position %d isn't available in the Layout of Store %d", e.Position, e.StoreID)
 }
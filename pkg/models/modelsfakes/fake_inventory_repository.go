@@ -0,0 +1,435 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package modelsfakes
+
+import (
+	"sync"
+
+	"github.com/jcorry/morellis/pkg/models"
+)
+
+type FakeInventoryRepository struct {
+	GetBarrelStub        func(int64, int) (*models.Barrel, error)
+	getBarrelMutex       sync.RWMutex
+	getBarrelArgsForCall []struct {
+		arg1 int64
+		arg2 int
+	}
+	getBarrelReturns struct {
+		result1 *models.Barrel
+		result2 error
+	}
+	getBarrelReturnsOnCall map[int]struct {
+		result1 *models.Barrel
+		result2 error
+	}
+	GetLowLevelStub        func(int64) (int, error)
+	getLowLevelMutex       sync.RWMutex
+	getLowLevelArgsForCall []struct {
+		arg1 int64
+	}
+	getLowLevelReturns struct {
+		result1 int
+		result2 error
+	}
+	getLowLevelReturnsOnCall map[int]struct {
+		result1 int
+		result2 error
+	}
+	ListBarrelsStub        func(int64) ([]*models.Barrel, error)
+	listBarrelsMutex       sync.RWMutex
+	listBarrelsArgsForCall []struct {
+		arg1 int64
+	}
+	listBarrelsReturns struct {
+		result1 []*models.Barrel
+		result2 error
+	}
+	listBarrelsReturnsOnCall map[int]struct {
+		result1 []*models.Barrel
+		result2 error
+	}
+	RecordLevelStub        func(int64, int, int64) (*models.BarrelLevel, error)
+	recordLevelMutex       sync.RWMutex
+	recordLevelArgsForCall []struct {
+		arg1 int64
+		arg2 int
+		arg3 int64
+	}
+	recordLevelReturns struct {
+		result1 *models.BarrelLevel
+		result2 error
+	}
+	recordLevelReturnsOnCall map[int]struct {
+		result1 *models.BarrelLevel
+		result2 error
+	}
+	SetLowLevelStub        func(int64, int) error
+	setLowLevelMutex       sync.RWMutex
+	setLowLevelArgsForCall []struct {
+		arg1 int64
+		arg2 int
+	}
+	setLowLevelReturns struct {
+		result1 error
+	}
+	setLowLevelReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeInventoryRepository) GetBarrel(arg1 int64, arg2 int) (*models.Barrel, error) {
+	fake.getBarrelMutex.Lock()
+	ret, specificReturn := fake.getBarrelReturnsOnCall[len(fake.getBarrelArgsForCall)]
+	fake.getBarrelArgsForCall = append(fake.getBarrelArgsForCall, struct {
+		arg1 int64
+		arg2 int
+	}{arg1, arg2})
+	stub := fake.GetBarrelStub
+	fakeReturns := fake.getBarrelReturns
+	fake.recordInvocation("GetBarrel", []interface{}{arg1, arg2})
+	fake.getBarrelMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeInventoryRepository) GetBarrelCallCount() int {
+	fake.getBarrelMutex.RLock()
+	defer fake.getBarrelMutex.RUnlock()
+	return len(fake.getBarrelArgsForCall)
+}
+
+func (fake *FakeInventoryRepository) GetBarrelCalls(stub func(int64, int) (*models.Barrel, error)) {
+	fake.getBarrelMutex.Lock()
+	defer fake.getBarrelMutex.Unlock()
+	fake.GetBarrelStub = stub
+}
+
+func (fake *FakeInventoryRepository) GetBarrelArgsForCall(i int) (int64, int) {
+	fake.getBarrelMutex.RLock()
+	defer fake.getBarrelMutex.RUnlock()
+	argsForCall := fake.getBarrelArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeInventoryRepository) GetBarrelReturns(result1 *models.Barrel, result2 error) {
+	fake.getBarrelMutex.Lock()
+	defer fake.getBarrelMutex.Unlock()
+	fake.GetBarrelStub = nil
+	fake.getBarrelReturns = struct {
+		result1 *models.Barrel
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeInventoryRepository) GetBarrelReturnsOnCall(i int, result1 *models.Barrel, result2 error) {
+	fake.getBarrelMutex.Lock()
+	defer fake.getBarrelMutex.Unlock()
+	fake.GetBarrelStub = nil
+	if fake.getBarrelReturnsOnCall == nil {
+		fake.getBarrelReturnsOnCall = make(map[int]struct {
+			result1 *models.Barrel
+			result2 error
+		})
+	}
+	fake.getBarrelReturnsOnCall[i] = struct {
+		result1 *models.Barrel
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeInventoryRepository) GetLowLevel(arg1 int64) (int, error) {
+	fake.getLowLevelMutex.Lock()
+	ret, specificReturn := fake.getLowLevelReturnsOnCall[len(fake.getLowLevelArgsForCall)]
+	fake.getLowLevelArgsForCall = append(fake.getLowLevelArgsForCall, struct {
+		arg1 int64
+	}{arg1})
+	stub := fake.GetLowLevelStub
+	fakeReturns := fake.getLowLevelReturns
+	fake.recordInvocation("GetLowLevel", []interface{}{arg1})
+	fake.getLowLevelMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeInventoryRepository) GetLowLevelCallCount() int {
+	fake.getLowLevelMutex.RLock()
+	defer fake.getLowLevelMutex.RUnlock()
+	return len(fake.getLowLevelArgsForCall)
+}
+
+func (fake *FakeInventoryRepository) GetLowLevelCalls(stub func(int64) (int, error)) {
+	fake.getLowLevelMutex.Lock()
+	defer fake.getLowLevelMutex.Unlock()
+	fake.GetLowLevelStub = stub
+}
+
+func (fake *FakeInventoryRepository) GetLowLevelArgsForCall(i int) int64 {
+	fake.getLowLevelMutex.RLock()
+	defer fake.getLowLevelMutex.RUnlock()
+	argsForCall := fake.getLowLevelArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeInventoryRepository) GetLowLevelReturns(result1 int, result2 error) {
+	fake.getLowLevelMutex.Lock()
+	defer fake.getLowLevelMutex.Unlock()
+	fake.GetLowLevelStub = nil
+	fake.getLowLevelReturns = struct {
+		result1 int
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeInventoryRepository) GetLowLevelReturnsOnCall(i int, result1 int, result2 error) {
+	fake.getLowLevelMutex.Lock()
+	defer fake.getLowLevelMutex.Unlock()
+	fake.GetLowLevelStub = nil
+	if fake.getLowLevelReturnsOnCall == nil {
+		fake.getLowLevelReturnsOnCall = make(map[int]struct {
+			result1 int
+			result2 error
+		})
+	}
+	fake.getLowLevelReturnsOnCall[i] = struct {
+		result1 int
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeInventoryRepository) ListBarrels(arg1 int64) ([]*models.Barrel, error) {
+	fake.listBarrelsMutex.Lock()
+	ret, specificReturn := fake.listBarrelsReturnsOnCall[len(fake.listBarrelsArgsForCall)]
+	fake.listBarrelsArgsForCall = append(fake.listBarrelsArgsForCall, struct {
+		arg1 int64
+	}{arg1})
+	stub := fake.ListBarrelsStub
+	fakeReturns := fake.listBarrelsReturns
+	fake.recordInvocation("ListBarrels", []interface{}{arg1})
+	fake.listBarrelsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeInventoryRepository) ListBarrelsCallCount() int {
+	fake.listBarrelsMutex.RLock()
+	defer fake.listBarrelsMutex.RUnlock()
+	return len(fake.listBarrelsArgsForCall)
+}
+
+func (fake *FakeInventoryRepository) ListBarrelsCalls(stub func(int64) ([]*models.Barrel, error)) {
+	fake.listBarrelsMutex.Lock()
+	defer fake.listBarrelsMutex.Unlock()
+	fake.ListBarrelsStub = stub
+}
+
+func (fake *FakeInventoryRepository) ListBarrelsArgsForCall(i int) int64 {
+	fake.listBarrelsMutex.RLock()
+	defer fake.listBarrelsMutex.RUnlock()
+	argsForCall := fake.listBarrelsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeInventoryRepository) ListBarrelsReturns(result1 []*models.Barrel, result2 error) {
+	fake.listBarrelsMutex.Lock()
+	defer fake.listBarrelsMutex.Unlock()
+	fake.ListBarrelsStub = nil
+	fake.listBarrelsReturns = struct {
+		result1 []*models.Barrel
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeInventoryRepository) ListBarrelsReturnsOnCall(i int, result1 []*models.Barrel, result2 error) {
+	fake.listBarrelsMutex.Lock()
+	defer fake.listBarrelsMutex.Unlock()
+	fake.ListBarrelsStub = nil
+	if fake.listBarrelsReturnsOnCall == nil {
+		fake.listBarrelsReturnsOnCall = make(map[int]struct {
+			result1 []*models.Barrel
+			result2 error
+		})
+	}
+	fake.listBarrelsReturnsOnCall[i] = struct {
+		result1 []*models.Barrel
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeInventoryRepository) RecordLevel(arg1 int64, arg2 int, arg3 int64) (*models.BarrelLevel, error) {
+	fake.recordLevelMutex.Lock()
+	ret, specificReturn := fake.recordLevelReturnsOnCall[len(fake.recordLevelArgsForCall)]
+	fake.recordLevelArgsForCall = append(fake.recordLevelArgsForCall, struct {
+		arg1 int64
+		arg2 int
+		arg3 int64
+	}{arg1, arg2, arg3})
+	stub := fake.RecordLevelStub
+	fakeReturns := fake.recordLevelReturns
+	fake.recordInvocation("RecordLevel", []interface{}{arg1, arg2, arg3})
+	fake.recordLevelMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeInventoryRepository) RecordLevelCallCount() int {
+	fake.recordLevelMutex.RLock()
+	defer fake.recordLevelMutex.RUnlock()
+	return len(fake.recordLevelArgsForCall)
+}
+
+func (fake *FakeInventoryRepository) RecordLevelCalls(stub func(int64, int, int64) (*models.BarrelLevel, error)) {
+	fake.recordLevelMutex.Lock()
+	defer fake.recordLevelMutex.Unlock()
+	fake.RecordLevelStub = stub
+}
+
+func (fake *FakeInventoryRepository) RecordLevelArgsForCall(i int) (int64, int, int64) {
+	fake.recordLevelMutex.RLock()
+	defer fake.recordLevelMutex.RUnlock()
+	argsForCall := fake.recordLevelArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeInventoryRepository) RecordLevelReturns(result1 *models.BarrelLevel, result2 error) {
+	fake.recordLevelMutex.Lock()
+	defer fake.recordLevelMutex.Unlock()
+	fake.RecordLevelStub = nil
+	fake.recordLevelReturns = struct {
+		result1 *models.BarrelLevel
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeInventoryRepository) RecordLevelReturnsOnCall(i int, result1 *models.BarrelLevel, result2 error) {
+	fake.recordLevelMutex.Lock()
+	defer fake.recordLevelMutex.Unlock()
+	fake.RecordLevelStub = nil
+	if fake.recordLevelReturnsOnCall == nil {
+		fake.recordLevelReturnsOnCall = make(map[int]struct {
+			result1 *models.BarrelLevel
+			result2 error
+		})
+	}
+	fake.recordLevelReturnsOnCall[i] = struct {
+		result1 *models.BarrelLevel
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeInventoryRepository) SetLowLevel(arg1 int64, arg2 int) error {
+	fake.setLowLevelMutex.Lock()
+	ret, specificReturn := fake.setLowLevelReturnsOnCall[len(fake.setLowLevelArgsForCall)]
+	fake.setLowLevelArgsForCall = append(fake.setLowLevelArgsForCall, struct {
+		arg1 int64
+		arg2 int
+	}{arg1, arg2})
+	stub := fake.SetLowLevelStub
+	fakeReturns := fake.setLowLevelReturns
+	fake.recordInvocation("SetLowLevel", []interface{}{arg1, arg2})
+	fake.setLowLevelMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeInventoryRepository) SetLowLevelCallCount() int {
+	fake.setLowLevelMutex.RLock()
+	defer fake.setLowLevelMutex.RUnlock()
+	return len(fake.setLowLevelArgsForCall)
+}
+
+func (fake *FakeInventoryRepository) SetLowLevelCalls(stub func(int64, int) error) {
+	fake.setLowLevelMutex.Lock()
+	defer fake.setLowLevelMutex.Unlock()
+	fake.SetLowLevelStub = stub
+}
+
+func (fake *FakeInventoryRepository) SetLowLevelArgsForCall(i int) (int64, int) {
+	fake.setLowLevelMutex.RLock()
+	defer fake.setLowLevelMutex.RUnlock()
+	argsForCall := fake.setLowLevelArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeInventoryRepository) SetLowLevelReturns(result1 error) {
+	fake.setLowLevelMutex.Lock()
+	defer fake.setLowLevelMutex.Unlock()
+	fake.SetLowLevelStub = nil
+	fake.setLowLevelReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeInventoryRepository) SetLowLevelReturnsOnCall(i int, result1 error) {
+	fake.setLowLevelMutex.Lock()
+	defer fake.setLowLevelMutex.Unlock()
+	fake.SetLowLevelStub = nil
+	if fake.setLowLevelReturnsOnCall == nil {
+		fake.setLowLevelReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.setLowLevelReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeInventoryRepository) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.getBarrelMutex.RLock()
+	defer fake.getBarrelMutex.RUnlock()
+	fake.getLowLevelMutex.RLock()
+	defer fake.getLowLevelMutex.RUnlock()
+	fake.listBarrelsMutex.RLock()
+	defer fake.listBarrelsMutex.RUnlock()
+	fake.recordLevelMutex.RLock()
+	defer fake.recordLevelMutex.RUnlock()
+	fake.setLowLevelMutex.RLock()
+	defer fake.setLowLevelMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeInventoryRepository) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ models.InventoryRepository = new(FakeInventoryRepository)
@@ -0,0 +1,116 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package modelsfakes
+
+import (
+	"sync"
+
+	"github.com/jcorry/morellis/pkg/models"
+)
+
+type FakeLoginAttemptRepository struct {
+	InsertStub        func(*models.LoginAttempt) (*models.LoginAttempt, error)
+	insertMutex       sync.RWMutex
+	insertArgsForCall []struct {
+		arg1 *models.LoginAttempt
+	}
+	insertReturns struct {
+		result1 *models.LoginAttempt
+		result2 error
+	}
+	insertReturnsOnCall map[int]struct {
+		result1 *models.LoginAttempt
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeLoginAttemptRepository) Insert(arg1 *models.LoginAttempt) (*models.LoginAttempt, error) {
+	fake.insertMutex.Lock()
+	ret, specificReturn := fake.insertReturnsOnCall[len(fake.insertArgsForCall)]
+	fake.insertArgsForCall = append(fake.insertArgsForCall, struct {
+		arg1 *models.LoginAttempt
+	}{arg1})
+	stub := fake.InsertStub
+	fakeReturns := fake.insertReturns
+	fake.recordInvocation("Insert", []interface{}{arg1})
+	fake.insertMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeLoginAttemptRepository) InsertCallCount() int {
+	fake.insertMutex.RLock()
+	defer fake.insertMutex.RUnlock()
+	return len(fake.insertArgsForCall)
+}
+
+func (fake *FakeLoginAttemptRepository) InsertCalls(stub func(*models.LoginAttempt) (*models.LoginAttempt, error)) {
+	fake.insertMutex.Lock()
+	defer fake.insertMutex.Unlock()
+	fake.InsertStub = stub
+}
+
+func (fake *FakeLoginAttemptRepository) InsertArgsForCall(i int) *models.LoginAttempt {
+	fake.insertMutex.RLock()
+	defer fake.insertMutex.RUnlock()
+	argsForCall := fake.insertArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeLoginAttemptRepository) InsertReturns(result1 *models.LoginAttempt, result2 error) {
+	fake.insertMutex.Lock()
+	defer fake.insertMutex.Unlock()
+	fake.InsertStub = nil
+	fake.insertReturns = struct {
+		result1 *models.LoginAttempt
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeLoginAttemptRepository) InsertReturnsOnCall(i int, result1 *models.LoginAttempt, result2 error) {
+	fake.insertMutex.Lock()
+	defer fake.insertMutex.Unlock()
+	fake.InsertStub = nil
+	if fake.insertReturnsOnCall == nil {
+		fake.insertReturnsOnCall = make(map[int]struct {
+			result1 *models.LoginAttempt
+			result2 error
+		})
+	}
+	fake.insertReturnsOnCall[i] = struct {
+		result1 *models.LoginAttempt
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeLoginAttemptRepository) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.insertMutex.RLock()
+	defer fake.insertMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeLoginAttemptRepository) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ models.LoginAttemptRepository = new(FakeLoginAttemptRepository)
@@ -0,0 +1,828 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package modelsfakes
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jcorry/morellis/pkg/models"
+)
+
+type FakeMessageRepository struct {
+	AddEventStub        func(int64, string, string) (*models.MessageEvent, error)
+	addEventMutex       sync.RWMutex
+	addEventArgsForCall []struct {
+		arg1 int64
+		arg2 string
+		arg3 string
+	}
+	addEventReturns struct {
+		result1 *models.MessageEvent
+		result2 error
+	}
+	addEventReturnsOnCall map[int]struct {
+		result1 *models.MessageEvent
+		result2 error
+	}
+	ClaimStub        func(int, time.Duration) ([]*models.Message, error)
+	claimMutex       sync.RWMutex
+	claimArgsForCall []struct {
+		arg1 int
+		arg2 time.Duration
+	}
+	claimReturns struct {
+		result1 []*models.Message
+		result2 error
+	}
+	claimReturnsOnCall map[int]struct {
+		result1 []*models.Message
+		result2 error
+	}
+	CountByUserStub        func(int64) int
+	countByUserMutex       sync.RWMutex
+	countByUserArgsForCall []struct {
+		arg1 int64
+	}
+	countByUserReturns struct {
+		result1 int
+	}
+	countByUserReturnsOnCall map[int]struct {
+		result1 int
+	}
+	GetStub        func(int64) (*models.Message, error)
+	getMutex       sync.RWMutex
+	getArgsForCall []struct {
+		arg1 int64
+	}
+	getReturns struct {
+		result1 *models.Message
+		result2 error
+	}
+	getReturnsOnCall map[int]struct {
+		result1 *models.Message
+		result2 error
+	}
+	GetBySIDStub        func(string) (*models.Message, error)
+	getBySIDMutex       sync.RWMutex
+	getBySIDArgsForCall []struct {
+		arg1 string
+	}
+	getBySIDReturns struct {
+		result1 *models.Message
+		result2 error
+	}
+	getBySIDReturnsOnCall map[int]struct {
+		result1 *models.Message
+		result2 error
+	}
+	InsertStub        func(string, string) (*models.Message, error)
+	insertMutex       sync.RWMutex
+	insertArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	insertReturns struct {
+		result1 *models.Message
+		result2 error
+	}
+	insertReturnsOnCall map[int]struct {
+		result1 *models.Message
+		result2 error
+	}
+	ListByUserStub        func(int64, int, int) ([]*models.Message, error)
+	listByUserMutex       sync.RWMutex
+	listByUserArgsForCall []struct {
+		arg1 int64
+		arg2 int
+		arg3 int
+	}
+	listByUserReturns struct {
+		result1 []*models.Message
+		result2 error
+	}
+	listByUserReturnsOnCall map[int]struct {
+		result1 []*models.Message
+		result2 error
+	}
+	MarkDeadStub        func(int64, string) error
+	markDeadMutex       sync.RWMutex
+	markDeadArgsForCall []struct {
+		arg1 int64
+		arg2 string
+	}
+	markDeadReturns struct {
+		result1 error
+	}
+	markDeadReturnsOnCall map[int]struct {
+		result1 error
+	}
+	MarkFailedStub        func(int64, string, time.Time) error
+	markFailedMutex       sync.RWMutex
+	markFailedArgsForCall []struct {
+		arg1 int64
+		arg2 string
+		arg3 time.Time
+	}
+	markFailedReturns struct {
+		result1 error
+	}
+	markFailedReturnsOnCall map[int]struct {
+		result1 error
+	}
+	MarkSentStub        func(int64, string) error
+	markSentMutex       sync.RWMutex
+	markSentArgsForCall []struct {
+		arg1 int64
+		arg2 string
+	}
+	markSentReturns struct {
+		result1 error
+	}
+	markSentReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeMessageRepository) AddEvent(arg1 int64, arg2 string, arg3 string) (*models.MessageEvent, error) {
+	fake.addEventMutex.Lock()
+	ret, specificReturn := fake.addEventReturnsOnCall[len(fake.addEventArgsForCall)]
+	fake.addEventArgsForCall = append(fake.addEventArgsForCall, struct {
+		arg1 int64
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.AddEventStub
+	fakeReturns := fake.addEventReturns
+	fake.recordInvocation("AddEvent", []interface{}{arg1, arg2, arg3})
+	fake.addEventMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeMessageRepository) AddEventCallCount() int {
+	fake.addEventMutex.RLock()
+	defer fake.addEventMutex.RUnlock()
+	return len(fake.addEventArgsForCall)
+}
+
+func (fake *FakeMessageRepository) AddEventCalls(stub func(int64, string, string) (*models.MessageEvent, error)) {
+	fake.addEventMutex.Lock()
+	defer fake.addEventMutex.Unlock()
+	fake.AddEventStub = stub
+}
+
+func (fake *FakeMessageRepository) AddEventArgsForCall(i int) (int64, string, string) {
+	fake.addEventMutex.RLock()
+	defer fake.addEventMutex.RUnlock()
+	argsForCall := fake.addEventArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeMessageRepository) AddEventReturns(result1 *models.MessageEvent, result2 error) {
+	fake.addEventMutex.Lock()
+	defer fake.addEventMutex.Unlock()
+	fake.AddEventStub = nil
+	fake.addEventReturns = struct {
+		result1 *models.MessageEvent
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeMessageRepository) AddEventReturnsOnCall(i int, result1 *models.MessageEvent, result2 error) {
+	fake.addEventMutex.Lock()
+	defer fake.addEventMutex.Unlock()
+	fake.AddEventStub = nil
+	if fake.addEventReturnsOnCall == nil {
+		fake.addEventReturnsOnCall = make(map[int]struct {
+			result1 *models.MessageEvent
+			result2 error
+		})
+	}
+	fake.addEventReturnsOnCall[i] = struct {
+		result1 *models.MessageEvent
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeMessageRepository) Claim(arg1 int, arg2 time.Duration) ([]*models.Message, error) {
+	fake.claimMutex.Lock()
+	ret, specificReturn := fake.claimReturnsOnCall[len(fake.claimArgsForCall)]
+	fake.claimArgsForCall = append(fake.claimArgsForCall, struct {
+		arg1 int
+		arg2 time.Duration
+	}{arg1, arg2})
+	stub := fake.ClaimStub
+	fakeReturns := fake.claimReturns
+	fake.recordInvocation("Claim", []interface{}{arg1, arg2})
+	fake.claimMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeMessageRepository) ClaimCallCount() int {
+	fake.claimMutex.RLock()
+	defer fake.claimMutex.RUnlock()
+	return len(fake.claimArgsForCall)
+}
+
+func (fake *FakeMessageRepository) ClaimCalls(stub func(int, time.Duration) ([]*models.Message, error)) {
+	fake.claimMutex.Lock()
+	defer fake.claimMutex.Unlock()
+	fake.ClaimStub = stub
+}
+
+func (fake *FakeMessageRepository) ClaimArgsForCall(i int) (int, time.Duration) {
+	fake.claimMutex.RLock()
+	defer fake.claimMutex.RUnlock()
+	argsForCall := fake.claimArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeMessageRepository) ClaimReturns(result1 []*models.Message, result2 error) {
+	fake.claimMutex.Lock()
+	defer fake.claimMutex.Unlock()
+	fake.ClaimStub = nil
+	fake.claimReturns = struct {
+		result1 []*models.Message
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeMessageRepository) ClaimReturnsOnCall(i int, result1 []*models.Message, result2 error) {
+	fake.claimMutex.Lock()
+	defer fake.claimMutex.Unlock()
+	fake.ClaimStub = nil
+	if fake.claimReturnsOnCall == nil {
+		fake.claimReturnsOnCall = make(map[int]struct {
+			result1 []*models.Message
+			result2 error
+		})
+	}
+	fake.claimReturnsOnCall[i] = struct {
+		result1 []*models.Message
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeMessageRepository) CountByUser(arg1 int64) int {
+	fake.countByUserMutex.Lock()
+	ret, specificReturn := fake.countByUserReturnsOnCall[len(fake.countByUserArgsForCall)]
+	fake.countByUserArgsForCall = append(fake.countByUserArgsForCall, struct {
+		arg1 int64
+	}{arg1})
+	stub := fake.CountByUserStub
+	fakeReturns := fake.countByUserReturns
+	fake.recordInvocation("CountByUser", []interface{}{arg1})
+	fake.countByUserMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeMessageRepository) CountByUserCallCount() int {
+	fake.countByUserMutex.RLock()
+	defer fake.countByUserMutex.RUnlock()
+	return len(fake.countByUserArgsForCall)
+}
+
+func (fake *FakeMessageRepository) CountByUserCalls(stub func(int64) int) {
+	fake.countByUserMutex.Lock()
+	defer fake.countByUserMutex.Unlock()
+	fake.CountByUserStub = stub
+}
+
+func (fake *FakeMessageRepository) CountByUserArgsForCall(i int) int64 {
+	fake.countByUserMutex.RLock()
+	defer fake.countByUserMutex.RUnlock()
+	argsForCall := fake.countByUserArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeMessageRepository) CountByUserReturns(result1 int) {
+	fake.countByUserMutex.Lock()
+	defer fake.countByUserMutex.Unlock()
+	fake.CountByUserStub = nil
+	fake.countByUserReturns = struct {
+		result1 int
+	}{result1}
+}
+
+func (fake *FakeMessageRepository) CountByUserReturnsOnCall(i int, result1 int) {
+	fake.countByUserMutex.Lock()
+	defer fake.countByUserMutex.Unlock()
+	fake.CountByUserStub = nil
+	if fake.countByUserReturnsOnCall == nil {
+		fake.countByUserReturnsOnCall = make(map[int]struct {
+			result1 int
+		})
+	}
+	fake.countByUserReturnsOnCall[i] = struct {
+		result1 int
+	}{result1}
+}
+
+func (fake *FakeMessageRepository) Get(arg1 int64) (*models.Message, error) {
+	fake.getMutex.Lock()
+	ret, specificReturn := fake.getReturnsOnCall[len(fake.getArgsForCall)]
+	fake.getArgsForCall = append(fake.getArgsForCall, struct {
+		arg1 int64
+	}{arg1})
+	stub := fake.GetStub
+	fakeReturns := fake.getReturns
+	fake.recordInvocation("Get", []interface{}{arg1})
+	fake.getMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeMessageRepository) GetCallCount() int {
+	fake.getMutex.RLock()
+	defer fake.getMutex.RUnlock()
+	return len(fake.getArgsForCall)
+}
+
+func (fake *FakeMessageRepository) GetCalls(stub func(int64) (*models.Message, error)) {
+	fake.getMutex.Lock()
+	defer fake.getMutex.Unlock()
+	fake.GetStub = stub
+}
+
+func (fake *FakeMessageRepository) GetArgsForCall(i int) int64 {
+	fake.getMutex.RLock()
+	defer fake.getMutex.RUnlock()
+	argsForCall := fake.getArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeMessageRepository) GetReturns(result1 *models.Message, result2 error) {
+	fake.getMutex.Lock()
+	defer fake.getMutex.Unlock()
+	fake.GetStub = nil
+	fake.getReturns = struct {
+		result1 *models.Message
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeMessageRepository) GetReturnsOnCall(i int, result1 *models.Message, result2 error) {
+	fake.getMutex.Lock()
+	defer fake.getMutex.Unlock()
+	fake.GetStub = nil
+	if fake.getReturnsOnCall == nil {
+		fake.getReturnsOnCall = make(map[int]struct {
+			result1 *models.Message
+			result2 error
+		})
+	}
+	fake.getReturnsOnCall[i] = struct {
+		result1 *models.Message
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeMessageRepository) GetBySID(arg1 string) (*models.Message, error) {
+	fake.getBySIDMutex.Lock()
+	ret, specificReturn := fake.getBySIDReturnsOnCall[len(fake.getBySIDArgsForCall)]
+	fake.getBySIDArgsForCall = append(fake.getBySIDArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.GetBySIDStub
+	fakeReturns := fake.getBySIDReturns
+	fake.recordInvocation("GetBySID", []interface{}{arg1})
+	fake.getBySIDMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeMessageRepository) GetBySIDCallCount() int {
+	fake.getBySIDMutex.RLock()
+	defer fake.getBySIDMutex.RUnlock()
+	return len(fake.getBySIDArgsForCall)
+}
+
+func (fake *FakeMessageRepository) GetBySIDCalls(stub func(string) (*models.Message, error)) {
+	fake.getBySIDMutex.Lock()
+	defer fake.getBySIDMutex.Unlock()
+	fake.GetBySIDStub = stub
+}
+
+func (fake *FakeMessageRepository) GetBySIDArgsForCall(i int) string {
+	fake.getBySIDMutex.RLock()
+	defer fake.getBySIDMutex.RUnlock()
+	argsForCall := fake.getBySIDArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeMessageRepository) GetBySIDReturns(result1 *models.Message, result2 error) {
+	fake.getBySIDMutex.Lock()
+	defer fake.getBySIDMutex.Unlock()
+	fake.GetBySIDStub = nil
+	fake.getBySIDReturns = struct {
+		result1 *models.Message
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeMessageRepository) GetBySIDReturnsOnCall(i int, result1 *models.Message, result2 error) {
+	fake.getBySIDMutex.Lock()
+	defer fake.getBySIDMutex.Unlock()
+	fake.GetBySIDStub = nil
+	if fake.getBySIDReturnsOnCall == nil {
+		fake.getBySIDReturnsOnCall = make(map[int]struct {
+			result1 *models.Message
+			result2 error
+		})
+	}
+	fake.getBySIDReturnsOnCall[i] = struct {
+		result1 *models.Message
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeMessageRepository) Insert(arg1 string, arg2 string) (*models.Message, error) {
+	fake.insertMutex.Lock()
+	ret, specificReturn := fake.insertReturnsOnCall[len(fake.insertArgsForCall)]
+	fake.insertArgsForCall = append(fake.insertArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.InsertStub
+	fakeReturns := fake.insertReturns
+	fake.recordInvocation("Insert", []interface{}{arg1, arg2})
+	fake.insertMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeMessageRepository) InsertCallCount() int {
+	fake.insertMutex.RLock()
+	defer fake.insertMutex.RUnlock()
+	return len(fake.insertArgsForCall)
+}
+
+func (fake *FakeMessageRepository) InsertCalls(stub func(string, string) (*models.Message, error)) {
+	fake.insertMutex.Lock()
+	defer fake.insertMutex.Unlock()
+	fake.InsertStub = stub
+}
+
+func (fake *FakeMessageRepository) InsertArgsForCall(i int) (string, string) {
+	fake.insertMutex.RLock()
+	defer fake.insertMutex.RUnlock()
+	argsForCall := fake.insertArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeMessageRepository) InsertReturns(result1 *models.Message, result2 error) {
+	fake.insertMutex.Lock()
+	defer fake.insertMutex.Unlock()
+	fake.InsertStub = nil
+	fake.insertReturns = struct {
+		result1 *models.Message
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeMessageRepository) InsertReturnsOnCall(i int, result1 *models.Message, result2 error) {
+	fake.insertMutex.Lock()
+	defer fake.insertMutex.Unlock()
+	fake.InsertStub = nil
+	if fake.insertReturnsOnCall == nil {
+		fake.insertReturnsOnCall = make(map[int]struct {
+			result1 *models.Message
+			result2 error
+		})
+	}
+	fake.insertReturnsOnCall[i] = struct {
+		result1 *models.Message
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeMessageRepository) ListByUser(arg1 int64, arg2 int, arg3 int) ([]*models.Message, error) {
+	fake.listByUserMutex.Lock()
+	ret, specificReturn := fake.listByUserReturnsOnCall[len(fake.listByUserArgsForCall)]
+	fake.listByUserArgsForCall = append(fake.listByUserArgsForCall, struct {
+		arg1 int64
+		arg2 int
+		arg3 int
+	}{arg1, arg2, arg3})
+	stub := fake.ListByUserStub
+	fakeReturns := fake.listByUserReturns
+	fake.recordInvocation("ListByUser", []interface{}{arg1, arg2, arg3})
+	fake.listByUserMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeMessageRepository) ListByUserCallCount() int {
+	fake.listByUserMutex.RLock()
+	defer fake.listByUserMutex.RUnlock()
+	return len(fake.listByUserArgsForCall)
+}
+
+func (fake *FakeMessageRepository) ListByUserCalls(stub func(int64, int, int) ([]*models.Message, error)) {
+	fake.listByUserMutex.Lock()
+	defer fake.listByUserMutex.Unlock()
+	fake.ListByUserStub = stub
+}
+
+func (fake *FakeMessageRepository) ListByUserArgsForCall(i int) (int64, int, int) {
+	fake.listByUserMutex.RLock()
+	defer fake.listByUserMutex.RUnlock()
+	argsForCall := fake.listByUserArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeMessageRepository) ListByUserReturns(result1 []*models.Message, result2 error) {
+	fake.listByUserMutex.Lock()
+	defer fake.listByUserMutex.Unlock()
+	fake.ListByUserStub = nil
+	fake.listByUserReturns = struct {
+		result1 []*models.Message
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeMessageRepository) ListByUserReturnsOnCall(i int, result1 []*models.Message, result2 error) {
+	fake.listByUserMutex.Lock()
+	defer fake.listByUserMutex.Unlock()
+	fake.ListByUserStub = nil
+	if fake.listByUserReturnsOnCall == nil {
+		fake.listByUserReturnsOnCall = make(map[int]struct {
+			result1 []*models.Message
+			result2 error
+		})
+	}
+	fake.listByUserReturnsOnCall[i] = struct {
+		result1 []*models.Message
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeMessageRepository) MarkDead(arg1 int64, arg2 string) error {
+	fake.markDeadMutex.Lock()
+	ret, specificReturn := fake.markDeadReturnsOnCall[len(fake.markDeadArgsForCall)]
+	fake.markDeadArgsForCall = append(fake.markDeadArgsForCall, struct {
+		arg1 int64
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.MarkDeadStub
+	fakeReturns := fake.markDeadReturns
+	fake.recordInvocation("MarkDead", []interface{}{arg1, arg2})
+	fake.markDeadMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeMessageRepository) MarkDeadCallCount() int {
+	fake.markDeadMutex.RLock()
+	defer fake.markDeadMutex.RUnlock()
+	return len(fake.markDeadArgsForCall)
+}
+
+func (fake *FakeMessageRepository) MarkDeadCalls(stub func(int64, string) error) {
+	fake.markDeadMutex.Lock()
+	defer fake.markDeadMutex.Unlock()
+	fake.MarkDeadStub = stub
+}
+
+func (fake *FakeMessageRepository) MarkDeadArgsForCall(i int) (int64, string) {
+	fake.markDeadMutex.RLock()
+	defer fake.markDeadMutex.RUnlock()
+	argsForCall := fake.markDeadArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeMessageRepository) MarkDeadReturns(result1 error) {
+	fake.markDeadMutex.Lock()
+	defer fake.markDeadMutex.Unlock()
+	fake.MarkDeadStub = nil
+	fake.markDeadReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeMessageRepository) MarkDeadReturnsOnCall(i int, result1 error) {
+	fake.markDeadMutex.Lock()
+	defer fake.markDeadMutex.Unlock()
+	fake.MarkDeadStub = nil
+	if fake.markDeadReturnsOnCall == nil {
+		fake.markDeadReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.markDeadReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeMessageRepository) MarkFailed(arg1 int64, arg2 string, arg3 time.Time) error {
+	fake.markFailedMutex.Lock()
+	ret, specificReturn := fake.markFailedReturnsOnCall[len(fake.markFailedArgsForCall)]
+	fake.markFailedArgsForCall = append(fake.markFailedArgsForCall, struct {
+		arg1 int64
+		arg2 string
+		arg3 time.Time
+	}{arg1, arg2, arg3})
+	stub := fake.MarkFailedStub
+	fakeReturns := fake.markFailedReturns
+	fake.recordInvocation("MarkFailed", []interface{}{arg1, arg2, arg3})
+	fake.markFailedMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeMessageRepository) MarkFailedCallCount() int {
+	fake.markFailedMutex.RLock()
+	defer fake.markFailedMutex.RUnlock()
+	return len(fake.markFailedArgsForCall)
+}
+
+func (fake *FakeMessageRepository) MarkFailedCalls(stub func(int64, string, time.Time) error) {
+	fake.markFailedMutex.Lock()
+	defer fake.markFailedMutex.Unlock()
+	fake.MarkFailedStub = stub
+}
+
+func (fake *FakeMessageRepository) MarkFailedArgsForCall(i int) (int64, string, time.Time) {
+	fake.markFailedMutex.RLock()
+	defer fake.markFailedMutex.RUnlock()
+	argsForCall := fake.markFailedArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeMessageRepository) MarkFailedReturns(result1 error) {
+	fake.markFailedMutex.Lock()
+	defer fake.markFailedMutex.Unlock()
+	fake.MarkFailedStub = nil
+	fake.markFailedReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeMessageRepository) MarkFailedReturnsOnCall(i int, result1 error) {
+	fake.markFailedMutex.Lock()
+	defer fake.markFailedMutex.Unlock()
+	fake.MarkFailedStub = nil
+	if fake.markFailedReturnsOnCall == nil {
+		fake.markFailedReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.markFailedReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeMessageRepository) MarkSent(arg1 int64, arg2 string) error {
+	fake.markSentMutex.Lock()
+	ret, specificReturn := fake.markSentReturnsOnCall[len(fake.markSentArgsForCall)]
+	fake.markSentArgsForCall = append(fake.markSentArgsForCall, struct {
+		arg1 int64
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.MarkSentStub
+	fakeReturns := fake.markSentReturns
+	fake.recordInvocation("MarkSent", []interface{}{arg1, arg2})
+	fake.markSentMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeMessageRepository) MarkSentCallCount() int {
+	fake.markSentMutex.RLock()
+	defer fake.markSentMutex.RUnlock()
+	return len(fake.markSentArgsForCall)
+}
+
+func (fake *FakeMessageRepository) MarkSentCalls(stub func(int64, string) error) {
+	fake.markSentMutex.Lock()
+	defer fake.markSentMutex.Unlock()
+	fake.MarkSentStub = stub
+}
+
+func (fake *FakeMessageRepository) MarkSentArgsForCall(i int) (int64, string) {
+	fake.markSentMutex.RLock()
+	defer fake.markSentMutex.RUnlock()
+	argsForCall := fake.markSentArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeMessageRepository) MarkSentReturns(result1 error) {
+	fake.markSentMutex.Lock()
+	defer fake.markSentMutex.Unlock()
+	fake.MarkSentStub = nil
+	fake.markSentReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeMessageRepository) MarkSentReturnsOnCall(i int, result1 error) {
+	fake.markSentMutex.Lock()
+	defer fake.markSentMutex.Unlock()
+	fake.MarkSentStub = nil
+	if fake.markSentReturnsOnCall == nil {
+		fake.markSentReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.markSentReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeMessageRepository) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.addEventMutex.RLock()
+	defer fake.addEventMutex.RUnlock()
+	fake.claimMutex.RLock()
+	defer fake.claimMutex.RUnlock()
+	fake.countByUserMutex.RLock()
+	defer fake.countByUserMutex.RUnlock()
+	fake.getMutex.RLock()
+	defer fake.getMutex.RUnlock()
+	fake.getBySIDMutex.RLock()
+	defer fake.getBySIDMutex.RUnlock()
+	fake.insertMutex.RLock()
+	defer fake.insertMutex.RUnlock()
+	fake.listByUserMutex.RLock()
+	defer fake.listByUserMutex.RUnlock()
+	fake.markDeadMutex.RLock()
+	defer fake.markDeadMutex.RUnlock()
+	fake.markFailedMutex.RLock()
+	defer fake.markFailedMutex.RUnlock()
+	fake.markSentMutex.RLock()
+	defer fake.markSentMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeMessageRepository) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ models.MessageRepository = new(FakeMessageRepository)
@@ -0,0 +1,756 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package modelsfakes
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jcorry/morellis/pkg/models"
+)
+
+type FakeNotificationRepository struct {
+	ClaimStub        func(int64, string) ([]*models.Notification, error)
+	claimMutex       sync.RWMutex
+	claimArgsForCall []struct {
+		arg1 int64
+		arg2 string
+	}
+	claimReturns struct {
+		result1 []*models.Notification
+		result2 error
+	}
+	claimReturnsOnCall map[int]struct {
+		result1 []*models.Notification
+		result2 error
+	}
+	CountSentStub        func(int64, time.Time) (int, error)
+	countSentMutex       sync.RWMutex
+	countSentArgsForCall []struct {
+		arg1 int64
+		arg2 time.Time
+	}
+	countSentReturns struct {
+		result1 int
+		result2 error
+	}
+	countSentReturnsOnCall map[int]struct {
+		result1 int
+		result2 error
+	}
+	GetPolicyStub        func(int64) (*models.NotificationPolicy, error)
+	getPolicyMutex       sync.RWMutex
+	getPolicyArgsForCall []struct {
+		arg1 int64
+	}
+	getPolicyReturns struct {
+		result1 *models.NotificationPolicy
+		result2 error
+	}
+	getPolicyReturnsOnCall map[int]struct {
+		result1 *models.NotificationPolicy
+		result2 error
+	}
+	InsertStub        func(int64, int64, int64, string) (*models.Notification, error)
+	insertMutex       sync.RWMutex
+	insertArgsForCall []struct {
+		arg1 int64
+		arg2 int64
+		arg3 int64
+		arg4 string
+	}
+	insertReturns struct {
+		result1 *models.Notification
+		result2 error
+	}
+	insertReturnsOnCall map[int]struct {
+		result1 *models.Notification
+		result2 error
+	}
+	LastSentStub        func(int64) (time.Time, error)
+	lastSentMutex       sync.RWMutex
+	lastSentArgsForCall []struct {
+		arg1 int64
+	}
+	lastSentReturns struct {
+		result1 time.Time
+		result2 error
+	}
+	lastSentReturnsOnCall map[int]struct {
+		result1 time.Time
+		result2 error
+	}
+	MarkSentStub        func([]int64, string) error
+	markSentMutex       sync.RWMutex
+	markSentArgsForCall []struct {
+		arg1 []int64
+		arg2 string
+	}
+	markSentReturns struct {
+		result1 error
+	}
+	markSentReturnsOnCall map[int]struct {
+		result1 error
+	}
+	PendingUsersStub        func(models.Frequency) ([]int64, error)
+	pendingUsersMutex       sync.RWMutex
+	pendingUsersArgsForCall []struct {
+		arg1 models.Frequency
+	}
+	pendingUsersReturns struct {
+		result1 []int64
+		result2 error
+	}
+	pendingUsersReturnsOnCall map[int]struct {
+		result1 []int64
+		result2 error
+	}
+	ReleaseStub        func(string) error
+	releaseMutex       sync.RWMutex
+	releaseArgsForCall []struct {
+		arg1 string
+	}
+	releaseReturns struct {
+		result1 error
+	}
+	releaseReturnsOnCall map[int]struct {
+		result1 error
+	}
+	SavePolicyStub        func(*models.NotificationPolicy) (*models.NotificationPolicy, error)
+	savePolicyMutex       sync.RWMutex
+	savePolicyArgsForCall []struct {
+		arg1 *models.NotificationPolicy
+	}
+	savePolicyReturns struct {
+		result1 *models.NotificationPolicy
+		result2 error
+	}
+	savePolicyReturnsOnCall map[int]struct {
+		result1 *models.NotificationPolicy
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeNotificationRepository) Claim(arg1 int64, arg2 string) ([]*models.Notification, error) {
+	fake.claimMutex.Lock()
+	ret, specificReturn := fake.claimReturnsOnCall[len(fake.claimArgsForCall)]
+	fake.claimArgsForCall = append(fake.claimArgsForCall, struct {
+		arg1 int64
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.ClaimStub
+	fakeReturns := fake.claimReturns
+	fake.recordInvocation("Claim", []interface{}{arg1, arg2})
+	fake.claimMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeNotificationRepository) ClaimCallCount() int {
+	fake.claimMutex.RLock()
+	defer fake.claimMutex.RUnlock()
+	return len(fake.claimArgsForCall)
+}
+
+func (fake *FakeNotificationRepository) ClaimCalls(stub func(int64, string) ([]*models.Notification, error)) {
+	fake.claimMutex.Lock()
+	defer fake.claimMutex.Unlock()
+	fake.ClaimStub = stub
+}
+
+func (fake *FakeNotificationRepository) ClaimArgsForCall(i int) (int64, string) {
+	fake.claimMutex.RLock()
+	defer fake.claimMutex.RUnlock()
+	argsForCall := fake.claimArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeNotificationRepository) ClaimReturns(result1 []*models.Notification, result2 error) {
+	fake.claimMutex.Lock()
+	defer fake.claimMutex.Unlock()
+	fake.ClaimStub = nil
+	fake.claimReturns = struct {
+		result1 []*models.Notification
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeNotificationRepository) ClaimReturnsOnCall(i int, result1 []*models.Notification, result2 error) {
+	fake.claimMutex.Lock()
+	defer fake.claimMutex.Unlock()
+	fake.ClaimStub = nil
+	if fake.claimReturnsOnCall == nil {
+		fake.claimReturnsOnCall = make(map[int]struct {
+			result1 []*models.Notification
+			result2 error
+		})
+	}
+	fake.claimReturnsOnCall[i] = struct {
+		result1 []*models.Notification
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeNotificationRepository) CountSent(arg1 int64, arg2 time.Time) (int, error) {
+	fake.countSentMutex.Lock()
+	ret, specificReturn := fake.countSentReturnsOnCall[len(fake.countSentArgsForCall)]
+	fake.countSentArgsForCall = append(fake.countSentArgsForCall, struct {
+		arg1 int64
+		arg2 time.Time
+	}{arg1, arg2})
+	stub := fake.CountSentStub
+	fakeReturns := fake.countSentReturns
+	fake.recordInvocation("CountSent", []interface{}{arg1, arg2})
+	fake.countSentMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeNotificationRepository) CountSentCallCount() int {
+	fake.countSentMutex.RLock()
+	defer fake.countSentMutex.RUnlock()
+	return len(fake.countSentArgsForCall)
+}
+
+func (fake *FakeNotificationRepository) CountSentCalls(stub func(int64, time.Time) (int, error)) {
+	fake.countSentMutex.Lock()
+	defer fake.countSentMutex.Unlock()
+	fake.CountSentStub = stub
+}
+
+func (fake *FakeNotificationRepository) CountSentArgsForCall(i int) (int64, time.Time) {
+	fake.countSentMutex.RLock()
+	defer fake.countSentMutex.RUnlock()
+	argsForCall := fake.countSentArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeNotificationRepository) CountSentReturns(result1 int, result2 error) {
+	fake.countSentMutex.Lock()
+	defer fake.countSentMutex.Unlock()
+	fake.CountSentStub = nil
+	fake.countSentReturns = struct {
+		result1 int
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeNotificationRepository) CountSentReturnsOnCall(i int, result1 int, result2 error) {
+	fake.countSentMutex.Lock()
+	defer fake.countSentMutex.Unlock()
+	fake.CountSentStub = nil
+	if fake.countSentReturnsOnCall == nil {
+		fake.countSentReturnsOnCall = make(map[int]struct {
+			result1 int
+			result2 error
+		})
+	}
+	fake.countSentReturnsOnCall[i] = struct {
+		result1 int
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeNotificationRepository) GetPolicy(arg1 int64) (*models.NotificationPolicy, error) {
+	fake.getPolicyMutex.Lock()
+	ret, specificReturn := fake.getPolicyReturnsOnCall[len(fake.getPolicyArgsForCall)]
+	fake.getPolicyArgsForCall = append(fake.getPolicyArgsForCall, struct {
+		arg1 int64
+	}{arg1})
+	stub := fake.GetPolicyStub
+	fakeReturns := fake.getPolicyReturns
+	fake.recordInvocation("GetPolicy", []interface{}{arg1})
+	fake.getPolicyMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeNotificationRepository) GetPolicyCallCount() int {
+	fake.getPolicyMutex.RLock()
+	defer fake.getPolicyMutex.RUnlock()
+	return len(fake.getPolicyArgsForCall)
+}
+
+func (fake *FakeNotificationRepository) GetPolicyCalls(stub func(int64) (*models.NotificationPolicy, error)) {
+	fake.getPolicyMutex.Lock()
+	defer fake.getPolicyMutex.Unlock()
+	fake.GetPolicyStub = stub
+}
+
+func (fake *FakeNotificationRepository) GetPolicyArgsForCall(i int) int64 {
+	fake.getPolicyMutex.RLock()
+	defer fake.getPolicyMutex.RUnlock()
+	argsForCall := fake.getPolicyArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeNotificationRepository) GetPolicyReturns(result1 *models.NotificationPolicy, result2 error) {
+	fake.getPolicyMutex.Lock()
+	defer fake.getPolicyMutex.Unlock()
+	fake.GetPolicyStub = nil
+	fake.getPolicyReturns = struct {
+		result1 *models.NotificationPolicy
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeNotificationRepository) GetPolicyReturnsOnCall(i int, result1 *models.NotificationPolicy, result2 error) {
+	fake.getPolicyMutex.Lock()
+	defer fake.getPolicyMutex.Unlock()
+	fake.GetPolicyStub = nil
+	if fake.getPolicyReturnsOnCall == nil {
+		fake.getPolicyReturnsOnCall = make(map[int]struct {
+			result1 *models.NotificationPolicy
+			result2 error
+		})
+	}
+	fake.getPolicyReturnsOnCall[i] = struct {
+		result1 *models.NotificationPolicy
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeNotificationRepository) Insert(arg1 int64, arg2 int64, arg3 int64, arg4 string) (*models.Notification, error) {
+	fake.insertMutex.Lock()
+	ret, specificReturn := fake.insertReturnsOnCall[len(fake.insertArgsForCall)]
+	fake.insertArgsForCall = append(fake.insertArgsForCall, struct {
+		arg1 int64
+		arg2 int64
+		arg3 int64
+		arg4 string
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.InsertStub
+	fakeReturns := fake.insertReturns
+	fake.recordInvocation("Insert", []interface{}{arg1, arg2, arg3, arg4})
+	fake.insertMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeNotificationRepository) InsertCallCount() int {
+	fake.insertMutex.RLock()
+	defer fake.insertMutex.RUnlock()
+	return len(fake.insertArgsForCall)
+}
+
+func (fake *FakeNotificationRepository) InsertCalls(stub func(int64, int64, int64, string) (*models.Notification, error)) {
+	fake.insertMutex.Lock()
+	defer fake.insertMutex.Unlock()
+	fake.InsertStub = stub
+}
+
+func (fake *FakeNotificationRepository) InsertArgsForCall(i int) (int64, int64, int64, string) {
+	fake.insertMutex.RLock()
+	defer fake.insertMutex.RUnlock()
+	argsForCall := fake.insertArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeNotificationRepository) InsertReturns(result1 *models.Notification, result2 error) {
+	fake.insertMutex.Lock()
+	defer fake.insertMutex.Unlock()
+	fake.InsertStub = nil
+	fake.insertReturns = struct {
+		result1 *models.Notification
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeNotificationRepository) InsertReturnsOnCall(i int, result1 *models.Notification, result2 error) {
+	fake.insertMutex.Lock()
+	defer fake.insertMutex.Unlock()
+	fake.InsertStub = nil
+	if fake.insertReturnsOnCall == nil {
+		fake.insertReturnsOnCall = make(map[int]struct {
+			result1 *models.Notification
+			result2 error
+		})
+	}
+	fake.insertReturnsOnCall[i] = struct {
+		result1 *models.Notification
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeNotificationRepository) LastSent(arg1 int64) (time.Time, error) {
+	fake.lastSentMutex.Lock()
+	ret, specificReturn := fake.lastSentReturnsOnCall[len(fake.lastSentArgsForCall)]
+	fake.lastSentArgsForCall = append(fake.lastSentArgsForCall, struct {
+		arg1 int64
+	}{arg1})
+	stub := fake.LastSentStub
+	fakeReturns := fake.lastSentReturns
+	fake.recordInvocation("LastSent", []interface{}{arg1})
+	fake.lastSentMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeNotificationRepository) LastSentCallCount() int {
+	fake.lastSentMutex.RLock()
+	defer fake.lastSentMutex.RUnlock()
+	return len(fake.lastSentArgsForCall)
+}
+
+func (fake *FakeNotificationRepository) LastSentCalls(stub func(int64) (time.Time, error)) {
+	fake.lastSentMutex.Lock()
+	defer fake.lastSentMutex.Unlock()
+	fake.LastSentStub = stub
+}
+
+func (fake *FakeNotificationRepository) LastSentArgsForCall(i int) int64 {
+	fake.lastSentMutex.RLock()
+	defer fake.lastSentMutex.RUnlock()
+	argsForCall := fake.lastSentArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeNotificationRepository) LastSentReturns(result1 time.Time, result2 error) {
+	fake.lastSentMutex.Lock()
+	defer fake.lastSentMutex.Unlock()
+	fake.LastSentStub = nil
+	fake.lastSentReturns = struct {
+		result1 time.Time
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeNotificationRepository) LastSentReturnsOnCall(i int, result1 time.Time, result2 error) {
+	fake.lastSentMutex.Lock()
+	defer fake.lastSentMutex.Unlock()
+	fake.LastSentStub = nil
+	if fake.lastSentReturnsOnCall == nil {
+		fake.lastSentReturnsOnCall = make(map[int]struct {
+			result1 time.Time
+			result2 error
+		})
+	}
+	fake.lastSentReturnsOnCall[i] = struct {
+		result1 time.Time
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeNotificationRepository) MarkSent(arg1 []int64, arg2 string) error {
+	var arg1Copy []int64
+	if arg1 != nil {
+		arg1Copy = make([]int64, len(arg1))
+		copy(arg1Copy, arg1)
+	}
+	fake.markSentMutex.Lock()
+	ret, specificReturn := fake.markSentReturnsOnCall[len(fake.markSentArgsForCall)]
+	fake.markSentArgsForCall = append(fake.markSentArgsForCall, struct {
+		arg1 []int64
+		arg2 string
+	}{arg1Copy, arg2})
+	stub := fake.MarkSentStub
+	fakeReturns := fake.markSentReturns
+	fake.recordInvocation("MarkSent", []interface{}{arg1Copy, arg2})
+	fake.markSentMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeNotificationRepository) MarkSentCallCount() int {
+	fake.markSentMutex.RLock()
+	defer fake.markSentMutex.RUnlock()
+	return len(fake.markSentArgsForCall)
+}
+
+func (fake *FakeNotificationRepository) MarkSentCalls(stub func([]int64, string) error) {
+	fake.markSentMutex.Lock()
+	defer fake.markSentMutex.Unlock()
+	fake.MarkSentStub = stub
+}
+
+func (fake *FakeNotificationRepository) MarkSentArgsForCall(i int) ([]int64, string) {
+	fake.markSentMutex.RLock()
+	defer fake.markSentMutex.RUnlock()
+	argsForCall := fake.markSentArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeNotificationRepository) MarkSentReturns(result1 error) {
+	fake.markSentMutex.Lock()
+	defer fake.markSentMutex.Unlock()
+	fake.MarkSentStub = nil
+	fake.markSentReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeNotificationRepository) MarkSentReturnsOnCall(i int, result1 error) {
+	fake.markSentMutex.Lock()
+	defer fake.markSentMutex.Unlock()
+	fake.MarkSentStub = nil
+	if fake.markSentReturnsOnCall == nil {
+		fake.markSentReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.markSentReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeNotificationRepository) PendingUsers(arg1 models.Frequency) ([]int64, error) {
+	fake.pendingUsersMutex.Lock()
+	ret, specificReturn := fake.pendingUsersReturnsOnCall[len(fake.pendingUsersArgsForCall)]
+	fake.pendingUsersArgsForCall = append(fake.pendingUsersArgsForCall, struct {
+		arg1 models.Frequency
+	}{arg1})
+	stub := fake.PendingUsersStub
+	fakeReturns := fake.pendingUsersReturns
+	fake.recordInvocation("PendingUsers", []interface{}{arg1})
+	fake.pendingUsersMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeNotificationRepository) PendingUsersCallCount() int {
+	fake.pendingUsersMutex.RLock()
+	defer fake.pendingUsersMutex.RUnlock()
+	return len(fake.pendingUsersArgsForCall)
+}
+
+func (fake *FakeNotificationRepository) PendingUsersCalls(stub func(models.Frequency) ([]int64, error)) {
+	fake.pendingUsersMutex.Lock()
+	defer fake.pendingUsersMutex.Unlock()
+	fake.PendingUsersStub = stub
+}
+
+func (fake *FakeNotificationRepository) PendingUsersArgsForCall(i int) models.Frequency {
+	fake.pendingUsersMutex.RLock()
+	defer fake.pendingUsersMutex.RUnlock()
+	argsForCall := fake.pendingUsersArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeNotificationRepository) PendingUsersReturns(result1 []int64, result2 error) {
+	fake.pendingUsersMutex.Lock()
+	defer fake.pendingUsersMutex.Unlock()
+	fake.PendingUsersStub = nil
+	fake.pendingUsersReturns = struct {
+		result1 []int64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeNotificationRepository) PendingUsersReturnsOnCall(i int, result1 []int64, result2 error) {
+	fake.pendingUsersMutex.Lock()
+	defer fake.pendingUsersMutex.Unlock()
+	fake.PendingUsersStub = nil
+	if fake.pendingUsersReturnsOnCall == nil {
+		fake.pendingUsersReturnsOnCall = make(map[int]struct {
+			result1 []int64
+			result2 error
+		})
+	}
+	fake.pendingUsersReturnsOnCall[i] = struct {
+		result1 []int64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeNotificationRepository) Release(arg1 string) error {
+	fake.releaseMutex.Lock()
+	ret, specificReturn := fake.releaseReturnsOnCall[len(fake.releaseArgsForCall)]
+	fake.releaseArgsForCall = append(fake.releaseArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ReleaseStub
+	fakeReturns := fake.releaseReturns
+	fake.recordInvocation("Release", []interface{}{arg1})
+	fake.releaseMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeNotificationRepository) ReleaseCallCount() int {
+	fake.releaseMutex.RLock()
+	defer fake.releaseMutex.RUnlock()
+	return len(fake.releaseArgsForCall)
+}
+
+func (fake *FakeNotificationRepository) ReleaseCalls(stub func(string) error) {
+	fake.releaseMutex.Lock()
+	defer fake.releaseMutex.Unlock()
+	fake.ReleaseStub = stub
+}
+
+func (fake *FakeNotificationRepository) ReleaseArgsForCall(i int) string {
+	fake.releaseMutex.RLock()
+	defer fake.releaseMutex.RUnlock()
+	argsForCall := fake.releaseArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeNotificationRepository) ReleaseReturns(result1 error) {
+	fake.releaseMutex.Lock()
+	defer fake.releaseMutex.Unlock()
+	fake.ReleaseStub = nil
+	fake.releaseReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeNotificationRepository) ReleaseReturnsOnCall(i int, result1 error) {
+	fake.releaseMutex.Lock()
+	defer fake.releaseMutex.Unlock()
+	fake.ReleaseStub = nil
+	if fake.releaseReturnsOnCall == nil {
+		fake.releaseReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.releaseReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeNotificationRepository) SavePolicy(arg1 *models.NotificationPolicy) (*models.NotificationPolicy, error) {
+	fake.savePolicyMutex.Lock()
+	ret, specificReturn := fake.savePolicyReturnsOnCall[len(fake.savePolicyArgsForCall)]
+	fake.savePolicyArgsForCall = append(fake.savePolicyArgsForCall, struct {
+		arg1 *models.NotificationPolicy
+	}{arg1})
+	stub := fake.SavePolicyStub
+	fakeReturns := fake.savePolicyReturns
+	fake.recordInvocation("SavePolicy", []interface{}{arg1})
+	fake.savePolicyMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeNotificationRepository) SavePolicyCallCount() int {
+	fake.savePolicyMutex.RLock()
+	defer fake.savePolicyMutex.RUnlock()
+	return len(fake.savePolicyArgsForCall)
+}
+
+func (fake *FakeNotificationRepository) SavePolicyCalls(stub func(*models.NotificationPolicy) (*models.NotificationPolicy, error)) {
+	fake.savePolicyMutex.Lock()
+	defer fake.savePolicyMutex.Unlock()
+	fake.SavePolicyStub = stub
+}
+
+func (fake *FakeNotificationRepository) SavePolicyArgsForCall(i int) *models.NotificationPolicy {
+	fake.savePolicyMutex.RLock()
+	defer fake.savePolicyMutex.RUnlock()
+	argsForCall := fake.savePolicyArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeNotificationRepository) SavePolicyReturns(result1 *models.NotificationPolicy, result2 error) {
+	fake.savePolicyMutex.Lock()
+	defer fake.savePolicyMutex.Unlock()
+	fake.SavePolicyStub = nil
+	fake.savePolicyReturns = struct {
+		result1 *models.NotificationPolicy
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeNotificationRepository) SavePolicyReturnsOnCall(i int, result1 *models.NotificationPolicy, result2 error) {
+	fake.savePolicyMutex.Lock()
+	defer fake.savePolicyMutex.Unlock()
+	fake.SavePolicyStub = nil
+	if fake.savePolicyReturnsOnCall == nil {
+		fake.savePolicyReturnsOnCall = make(map[int]struct {
+			result1 *models.NotificationPolicy
+			result2 error
+		})
+	}
+	fake.savePolicyReturnsOnCall[i] = struct {
+		result1 *models.NotificationPolicy
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeNotificationRepository) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.claimMutex.RLock()
+	defer fake.claimMutex.RUnlock()
+	fake.countSentMutex.RLock()
+	defer fake.countSentMutex.RUnlock()
+	fake.getPolicyMutex.RLock()
+	defer fake.getPolicyMutex.RUnlock()
+	fake.insertMutex.RLock()
+	defer fake.insertMutex.RUnlock()
+	fake.lastSentMutex.RLock()
+	defer fake.lastSentMutex.RUnlock()
+	fake.markSentMutex.RLock()
+	defer fake.markSentMutex.RUnlock()
+	fake.pendingUsersMutex.RLock()
+	defer fake.pendingUsersMutex.RUnlock()
+	fake.releaseMutex.RLock()
+	defer fake.releaseMutex.RUnlock()
+	fake.savePolicyMutex.RLock()
+	defer fake.savePolicyMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeNotificationRepository) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ models.NotificationRepository = new(FakeNotificationRepository)
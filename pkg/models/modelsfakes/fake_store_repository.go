@@ -3,6 +3,7 @@ package modelsfakes
 
 import (
 	"sync"
+	"time"
 
 	"github.com/jcorry/morellis/pkg/models"
 )
@@ -72,6 +73,19 @@ type FakeStoreRepository struct {
 		result1 *models.Store
 		result2 error
 	}
+	GetLayoutStub        func(int64) (*models.Layout, error)
+	getLayoutMutex       sync.RWMutex
+	getLayoutArgsForCall []struct {
+		arg1 int64
+	}
+	getLayoutReturns struct {
+		result1 *models.Layout
+		result2 error
+	}
+	getLayoutReturnsOnCall map[int]struct {
+		result1 *models.Layout
+		result2 error
+	}
 	InsertStub        func(string, string, string, string, string, string, string, string, float64, float64) (*models.Store, error)
 	insertMutex       sync.RWMutex
 	insertArgsForCall []struct {
@@ -109,6 +123,60 @@ type FakeStoreRepository struct {
 		result1 []*models.Store
 		result2 error
 	}
+	ListLineupsStub        func(int64) ([]*models.Lineup, error)
+	listLineupsMutex       sync.RWMutex
+	listLineupsArgsForCall []struct {
+		arg1 int64
+	}
+	listLineupsReturns struct {
+		result1 []*models.Lineup
+		result2 error
+	}
+	listLineupsReturnsOnCall map[int]struct {
+		result1 []*models.Lineup
+		result2 error
+	}
+	ListRotationsStub        func(time.Time, time.Time, int64, int64) ([]*models.Rotation, error)
+	listRotationsMutex       sync.RWMutex
+	listRotationsArgsForCall []struct {
+		arg1 time.Time
+		arg2 time.Time
+		arg3 int64
+		arg4 int64
+	}
+	listRotationsReturns struct {
+		result1 []*models.Rotation
+		result2 error
+	}
+	listRotationsReturnsOnCall map[int]struct {
+		result1 []*models.Rotation
+		result2 error
+	}
+	ReplaceLineupStub        func(int64, map[int]int64) (*models.LineupDiff, error)
+	replaceLineupMutex       sync.RWMutex
+	replaceLineupArgsForCall []struct {
+		arg1 int64
+		arg2 map[int]int64
+	}
+	replaceLineupReturns struct {
+		result1 *models.LineupDiff
+		result2 error
+	}
+	replaceLineupReturnsOnCall map[int]struct {
+		result1 *models.LineupDiff
+		result2 error
+	}
+	SaveLayoutStub        func(*models.Layout) error
+	saveLayoutMutex       sync.RWMutex
+	saveLayoutArgsForCall []struct {
+		arg1 *models.Layout
+	}
+	saveLayoutReturns struct {
+		result1 error
+	}
+	saveLayoutReturnsOnCall map[int]struct {
+		result1 error
+	}
 	UpdateStub        func(int, string, string, string, string, string, string, string, string, float64, float64) (*models.Store, error)
 	updateMutex       sync.RWMutex
 	updateArgsForCall []struct {
@@ -144,15 +212,16 @@ func (fake *FakeStoreRepository) ActivateFlavor(arg1 int64, arg2 int64, arg3 int
 		arg2 int64
 		arg3 int
 	}{arg1, arg2, arg3})
+	stub := fake.ActivateFlavorStub
+	fakeReturns := fake.activateFlavorReturns
 	fake.recordInvocation("ActivateFlavor", []interface{}{arg1, arg2, arg3})
 	fake.activateFlavorMutex.Unlock()
-	if fake.ActivateFlavorStub != nil {
-		return fake.ActivateFlavorStub(arg1, arg2, arg3)
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.activateFlavorReturns
 	return fakeReturns.result1
 }
 
@@ -203,15 +272,16 @@ func (fake *FakeStoreRepository) Count() int {
 	ret, specificReturn := fake.countReturnsOnCall[len(fake.countArgsForCall)]
 	fake.countArgsForCall = append(fake.countArgsForCall, struct {
 	}{})
+	stub := fake.CountStub
+	fakeReturns := fake.countReturns
 	fake.recordInvocation("Count", []interface{}{})
 	fake.countMutex.Unlock()
-	if fake.CountStub != nil {
-		return fake.CountStub()
+	if stub != nil {
+		return stub()
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.countReturns
 	return fakeReturns.result1
 }
 
@@ -257,15 +327,16 @@ func (fake *FakeStoreRepository) DeactivateFlavor(arg1 int64, arg2 int64) (bool,
 		arg1 int64
 		arg2 int64
 	}{arg1, arg2})
+	stub := fake.DeactivateFlavorStub
+	fakeReturns := fake.deactivateFlavorReturns
 	fake.recordInvocation("DeactivateFlavor", []interface{}{arg1, arg2})
 	fake.deactivateFlavorMutex.Unlock()
-	if fake.DeactivateFlavorStub != nil {
-		return fake.DeactivateFlavorStub(arg1, arg2)
+	if stub != nil {
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.deactivateFlavorReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -321,15 +392,16 @@ func (fake *FakeStoreRepository) DeactivateFlavorAtPosition(arg1 int64, arg2 int
 		arg1 int64
 		arg2 int
 	}{arg1, arg2})
+	stub := fake.DeactivateFlavorAtPositionStub
+	fakeReturns := fake.deactivateFlavorAtPositionReturns
 	fake.recordInvocation("DeactivateFlavorAtPosition", []interface{}{arg1, arg2})
 	fake.deactivateFlavorAtPositionMutex.Unlock()
-	if fake.DeactivateFlavorAtPositionStub != nil {
-		return fake.DeactivateFlavorAtPositionStub(arg1, arg2)
+	if stub != nil {
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.deactivateFlavorAtPositionReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -384,15 +456,16 @@ func (fake *FakeStoreRepository) Get(arg1 int) (*models.Store, error) {
 	fake.getArgsForCall = append(fake.getArgsForCall, struct {
 		arg1 int
 	}{arg1})
+	stub := fake.GetStub
+	fakeReturns := fake.getReturns
 	fake.recordInvocation("Get", []interface{}{arg1})
 	fake.getMutex.Unlock()
-	if fake.GetStub != nil {
-		return fake.GetStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.getReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -441,6 +514,70 @@ func (fake *FakeStoreRepository) GetReturnsOnCall(i int, result1 *models.Store,
 	}{result1, result2}
 }
 
+func (fake *FakeStoreRepository) GetLayout(arg1 int64) (*models.Layout, error) {
+	fake.getLayoutMutex.Lock()
+	ret, specificReturn := fake.getLayoutReturnsOnCall[len(fake.getLayoutArgsForCall)]
+	fake.getLayoutArgsForCall = append(fake.getLayoutArgsForCall, struct {
+		arg1 int64
+	}{arg1})
+	stub := fake.GetLayoutStub
+	fakeReturns := fake.getLayoutReturns
+	fake.recordInvocation("GetLayout", []interface{}{arg1})
+	fake.getLayoutMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeStoreRepository) GetLayoutCallCount() int {
+	fake.getLayoutMutex.RLock()
+	defer fake.getLayoutMutex.RUnlock()
+	return len(fake.getLayoutArgsForCall)
+}
+
+func (fake *FakeStoreRepository) GetLayoutCalls(stub func(int64) (*models.Layout, error)) {
+	fake.getLayoutMutex.Lock()
+	defer fake.getLayoutMutex.Unlock()
+	fake.GetLayoutStub = stub
+}
+
+func (fake *FakeStoreRepository) GetLayoutArgsForCall(i int) int64 {
+	fake.getLayoutMutex.RLock()
+	defer fake.getLayoutMutex.RUnlock()
+	argsForCall := fake.getLayoutArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeStoreRepository) GetLayoutReturns(result1 *models.Layout, result2 error) {
+	fake.getLayoutMutex.Lock()
+	defer fake.getLayoutMutex.Unlock()
+	fake.GetLayoutStub = nil
+	fake.getLayoutReturns = struct {
+		result1 *models.Layout
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeStoreRepository) GetLayoutReturnsOnCall(i int, result1 *models.Layout, result2 error) {
+	fake.getLayoutMutex.Lock()
+	defer fake.getLayoutMutex.Unlock()
+	fake.GetLayoutStub = nil
+	if fake.getLayoutReturnsOnCall == nil {
+		fake.getLayoutReturnsOnCall = make(map[int]struct {
+			result1 *models.Layout
+			result2 error
+		})
+	}
+	fake.getLayoutReturnsOnCall[i] = struct {
+		result1 *models.Layout
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeStoreRepository) Insert(arg1 string, arg2 string, arg3 string, arg4 string, arg5 string, arg6 string, arg7 string, arg8 string, arg9 float64, arg10 float64) (*models.Store, error) {
 	fake.insertMutex.Lock()
 	ret, specificReturn := fake.insertReturnsOnCall[len(fake.insertArgsForCall)]
@@ -456,15 +593,16 @@ func (fake *FakeStoreRepository) Insert(arg1 string, arg2 string, arg3 string, a
 		arg9  float64
 		arg10 float64
 	}{arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10})
+	stub := fake.InsertStub
+	fakeReturns := fake.insertReturns
 	fake.recordInvocation("Insert", []interface{}{arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10})
 	fake.insertMutex.Unlock()
-	if fake.InsertStub != nil {
-		return fake.InsertStub(arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10)
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.insertReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -521,15 +659,16 @@ func (fake *FakeStoreRepository) List(arg1 int, arg2 int, arg3 string) ([]*model
 		arg2 int
 		arg3 string
 	}{arg1, arg2, arg3})
+	stub := fake.ListStub
+	fakeReturns := fake.listReturns
 	fake.recordInvocation("List", []interface{}{arg1, arg2, arg3})
 	fake.listMutex.Unlock()
-	if fake.ListStub != nil {
-		return fake.ListStub(arg1, arg2, arg3)
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.listReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -578,6 +717,263 @@ func (fake *FakeStoreRepository) ListReturnsOnCall(i int, result1 []*models.Stor
 	}{result1, result2}
 }
 
+func (fake *FakeStoreRepository) ListLineups(arg1 int64) ([]*models.Lineup, error) {
+	fake.listLineupsMutex.Lock()
+	ret, specificReturn := fake.listLineupsReturnsOnCall[len(fake.listLineupsArgsForCall)]
+	fake.listLineupsArgsForCall = append(fake.listLineupsArgsForCall, struct {
+		arg1 int64
+	}{arg1})
+	stub := fake.ListLineupsStub
+	fakeReturns := fake.listLineupsReturns
+	fake.recordInvocation("ListLineups", []interface{}{arg1})
+	fake.listLineupsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeStoreRepository) ListLineupsCallCount() int {
+	fake.listLineupsMutex.RLock()
+	defer fake.listLineupsMutex.RUnlock()
+	return len(fake.listLineupsArgsForCall)
+}
+
+func (fake *FakeStoreRepository) ListLineupsCalls(stub func(int64) ([]*models.Lineup, error)) {
+	fake.listLineupsMutex.Lock()
+	defer fake.listLineupsMutex.Unlock()
+	fake.ListLineupsStub = stub
+}
+
+func (fake *FakeStoreRepository) ListLineupsArgsForCall(i int) int64 {
+	fake.listLineupsMutex.RLock()
+	defer fake.listLineupsMutex.RUnlock()
+	argsForCall := fake.listLineupsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeStoreRepository) ListLineupsReturns(result1 []*models.Lineup, result2 error) {
+	fake.listLineupsMutex.Lock()
+	defer fake.listLineupsMutex.Unlock()
+	fake.ListLineupsStub = nil
+	fake.listLineupsReturns = struct {
+		result1 []*models.Lineup
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeStoreRepository) ListLineupsReturnsOnCall(i int, result1 []*models.Lineup, result2 error) {
+	fake.listLineupsMutex.Lock()
+	defer fake.listLineupsMutex.Unlock()
+	fake.ListLineupsStub = nil
+	if fake.listLineupsReturnsOnCall == nil {
+		fake.listLineupsReturnsOnCall = make(map[int]struct {
+			result1 []*models.Lineup
+			result2 error
+		})
+	}
+	fake.listLineupsReturnsOnCall[i] = struct {
+		result1 []*models.Lineup
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeStoreRepository) ListRotations(arg1 time.Time, arg2 time.Time, arg3 int64, arg4 int64) ([]*models.Rotation, error) {
+	fake.listRotationsMutex.Lock()
+	ret, specificReturn := fake.listRotationsReturnsOnCall[len(fake.listRotationsArgsForCall)]
+	fake.listRotationsArgsForCall = append(fake.listRotationsArgsForCall, struct {
+		arg1 time.Time
+		arg2 time.Time
+		arg3 int64
+		arg4 int64
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.ListRotationsStub
+	fakeReturns := fake.listRotationsReturns
+	fake.recordInvocation("ListRotations", []interface{}{arg1, arg2, arg3, arg4})
+	fake.listRotationsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeStoreRepository) ListRotationsCallCount() int {
+	fake.listRotationsMutex.RLock()
+	defer fake.listRotationsMutex.RUnlock()
+	return len(fake.listRotationsArgsForCall)
+}
+
+func (fake *FakeStoreRepository) ListRotationsCalls(stub func(time.Time, time.Time, int64, int64) ([]*models.Rotation, error)) {
+	fake.listRotationsMutex.Lock()
+	defer fake.listRotationsMutex.Unlock()
+	fake.ListRotationsStub = stub
+}
+
+func (fake *FakeStoreRepository) ListRotationsArgsForCall(i int) (time.Time, time.Time, int64, int64) {
+	fake.listRotationsMutex.RLock()
+	defer fake.listRotationsMutex.RUnlock()
+	argsForCall := fake.listRotationsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeStoreRepository) ListRotationsReturns(result1 []*models.Rotation, result2 error) {
+	fake.listRotationsMutex.Lock()
+	defer fake.listRotationsMutex.Unlock()
+	fake.ListRotationsStub = nil
+	fake.listRotationsReturns = struct {
+		result1 []*models.Rotation
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeStoreRepository) ListRotationsReturnsOnCall(i int, result1 []*models.Rotation, result2 error) {
+	fake.listRotationsMutex.Lock()
+	defer fake.listRotationsMutex.Unlock()
+	fake.ListRotationsStub = nil
+	if fake.listRotationsReturnsOnCall == nil {
+		fake.listRotationsReturnsOnCall = make(map[int]struct {
+			result1 []*models.Rotation
+			result2 error
+		})
+	}
+	fake.listRotationsReturnsOnCall[i] = struct {
+		result1 []*models.Rotation
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeStoreRepository) ReplaceLineup(arg1 int64, arg2 map[int]int64) (*models.LineupDiff, error) {
+	fake.replaceLineupMutex.Lock()
+	ret, specificReturn := fake.replaceLineupReturnsOnCall[len(fake.replaceLineupArgsForCall)]
+	fake.replaceLineupArgsForCall = append(fake.replaceLineupArgsForCall, struct {
+		arg1 int64
+		arg2 map[int]int64
+	}{arg1, arg2})
+	stub := fake.ReplaceLineupStub
+	fakeReturns := fake.replaceLineupReturns
+	fake.recordInvocation("ReplaceLineup", []interface{}{arg1, arg2})
+	fake.replaceLineupMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeStoreRepository) ReplaceLineupCallCount() int {
+	fake.replaceLineupMutex.RLock()
+	defer fake.replaceLineupMutex.RUnlock()
+	return len(fake.replaceLineupArgsForCall)
+}
+
+func (fake *FakeStoreRepository) ReplaceLineupCalls(stub func(int64, map[int]int64) (*models.LineupDiff, error)) {
+	fake.replaceLineupMutex.Lock()
+	defer fake.replaceLineupMutex.Unlock()
+	fake.ReplaceLineupStub = stub
+}
+
+func (fake *FakeStoreRepository) ReplaceLineupArgsForCall(i int) (int64, map[int]int64) {
+	fake.replaceLineupMutex.RLock()
+	defer fake.replaceLineupMutex.RUnlock()
+	argsForCall := fake.replaceLineupArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeStoreRepository) ReplaceLineupReturns(result1 *models.LineupDiff, result2 error) {
+	fake.replaceLineupMutex.Lock()
+	defer fake.replaceLineupMutex.Unlock()
+	fake.ReplaceLineupStub = nil
+	fake.replaceLineupReturns = struct {
+		result1 *models.LineupDiff
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeStoreRepository) ReplaceLineupReturnsOnCall(i int, result1 *models.LineupDiff, result2 error) {
+	fake.replaceLineupMutex.Lock()
+	defer fake.replaceLineupMutex.Unlock()
+	fake.ReplaceLineupStub = nil
+	if fake.replaceLineupReturnsOnCall == nil {
+		fake.replaceLineupReturnsOnCall = make(map[int]struct {
+			result1 *models.LineupDiff
+			result2 error
+		})
+	}
+	fake.replaceLineupReturnsOnCall[i] = struct {
+		result1 *models.LineupDiff
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeStoreRepository) SaveLayout(arg1 *models.Layout) error {
+	fake.saveLayoutMutex.Lock()
+	ret, specificReturn := fake.saveLayoutReturnsOnCall[len(fake.saveLayoutArgsForCall)]
+	fake.saveLayoutArgsForCall = append(fake.saveLayoutArgsForCall, struct {
+		arg1 *models.Layout
+	}{arg1})
+	stub := fake.SaveLayoutStub
+	fakeReturns := fake.saveLayoutReturns
+	fake.recordInvocation("SaveLayout", []interface{}{arg1})
+	fake.saveLayoutMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeStoreRepository) SaveLayoutCallCount() int {
+	fake.saveLayoutMutex.RLock()
+	defer fake.saveLayoutMutex.RUnlock()
+	return len(fake.saveLayoutArgsForCall)
+}
+
+func (fake *FakeStoreRepository) SaveLayoutCalls(stub func(*models.Layout) error) {
+	fake.saveLayoutMutex.Lock()
+	defer fake.saveLayoutMutex.Unlock()
+	fake.SaveLayoutStub = stub
+}
+
+func (fake *FakeStoreRepository) SaveLayoutArgsForCall(i int) *models.Layout {
+	fake.saveLayoutMutex.RLock()
+	defer fake.saveLayoutMutex.RUnlock()
+	argsForCall := fake.saveLayoutArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeStoreRepository) SaveLayoutReturns(result1 error) {
+	fake.saveLayoutMutex.Lock()
+	defer fake.saveLayoutMutex.Unlock()
+	fake.SaveLayoutStub = nil
+	fake.saveLayoutReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeStoreRepository) SaveLayoutReturnsOnCall(i int, result1 error) {
+	fake.saveLayoutMutex.Lock()
+	defer fake.saveLayoutMutex.Unlock()
+	fake.SaveLayoutStub = nil
+	if fake.saveLayoutReturnsOnCall == nil {
+		fake.saveLayoutReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.saveLayoutReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeStoreRepository) Update(arg1 int, arg2 string, arg3 string, arg4 string, arg5 string, arg6 string, arg7 string, arg8 string, arg9 string, arg10 float64, arg11 float64) (*models.Store, error) {
 	fake.updateMutex.Lock()
 	ret, specificReturn := fake.updateReturnsOnCall[len(fake.updateArgsForCall)]
@@ -594,15 +990,16 @@ func (fake *FakeStoreRepository) Update(arg1 int, arg2 string, arg3 string, arg4
 		arg10 float64
 		arg11 float64
 	}{arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10, arg11})
+	stub := fake.UpdateStub
+	fakeReturns := fake.updateReturns
 	fake.recordInvocation("Update", []interface{}{arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10, arg11})
 	fake.updateMutex.Unlock()
-	if fake.UpdateStub != nil {
-		return fake.UpdateStub(arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10, arg11)
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10, arg11)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.updateReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -664,10 +1061,20 @@ func (fake *FakeStoreRepository) Invocations() map[string][][]interface{} {
 	defer fake.deactivateFlavorAtPositionMutex.RUnlock()
 	fake.getMutex.RLock()
 	defer fake.getMutex.RUnlock()
+	fake.getLayoutMutex.RLock()
+	defer fake.getLayoutMutex.RUnlock()
 	fake.insertMutex.RLock()
 	defer fake.insertMutex.RUnlock()
 	fake.listMutex.RLock()
 	defer fake.listMutex.RUnlock()
+	fake.listLineupsMutex.RLock()
+	defer fake.listLineupsMutex.RUnlock()
+	fake.listRotationsMutex.RLock()
+	defer fake.listRotationsMutex.RUnlock()
+	fake.replaceLineupMutex.RLock()
+	defer fake.replaceLineupMutex.RUnlock()
+	fake.saveLayoutMutex.RLock()
+	defer fake.saveLayoutMutex.RUnlock()
 	fake.updateMutex.RLock()
 	defer fake.updateMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
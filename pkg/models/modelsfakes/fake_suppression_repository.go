@@ -0,0 +1,417 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package modelsfakes
+
+import (
+	"sync"
+
+	"github.com/jcorry/morellis/pkg/models"
+)
+
+type FakeSuppressionRepository struct {
+	CountStub        func() int
+	countMutex       sync.RWMutex
+	countArgsForCall []struct {
+	}
+	countReturns struct {
+		result1 int
+	}
+	countReturnsOnCall map[int]struct {
+		result1 int
+	}
+	IsSuppressedStub        func(string) (bool, error)
+	isSuppressedMutex       sync.RWMutex
+	isSuppressedArgsForCall []struct {
+		arg1 string
+	}
+	isSuppressedReturns struct {
+		result1 bool
+		result2 error
+	}
+	isSuppressedReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
+	ListStub        func(int, int) ([]*models.Suppression, error)
+	listMutex       sync.RWMutex
+	listArgsForCall []struct {
+		arg1 int
+		arg2 int
+	}
+	listReturns struct {
+		result1 []*models.Suppression
+		result2 error
+	}
+	listReturnsOnCall map[int]struct {
+		result1 []*models.Suppression
+		result2 error
+	}
+	SuppressStub        func(string, string) (*models.Suppression, error)
+	suppressMutex       sync.RWMutex
+	suppressArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	suppressReturns struct {
+		result1 *models.Suppression
+		result2 error
+	}
+	suppressReturnsOnCall map[int]struct {
+		result1 *models.Suppression
+		result2 error
+	}
+	UnsuppressStub        func(string) error
+	unsuppressMutex       sync.RWMutex
+	unsuppressArgsForCall []struct {
+		arg1 string
+	}
+	unsuppressReturns struct {
+		result1 error
+	}
+	unsuppressReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeSuppressionRepository) Count() int {
+	fake.countMutex.Lock()
+	ret, specificReturn := fake.countReturnsOnCall[len(fake.countArgsForCall)]
+	fake.countArgsForCall = append(fake.countArgsForCall, struct {
+	}{})
+	stub := fake.CountStub
+	fakeReturns := fake.countReturns
+	fake.recordInvocation("Count", []interface{}{})
+	fake.countMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeSuppressionRepository) CountCallCount() int {
+	fake.countMutex.RLock()
+	defer fake.countMutex.RUnlock()
+	return len(fake.countArgsForCall)
+}
+
+func (fake *FakeSuppressionRepository) CountCalls(stub func() int) {
+	fake.countMutex.Lock()
+	defer fake.countMutex.Unlock()
+	fake.CountStub = stub
+}
+
+func (fake *FakeSuppressionRepository) CountReturns(result1 int) {
+	fake.countMutex.Lock()
+	defer fake.countMutex.Unlock()
+	fake.CountStub = nil
+	fake.countReturns = struct {
+		result1 int
+	}{result1}
+}
+
+func (fake *FakeSuppressionRepository) CountReturnsOnCall(i int, result1 int) {
+	fake.countMutex.Lock()
+	defer fake.countMutex.Unlock()
+	fake.CountStub = nil
+	if fake.countReturnsOnCall == nil {
+		fake.countReturnsOnCall = make(map[int]struct {
+			result1 int
+		})
+	}
+	fake.countReturnsOnCall[i] = struct {
+		result1 int
+	}{result1}
+}
+
+func (fake *FakeSuppressionRepository) IsSuppressed(arg1 string) (bool, error) {
+	fake.isSuppressedMutex.Lock()
+	ret, specificReturn := fake.isSuppressedReturnsOnCall[len(fake.isSuppressedArgsForCall)]
+	fake.isSuppressedArgsForCall = append(fake.isSuppressedArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.IsSuppressedStub
+	fakeReturns := fake.isSuppressedReturns
+	fake.recordInvocation("IsSuppressed", []interface{}{arg1})
+	fake.isSuppressedMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeSuppressionRepository) IsSuppressedCallCount() int {
+	fake.isSuppressedMutex.RLock()
+	defer fake.isSuppressedMutex.RUnlock()
+	return len(fake.isSuppressedArgsForCall)
+}
+
+func (fake *FakeSuppressionRepository) IsSuppressedCalls(stub func(string) (bool, error)) {
+	fake.isSuppressedMutex.Lock()
+	defer fake.isSuppressedMutex.Unlock()
+	fake.IsSuppressedStub = stub
+}
+
+func (fake *FakeSuppressionRepository) IsSuppressedArgsForCall(i int) string {
+	fake.isSuppressedMutex.RLock()
+	defer fake.isSuppressedMutex.RUnlock()
+	argsForCall := fake.isSuppressedArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeSuppressionRepository) IsSuppressedReturns(result1 bool, result2 error) {
+	fake.isSuppressedMutex.Lock()
+	defer fake.isSuppressedMutex.Unlock()
+	fake.IsSuppressedStub = nil
+	fake.isSuppressedReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSuppressionRepository) IsSuppressedReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.isSuppressedMutex.Lock()
+	defer fake.isSuppressedMutex.Unlock()
+	fake.IsSuppressedStub = nil
+	if fake.isSuppressedReturnsOnCall == nil {
+		fake.isSuppressedReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.isSuppressedReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSuppressionRepository) List(arg1 int, arg2 int) ([]*models.Suppression, error) {
+	fake.listMutex.Lock()
+	ret, specificReturn := fake.listReturnsOnCall[len(fake.listArgsForCall)]
+	fake.listArgsForCall = append(fake.listArgsForCall, struct {
+		arg1 int
+		arg2 int
+	}{arg1, arg2})
+	stub := fake.ListStub
+	fakeReturns := fake.listReturns
+	fake.recordInvocation("List", []interface{}{arg1, arg2})
+	fake.listMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeSuppressionRepository) ListCallCount() int {
+	fake.listMutex.RLock()
+	defer fake.listMutex.RUnlock()
+	return len(fake.listArgsForCall)
+}
+
+func (fake *FakeSuppressionRepository) ListCalls(stub func(int, int) ([]*models.Suppression, error)) {
+	fake.listMutex.Lock()
+	defer fake.listMutex.Unlock()
+	fake.ListStub = stub
+}
+
+func (fake *FakeSuppressionRepository) ListArgsForCall(i int) (int, int) {
+	fake.listMutex.RLock()
+	defer fake.listMutex.RUnlock()
+	argsForCall := fake.listArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeSuppressionRepository) ListReturns(result1 []*models.Suppression, result2 error) {
+	fake.listMutex.Lock()
+	defer fake.listMutex.Unlock()
+	fake.ListStub = nil
+	fake.listReturns = struct {
+		result1 []*models.Suppression
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSuppressionRepository) ListReturnsOnCall(i int, result1 []*models.Suppression, result2 error) {
+	fake.listMutex.Lock()
+	defer fake.listMutex.Unlock()
+	fake.ListStub = nil
+	if fake.listReturnsOnCall == nil {
+		fake.listReturnsOnCall = make(map[int]struct {
+			result1 []*models.Suppression
+			result2 error
+		})
+	}
+	fake.listReturnsOnCall[i] = struct {
+		result1 []*models.Suppression
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSuppressionRepository) Suppress(arg1 string, arg2 string) (*models.Suppression, error) {
+	fake.suppressMutex.Lock()
+	ret, specificReturn := fake.suppressReturnsOnCall[len(fake.suppressArgsForCall)]
+	fake.suppressArgsForCall = append(fake.suppressArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.SuppressStub
+	fakeReturns := fake.suppressReturns
+	fake.recordInvocation("Suppress", []interface{}{arg1, arg2})
+	fake.suppressMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeSuppressionRepository) SuppressCallCount() int {
+	fake.suppressMutex.RLock()
+	defer fake.suppressMutex.RUnlock()
+	return len(fake.suppressArgsForCall)
+}
+
+func (fake *FakeSuppressionRepository) SuppressCalls(stub func(string, string) (*models.Suppression, error)) {
+	fake.suppressMutex.Lock()
+	defer fake.suppressMutex.Unlock()
+	fake.SuppressStub = stub
+}
+
+func (fake *FakeSuppressionRepository) SuppressArgsForCall(i int) (string, string) {
+	fake.suppressMutex.RLock()
+	defer fake.suppressMutex.RUnlock()
+	argsForCall := fake.suppressArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeSuppressionRepository) SuppressReturns(result1 *models.Suppression, result2 error) {
+	fake.suppressMutex.Lock()
+	defer fake.suppressMutex.Unlock()
+	fake.SuppressStub = nil
+	fake.suppressReturns = struct {
+		result1 *models.Suppression
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSuppressionRepository) SuppressReturnsOnCall(i int, result1 *models.Suppression, result2 error) {
+	fake.suppressMutex.Lock()
+	defer fake.suppressMutex.Unlock()
+	fake.SuppressStub = nil
+	if fake.suppressReturnsOnCall == nil {
+		fake.suppressReturnsOnCall = make(map[int]struct {
+			result1 *models.Suppression
+			result2 error
+		})
+	}
+	fake.suppressReturnsOnCall[i] = struct {
+		result1 *models.Suppression
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSuppressionRepository) Unsuppress(arg1 string) error {
+	fake.unsuppressMutex.Lock()
+	ret, specificReturn := fake.unsuppressReturnsOnCall[len(fake.unsuppressArgsForCall)]
+	fake.unsuppressArgsForCall = append(fake.unsuppressArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.UnsuppressStub
+	fakeReturns := fake.unsuppressReturns
+	fake.recordInvocation("Unsuppress", []interface{}{arg1})
+	fake.unsuppressMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeSuppressionRepository) UnsuppressCallCount() int {
+	fake.unsuppressMutex.RLock()
+	defer fake.unsuppressMutex.RUnlock()
+	return len(fake.unsuppressArgsForCall)
+}
+
+func (fake *FakeSuppressionRepository) UnsuppressCalls(stub func(string) error) {
+	fake.unsuppressMutex.Lock()
+	defer fake.unsuppressMutex.Unlock()
+	fake.UnsuppressStub = stub
+}
+
+func (fake *FakeSuppressionRepository) UnsuppressArgsForCall(i int) string {
+	fake.unsuppressMutex.RLock()
+	defer fake.unsuppressMutex.RUnlock()
+	argsForCall := fake.unsuppressArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeSuppressionRepository) UnsuppressReturns(result1 error) {
+	fake.unsuppressMutex.Lock()
+	defer fake.unsuppressMutex.Unlock()
+	fake.UnsuppressStub = nil
+	fake.unsuppressReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSuppressionRepository) UnsuppressReturnsOnCall(i int, result1 error) {
+	fake.unsuppressMutex.Lock()
+	defer fake.unsuppressMutex.Unlock()
+	fake.UnsuppressStub = nil
+	if fake.unsuppressReturnsOnCall == nil {
+		fake.unsuppressReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.unsuppressReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSuppressionRepository) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.countMutex.RLock()
+	defer fake.countMutex.RUnlock()
+	fake.isSuppressedMutex.RLock()
+	defer fake.isSuppressedMutex.RUnlock()
+	fake.listMutex.RLock()
+	defer fake.listMutex.RUnlock()
+	fake.suppressMutex.RLock()
+	defer fake.suppressMutex.RUnlock()
+	fake.unsuppressMutex.RLock()
+	defer fake.unsuppressMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeSuppressionRepository) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ models.SuppressionRepository = new(FakeSuppressionRepository)
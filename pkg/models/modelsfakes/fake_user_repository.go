@@ -3,18 +3,34 @@ package modelsfakes
 
 import (
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jcorry/morellis/pkg/models"
 )
 
 type FakeUserRepository struct {
-	AddIngredientStub        func(int64, *models.Ingredient, string) (*models.UserIngredient, error)
+	AddFlavorStub        func(int64, *models.Flavor) (*models.UserFlavor, error)
+	addFlavorMutex       sync.RWMutex
+	addFlavorArgsForCall []struct {
+		arg1 int64
+		arg2 *models.Flavor
+	}
+	addFlavorReturns struct {
+		result1 *models.UserFlavor
+		result2 error
+	}
+	addFlavorReturnsOnCall map[int]struct {
+		result1 *models.UserFlavor
+		result2 error
+	}
+	AddIngredientStub        func(int64, *models.Ingredient, string, models.SubscriptionScope) (*models.UserIngredient, error)
 	addIngredientMutex       sync.RWMutex
 	addIngredientArgsForCall []struct {
 		arg1 int64
 		arg2 *models.Ingredient
 		arg3 string
+		arg4 models.SubscriptionScope
 	}
 	addIngredientReturns struct {
 		result1 *models.UserIngredient
@@ -38,6 +54,88 @@ type FakeUserRepository struct {
 		result1 int
 		result2 error
 	}
+	AddRoleStub        func(int64, string, int64) (*models.UserRole, error)
+	addRoleMutex       sync.RWMutex
+	addRoleArgsForCall []struct {
+		arg1 int64
+		arg2 string
+		arg3 int64
+	}
+	addRoleReturns struct {
+		result1 *models.UserRole
+		result2 error
+	}
+	addRoleReturnsOnCall map[int]struct {
+		result1 *models.UserRole
+		result2 error
+	}
+	ConsumeAuthCodeStub        func(int64, string, int) (bool, error)
+	consumeAuthCodeMutex       sync.RWMutex
+	consumeAuthCodeArgsForCall []struct {
+		arg1 int64
+		arg2 string
+		arg3 int
+	}
+	consumeAuthCodeReturns struct {
+		result1 bool
+		result2 error
+	}
+	consumeAuthCodeReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
+	ConsumeAuthTokenStub        func(string) (*models.User, error)
+	consumeAuthTokenMutex       sync.RWMutex
+	consumeAuthTokenArgsForCall []struct {
+		arg1 string
+	}
+	consumeAuthTokenReturns struct {
+		result1 *models.User
+		result2 error
+	}
+	consumeAuthTokenReturnsOnCall map[int]struct {
+		result1 *models.User
+		result2 error
+	}
+	ConsumePasswordResetTokenStub        func(string) (*models.User, error)
+	consumePasswordResetTokenMutex       sync.RWMutex
+	consumePasswordResetTokenArgsForCall []struct {
+		arg1 string
+	}
+	consumePasswordResetTokenReturns struct {
+		result1 *models.User
+		result2 error
+	}
+	consumePasswordResetTokenReturnsOnCall map[int]struct {
+		result1 *models.User
+		result2 error
+	}
+	ConsumeRefreshTokenStub        func(string) (*models.User, error)
+	consumeRefreshTokenMutex       sync.RWMutex
+	consumeRefreshTokenArgsForCall []struct {
+		arg1 string
+	}
+	consumeRefreshTokenReturns struct {
+		result1 *models.User
+		result2 error
+	}
+	consumeRefreshTokenReturnsOnCall map[int]struct {
+		result1 *models.User
+		result2 error
+	}
+	ConsumeVerificationTokenStub        func(string) (*models.User, error)
+	consumeVerificationTokenMutex       sync.RWMutex
+	consumeVerificationTokenArgsForCall []struct {
+		arg1 string
+	}
+	consumeVerificationTokenReturns struct {
+		result1 *models.User
+		result2 error
+	}
+	consumeVerificationTokenReturnsOnCall map[int]struct {
+		result1 *models.User
+		result2 error
+	}
 	CountStub        func() int
 	countMutex       sync.RWMutex
 	countArgsForCall []struct {
@@ -48,6 +146,20 @@ type FakeUserRepository struct {
 	countReturnsOnCall map[int]struct {
 		result1 int
 	}
+	CountAuthRequestStub        func(string, time.Duration) (int64, error)
+	countAuthRequestMutex       sync.RWMutex
+	countAuthRequestArgsForCall []struct {
+		arg1 string
+		arg2 time.Duration
+	}
+	countAuthRequestReturns struct {
+		result1 int64
+		result2 error
+	}
+	countAuthRequestReturnsOnCall map[int]struct {
+		result1 int64
+		result2 error
+	}
 	DeleteStub        func(int) (bool, error)
 	deleteMutex       sync.RWMutex
 	deleteArgsForCall []struct {
@@ -74,29 +186,29 @@ type FakeUserRepository struct {
 		result1 *models.User
 		result2 error
 	}
-	GetByAuthTokenStub        func(string) (*models.User, error)
-	getByAuthTokenMutex       sync.RWMutex
-	getByAuthTokenArgsForCall []struct {
-		arg1 string
+	GetByCredentialsStub        func(models.Credentials) (*models.User, error)
+	getByCredentialsMutex       sync.RWMutex
+	getByCredentialsArgsForCall []struct {
+		arg1 models.Credentials
 	}
-	getByAuthTokenReturns struct {
+	getByCredentialsReturns struct {
 		result1 *models.User
 		result2 error
 	}
-	getByAuthTokenReturnsOnCall map[int]struct {
+	getByCredentialsReturnsOnCall map[int]struct {
 		result1 *models.User
 		result2 error
 	}
-	GetByCredentialsStub        func(models.Credentials) (*models.User, error)
-	getByCredentialsMutex       sync.RWMutex
-	getByCredentialsArgsForCall []struct {
-		arg1 models.Credentials
+	GetByEmailStub        func(string) (*models.User, error)
+	getByEmailMutex       sync.RWMutex
+	getByEmailArgsForCall []struct {
+		arg1 string
 	}
-	getByCredentialsReturns struct {
+	getByEmailReturns struct {
 		result1 *models.User
 		result2 error
 	}
-	getByCredentialsReturnsOnCall map[int]struct {
+	getByEmailReturnsOnCall map[int]struct {
 		result1 *models.User
 		result2 error
 	}
@@ -126,6 +238,32 @@ type FakeUserRepository struct {
 		result1 *models.User
 		result2 error
 	}
+	GetFlavorSubscribersStub        func(int64) ([]*models.User, error)
+	getFlavorSubscribersMutex       sync.RWMutex
+	getFlavorSubscribersArgsForCall []struct {
+		arg1 int64
+	}
+	getFlavorSubscribersReturns struct {
+		result1 []*models.User
+		result2 error
+	}
+	getFlavorSubscribersReturnsOnCall map[int]struct {
+		result1 []*models.User
+		result2 error
+	}
+	GetFlavorsStub        func(int64) ([]*models.UserFlavor, error)
+	getFlavorsMutex       sync.RWMutex
+	getFlavorsArgsForCall []struct {
+		arg1 int64
+	}
+	getFlavorsReturns struct {
+		result1 []*models.UserFlavor
+		result2 error
+	}
+	getFlavorsReturnsOnCall map[int]struct {
+		result1 []*models.UserFlavor
+		result2 error
+	}
 	GetIngredientsStub        func(int64) ([]*models.UserIngredient, error)
 	getIngredientsMutex       sync.RWMutex
 	getIngredientsArgsForCall []struct {
@@ -152,6 +290,45 @@ type FakeUserRepository struct {
 		result1 []models.UserPermission
 		result2 error
 	}
+	GetRolesStub        func(int64) ([]models.UserRole, error)
+	getRolesMutex       sync.RWMutex
+	getRolesArgsForCall []struct {
+		arg1 int64
+	}
+	getRolesReturns struct {
+		result1 []models.UserRole
+		result2 error
+	}
+	getRolesReturnsOnCall map[int]struct {
+		result1 []models.UserRole
+		result2 error
+	}
+	GetStoreStaffStub        func(int64) ([]*models.User, error)
+	getStoreStaffMutex       sync.RWMutex
+	getStoreStaffArgsForCall []struct {
+		arg1 int64
+	}
+	getStoreStaffReturns struct {
+		result1 []*models.User
+		result2 error
+	}
+	getStoreStaffReturnsOnCall map[int]struct {
+		result1 []*models.User
+		result2 error
+	}
+	GetSubscribersStub        func([]int64) ([]*models.User, error)
+	getSubscribersMutex       sync.RWMutex
+	getSubscribersArgsForCall []struct {
+		arg1 []int64
+	}
+	getSubscribersReturns struct {
+		result1 []*models.User
+		result2 error
+	}
+	getSubscribersReturnsOnCall map[int]struct {
+		result1 []*models.User
+		result2 error
+	}
 	InsertStub        func(uuid.UUID, models.NullString, models.NullString, models.NullString, string, int, string) (*models.User, error)
 	insertMutex       sync.RWMutex
 	insertArgsForCall []struct {
@@ -171,6 +348,19 @@ type FakeUserRepository struct {
 		result1 *models.User
 		result2 error
 	}
+	IsTokenRevokedStub        func(string) (bool, error)
+	isTokenRevokedMutex       sync.RWMutex
+	isTokenRevokedArgsForCall []struct {
+		arg1 string
+	}
+	isTokenRevokedReturns struct {
+		result1 bool
+		result2 error
+	}
+	isTokenRevokedReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
 	ListStub        func(int, int, string) ([]*models.User, error)
 	listMutex       sync.RWMutex
 	listArgsForCall []struct {
@@ -210,6 +400,28 @@ type FakeUserRepository struct {
 		result1 bool
 		result2 error
 	}
+	RemoveRoleStub        func(int64) error
+	removeRoleMutex       sync.RWMutex
+	removeRoleArgsForCall []struct {
+		arg1 int64
+	}
+	removeRoleReturns struct {
+		result1 error
+	}
+	removeRoleReturnsOnCall map[int]struct {
+		result1 error
+	}
+	RemoveUserFlavorStub        func(int64) error
+	removeUserFlavorMutex       sync.RWMutex
+	removeUserFlavorArgsForCall []struct {
+		arg1 int64
+	}
+	removeUserFlavorReturns struct {
+		result1 error
+	}
+	removeUserFlavorReturnsOnCall map[int]struct {
+		result1 error
+	}
 	RemoveUserIngredientStub        func(int64) error
 	removeUserIngredientMutex       sync.RWMutex
 	removeUserIngredientArgsForCall []struct {
@@ -221,6 +433,31 @@ type FakeUserRepository struct {
 	removeUserIngredientReturnsOnCall map[int]struct {
 		result1 error
 	}
+	RevokeTokenStub        func(string, time.Duration) error
+	revokeTokenMutex       sync.RWMutex
+	revokeTokenArgsForCall []struct {
+		arg1 string
+		arg2 time.Duration
+	}
+	revokeTokenReturns struct {
+		result1 error
+	}
+	revokeTokenReturnsOnCall map[int]struct {
+		result1 error
+	}
+	SaveAuthCodeStub        func(int64, string, time.Duration) error
+	saveAuthCodeMutex       sync.RWMutex
+	saveAuthCodeArgsForCall []struct {
+		arg1 int64
+		arg2 string
+		arg3 time.Duration
+	}
+	saveAuthCodeReturns struct {
+		result1 error
+	}
+	saveAuthCodeReturnsOnCall map[int]struct {
+		result1 error
+	}
 	SaveAuthTokenStub        func(string, int) error
 	saveAuthTokenMutex       sync.RWMutex
 	saveAuthTokenArgsForCall []struct {
@@ -233,6 +470,45 @@ type FakeUserRepository struct {
 	saveAuthTokenReturnsOnCall map[int]struct {
 		result1 error
 	}
+	SavePasswordResetTokenStub        func(string, int64, time.Duration) error
+	savePasswordResetTokenMutex       sync.RWMutex
+	savePasswordResetTokenArgsForCall []struct {
+		arg1 string
+		arg2 int64
+		arg3 time.Duration
+	}
+	savePasswordResetTokenReturns struct {
+		result1 error
+	}
+	savePasswordResetTokenReturnsOnCall map[int]struct {
+		result1 error
+	}
+	SaveRefreshTokenStub        func(string, int64, time.Duration) error
+	saveRefreshTokenMutex       sync.RWMutex
+	saveRefreshTokenArgsForCall []struct {
+		arg1 string
+		arg2 int64
+		arg3 time.Duration
+	}
+	saveRefreshTokenReturns struct {
+		result1 error
+	}
+	saveRefreshTokenReturnsOnCall map[int]struct {
+		result1 error
+	}
+	SaveVerificationTokenStub        func(string, int64, time.Duration) error
+	saveVerificationTokenMutex       sync.RWMutex
+	saveVerificationTokenArgsForCall []struct {
+		arg1 string
+		arg2 int64
+		arg3 time.Duration
+	}
+	saveVerificationTokenReturns struct {
+		result1 error
+	}
+	saveVerificationTokenReturnsOnCall map[int]struct {
+		result1 error
+	}
 	UpdateStub        func(*models.User) (*models.User, error)
 	updateMutex       sync.RWMutex
 	updateArgsForCall []struct {
@@ -246,27 +522,106 @@ type FakeUserRepository struct {
 		result1 *models.User
 		result2 error
 	}
+	UpdatePasswordStub        func(int64, string) error
+	updatePasswordMutex       sync.RWMutex
+	updatePasswordArgsForCall []struct {
+		arg1 int64
+		arg2 string
+	}
+	updatePasswordReturns struct {
+		result1 error
+	}
+	updatePasswordReturnsOnCall map[int]struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeUserRepository) AddIngredient(arg1 int64, arg2 *models.Ingredient, arg3 string) (*models.UserIngredient, error) {
+func (fake *FakeUserRepository) AddFlavor(arg1 int64, arg2 *models.Flavor) (*models.UserFlavor, error) {
+	fake.addFlavorMutex.Lock()
+	ret, specificReturn := fake.addFlavorReturnsOnCall[len(fake.addFlavorArgsForCall)]
+	fake.addFlavorArgsForCall = append(fake.addFlavorArgsForCall, struct {
+		arg1 int64
+		arg2 *models.Flavor
+	}{arg1, arg2})
+	stub := fake.AddFlavorStub
+	fakeReturns := fake.addFlavorReturns
+	fake.recordInvocation("AddFlavor", []interface{}{arg1, arg2})
+	fake.addFlavorMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeUserRepository) AddFlavorCallCount() int {
+	fake.addFlavorMutex.RLock()
+	defer fake.addFlavorMutex.RUnlock()
+	return len(fake.addFlavorArgsForCall)
+}
+
+func (fake *FakeUserRepository) AddFlavorCalls(stub func(int64, *models.Flavor) (*models.UserFlavor, error)) {
+	fake.addFlavorMutex.Lock()
+	defer fake.addFlavorMutex.Unlock()
+	fake.AddFlavorStub = stub
+}
+
+func (fake *FakeUserRepository) AddFlavorArgsForCall(i int) (int64, *models.Flavor) {
+	fake.addFlavorMutex.RLock()
+	defer fake.addFlavorMutex.RUnlock()
+	argsForCall := fake.addFlavorArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeUserRepository) AddFlavorReturns(result1 *models.UserFlavor, result2 error) {
+	fake.addFlavorMutex.Lock()
+	defer fake.addFlavorMutex.Unlock()
+	fake.AddFlavorStub = nil
+	fake.addFlavorReturns = struct {
+		result1 *models.UserFlavor
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) AddFlavorReturnsOnCall(i int, result1 *models.UserFlavor, result2 error) {
+	fake.addFlavorMutex.Lock()
+	defer fake.addFlavorMutex.Unlock()
+	fake.AddFlavorStub = nil
+	if fake.addFlavorReturnsOnCall == nil {
+		fake.addFlavorReturnsOnCall = make(map[int]struct {
+			result1 *models.UserFlavor
+			result2 error
+		})
+	}
+	fake.addFlavorReturnsOnCall[i] = struct {
+		result1 *models.UserFlavor
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) AddIngredient(arg1 int64, arg2 *models.Ingredient, arg3 string, arg4 models.SubscriptionScope) (*models.UserIngredient, error) {
 	fake.addIngredientMutex.Lock()
 	ret, specificReturn := fake.addIngredientReturnsOnCall[len(fake.addIngredientArgsForCall)]
 	fake.addIngredientArgsForCall = append(fake.addIngredientArgsForCall, struct {
 		arg1 int64
 		arg2 *models.Ingredient
 		arg3 string
-	}{arg1, arg2, arg3})
-	fake.recordInvocation("AddIngredient", []interface{}{arg1, arg2, arg3})
+		arg4 models.SubscriptionScope
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.AddIngredientStub
+	fakeReturns := fake.addIngredientReturns
+	fake.recordInvocation("AddIngredient", []interface{}{arg1, arg2, arg3, arg4})
 	fake.addIngredientMutex.Unlock()
-	if fake.AddIngredientStub != nil {
-		return fake.AddIngredientStub(arg1, arg2, arg3)
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.addIngredientReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -276,17 +631,17 @@ func (fake *FakeUserRepository) AddIngredientCallCount() int {
 	return len(fake.addIngredientArgsForCall)
 }
 
-func (fake *FakeUserRepository) AddIngredientCalls(stub func(int64, *models.Ingredient, string) (*models.UserIngredient, error)) {
+func (fake *FakeUserRepository) AddIngredientCalls(stub func(int64, *models.Ingredient, string, models.SubscriptionScope) (*models.UserIngredient, error)) {
 	fake.addIngredientMutex.Lock()
 	defer fake.addIngredientMutex.Unlock()
 	fake.AddIngredientStub = stub
 }
 
-func (fake *FakeUserRepository) AddIngredientArgsForCall(i int) (int64, *models.Ingredient, string) {
+func (fake *FakeUserRepository) AddIngredientArgsForCall(i int) (int64, *models.Ingredient, string, models.SubscriptionScope) {
 	fake.addIngredientMutex.RLock()
 	defer fake.addIngredientMutex.RUnlock()
 	argsForCall := fake.addIngredientArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
 }
 
 func (fake *FakeUserRepository) AddIngredientReturns(result1 *models.UserIngredient, result2 error) {
@@ -322,15 +677,16 @@ func (fake *FakeUserRepository) AddPermission(arg1 int, arg2 models.Permission)
 		arg1 int
 		arg2 models.Permission
 	}{arg1, arg2})
+	stub := fake.AddPermissionStub
+	fakeReturns := fake.addPermissionReturns
 	fake.recordInvocation("AddPermission", []interface{}{arg1, arg2})
 	fake.addPermissionMutex.Unlock()
-	if fake.AddPermissionStub != nil {
-		return fake.AddPermissionStub(arg1, arg2)
+	if stub != nil {
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.addPermissionReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -379,78 +735,533 @@ func (fake *FakeUserRepository) AddPermissionReturnsOnCall(i int, result1 int, r
 	}{result1, result2}
 }
 
-func (fake *FakeUserRepository) Count() int {
-	fake.countMutex.Lock()
-	ret, specificReturn := fake.countReturnsOnCall[len(fake.countArgsForCall)]
-	fake.countArgsForCall = append(fake.countArgsForCall, struct {
-	}{})
-	fake.recordInvocation("Count", []interface{}{})
-	fake.countMutex.Unlock()
-	if fake.CountStub != nil {
-		return fake.CountStub()
+func (fake *FakeUserRepository) AddRole(arg1 int64, arg2 string, arg3 int64) (*models.UserRole, error) {
+	fake.addRoleMutex.Lock()
+	ret, specificReturn := fake.addRoleReturnsOnCall[len(fake.addRoleArgsForCall)]
+	fake.addRoleArgsForCall = append(fake.addRoleArgsForCall, struct {
+		arg1 int64
+		arg2 string
+		arg3 int64
+	}{arg1, arg2, arg3})
+	stub := fake.AddRoleStub
+	fakeReturns := fake.addRoleReturns
+	fake.recordInvocation("AddRole", []interface{}{arg1, arg2, arg3})
+	fake.addRoleMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
 	}
 	if specificReturn {
-		return ret.result1
+		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.countReturns
-	return fakeReturns.result1
+	return fakeReturns.result1, fakeReturns.result2
 }
 
-func (fake *FakeUserRepository) CountCallCount() int {
-	fake.countMutex.RLock()
-	defer fake.countMutex.RUnlock()
-	return len(fake.countArgsForCall)
+func (fake *FakeUserRepository) AddRoleCallCount() int {
+	fake.addRoleMutex.RLock()
+	defer fake.addRoleMutex.RUnlock()
+	return len(fake.addRoleArgsForCall)
 }
 
-func (fake *FakeUserRepository) CountCalls(stub func() int) {
-	fake.countMutex.Lock()
-	defer fake.countMutex.Unlock()
-	fake.CountStub = stub
+func (fake *FakeUserRepository) AddRoleCalls(stub func(int64, string, int64) (*models.UserRole, error)) {
+	fake.addRoleMutex.Lock()
+	defer fake.addRoleMutex.Unlock()
+	fake.AddRoleStub = stub
 }
 
-func (fake *FakeUserRepository) CountReturns(result1 int) {
-	fake.countMutex.Lock()
-	defer fake.countMutex.Unlock()
-	fake.CountStub = nil
-	fake.countReturns = struct {
-		result1 int
-	}{result1}
+func (fake *FakeUserRepository) AddRoleArgsForCall(i int) (int64, string, int64) {
+	fake.addRoleMutex.RLock()
+	defer fake.addRoleMutex.RUnlock()
+	argsForCall := fake.addRoleArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
 }
 
-func (fake *FakeUserRepository) CountReturnsOnCall(i int, result1 int) {
-	fake.countMutex.Lock()
-	defer fake.countMutex.Unlock()
-	fake.CountStub = nil
-	if fake.countReturnsOnCall == nil {
-		fake.countReturnsOnCall = make(map[int]struct {
-			result1 int
+func (fake *FakeUserRepository) AddRoleReturns(result1 *models.UserRole, result2 error) {
+	fake.addRoleMutex.Lock()
+	defer fake.addRoleMutex.Unlock()
+	fake.AddRoleStub = nil
+	fake.addRoleReturns = struct {
+		result1 *models.UserRole
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) AddRoleReturnsOnCall(i int, result1 *models.UserRole, result2 error) {
+	fake.addRoleMutex.Lock()
+	defer fake.addRoleMutex.Unlock()
+	fake.AddRoleStub = nil
+	if fake.addRoleReturnsOnCall == nil {
+		fake.addRoleReturnsOnCall = make(map[int]struct {
+			result1 *models.UserRole
+			result2 error
 		})
 	}
-	fake.countReturnsOnCall[i] = struct {
-		result1 int
-	}{result1}
+	fake.addRoleReturnsOnCall[i] = struct {
+		result1 *models.UserRole
+		result2 error
+	}{result1, result2}
 }
 
-func (fake *FakeUserRepository) Delete(arg1 int) (bool, error) {
-	fake.deleteMutex.Lock()
-	ret, specificReturn := fake.deleteReturnsOnCall[len(fake.deleteArgsForCall)]
-	fake.deleteArgsForCall = append(fake.deleteArgsForCall, struct {
-		arg1 int
-	}{arg1})
-	fake.recordInvocation("Delete", []interface{}{arg1})
-	fake.deleteMutex.Unlock()
-	if fake.DeleteStub != nil {
-		return fake.DeleteStub(arg1)
+func (fake *FakeUserRepository) ConsumeAuthCode(arg1 int64, arg2 string, arg3 int) (bool, error) {
+	fake.consumeAuthCodeMutex.Lock()
+	ret, specificReturn := fake.consumeAuthCodeReturnsOnCall[len(fake.consumeAuthCodeArgsForCall)]
+	fake.consumeAuthCodeArgsForCall = append(fake.consumeAuthCodeArgsForCall, struct {
+		arg1 int64
+		arg2 string
+		arg3 int
+	}{arg1, arg2, arg3})
+	stub := fake.ConsumeAuthCodeStub
+	fakeReturns := fake.consumeAuthCodeReturns
+	fake.recordInvocation("ConsumeAuthCode", []interface{}{arg1, arg2, arg3})
+	fake.consumeAuthCodeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.deleteReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
-func (fake *FakeUserRepository) DeleteCallCount() int {
-	fake.deleteMutex.RLock()
+func (fake *FakeUserRepository) ConsumeAuthCodeCallCount() int {
+	fake.consumeAuthCodeMutex.RLock()
+	defer fake.consumeAuthCodeMutex.RUnlock()
+	return len(fake.consumeAuthCodeArgsForCall)
+}
+
+func (fake *FakeUserRepository) ConsumeAuthCodeCalls(stub func(int64, string, int) (bool, error)) {
+	fake.consumeAuthCodeMutex.Lock()
+	defer fake.consumeAuthCodeMutex.Unlock()
+	fake.ConsumeAuthCodeStub = stub
+}
+
+func (fake *FakeUserRepository) ConsumeAuthCodeArgsForCall(i int) (int64, string, int) {
+	fake.consumeAuthCodeMutex.RLock()
+	defer fake.consumeAuthCodeMutex.RUnlock()
+	argsForCall := fake.consumeAuthCodeArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeUserRepository) ConsumeAuthCodeReturns(result1 bool, result2 error) {
+	fake.consumeAuthCodeMutex.Lock()
+	defer fake.consumeAuthCodeMutex.Unlock()
+	fake.ConsumeAuthCodeStub = nil
+	fake.consumeAuthCodeReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) ConsumeAuthCodeReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.consumeAuthCodeMutex.Lock()
+	defer fake.consumeAuthCodeMutex.Unlock()
+	fake.ConsumeAuthCodeStub = nil
+	if fake.consumeAuthCodeReturnsOnCall == nil {
+		fake.consumeAuthCodeReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.consumeAuthCodeReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) ConsumeAuthToken(arg1 string) (*models.User, error) {
+	fake.consumeAuthTokenMutex.Lock()
+	ret, specificReturn := fake.consumeAuthTokenReturnsOnCall[len(fake.consumeAuthTokenArgsForCall)]
+	fake.consumeAuthTokenArgsForCall = append(fake.consumeAuthTokenArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ConsumeAuthTokenStub
+	fakeReturns := fake.consumeAuthTokenReturns
+	fake.recordInvocation("ConsumeAuthToken", []interface{}{arg1})
+	fake.consumeAuthTokenMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeUserRepository) ConsumeAuthTokenCallCount() int {
+	fake.consumeAuthTokenMutex.RLock()
+	defer fake.consumeAuthTokenMutex.RUnlock()
+	return len(fake.consumeAuthTokenArgsForCall)
+}
+
+func (fake *FakeUserRepository) ConsumeAuthTokenCalls(stub func(string) (*models.User, error)) {
+	fake.consumeAuthTokenMutex.Lock()
+	defer fake.consumeAuthTokenMutex.Unlock()
+	fake.ConsumeAuthTokenStub = stub
+}
+
+func (fake *FakeUserRepository) ConsumeAuthTokenArgsForCall(i int) string {
+	fake.consumeAuthTokenMutex.RLock()
+	defer fake.consumeAuthTokenMutex.RUnlock()
+	argsForCall := fake.consumeAuthTokenArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeUserRepository) ConsumeAuthTokenReturns(result1 *models.User, result2 error) {
+	fake.consumeAuthTokenMutex.Lock()
+	defer fake.consumeAuthTokenMutex.Unlock()
+	fake.ConsumeAuthTokenStub = nil
+	fake.consumeAuthTokenReturns = struct {
+		result1 *models.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) ConsumeAuthTokenReturnsOnCall(i int, result1 *models.User, result2 error) {
+	fake.consumeAuthTokenMutex.Lock()
+	defer fake.consumeAuthTokenMutex.Unlock()
+	fake.ConsumeAuthTokenStub = nil
+	if fake.consumeAuthTokenReturnsOnCall == nil {
+		fake.consumeAuthTokenReturnsOnCall = make(map[int]struct {
+			result1 *models.User
+			result2 error
+		})
+	}
+	fake.consumeAuthTokenReturnsOnCall[i] = struct {
+		result1 *models.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) ConsumePasswordResetToken(arg1 string) (*models.User, error) {
+	fake.consumePasswordResetTokenMutex.Lock()
+	ret, specificReturn := fake.consumePasswordResetTokenReturnsOnCall[len(fake.consumePasswordResetTokenArgsForCall)]
+	fake.consumePasswordResetTokenArgsForCall = append(fake.consumePasswordResetTokenArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ConsumePasswordResetTokenStub
+	fakeReturns := fake.consumePasswordResetTokenReturns
+	fake.recordInvocation("ConsumePasswordResetToken", []interface{}{arg1})
+	fake.consumePasswordResetTokenMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeUserRepository) ConsumePasswordResetTokenCallCount() int {
+	fake.consumePasswordResetTokenMutex.RLock()
+	defer fake.consumePasswordResetTokenMutex.RUnlock()
+	return len(fake.consumePasswordResetTokenArgsForCall)
+}
+
+func (fake *FakeUserRepository) ConsumePasswordResetTokenCalls(stub func(string) (*models.User, error)) {
+	fake.consumePasswordResetTokenMutex.Lock()
+	defer fake.consumePasswordResetTokenMutex.Unlock()
+	fake.ConsumePasswordResetTokenStub = stub
+}
+
+func (fake *FakeUserRepository) ConsumePasswordResetTokenArgsForCall(i int) string {
+	fake.consumePasswordResetTokenMutex.RLock()
+	defer fake.consumePasswordResetTokenMutex.RUnlock()
+	argsForCall := fake.consumePasswordResetTokenArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeUserRepository) ConsumePasswordResetTokenReturns(result1 *models.User, result2 error) {
+	fake.consumePasswordResetTokenMutex.Lock()
+	defer fake.consumePasswordResetTokenMutex.Unlock()
+	fake.ConsumePasswordResetTokenStub = nil
+	fake.consumePasswordResetTokenReturns = struct {
+		result1 *models.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) ConsumePasswordResetTokenReturnsOnCall(i int, result1 *models.User, result2 error) {
+	fake.consumePasswordResetTokenMutex.Lock()
+	defer fake.consumePasswordResetTokenMutex.Unlock()
+	fake.ConsumePasswordResetTokenStub = nil
+	if fake.consumePasswordResetTokenReturnsOnCall == nil {
+		fake.consumePasswordResetTokenReturnsOnCall = make(map[int]struct {
+			result1 *models.User
+			result2 error
+		})
+	}
+	fake.consumePasswordResetTokenReturnsOnCall[i] = struct {
+		result1 *models.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) ConsumeRefreshToken(arg1 string) (*models.User, error) {
+	fake.consumeRefreshTokenMutex.Lock()
+	ret, specificReturn := fake.consumeRefreshTokenReturnsOnCall[len(fake.consumeRefreshTokenArgsForCall)]
+	fake.consumeRefreshTokenArgsForCall = append(fake.consumeRefreshTokenArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ConsumeRefreshTokenStub
+	fakeReturns := fake.consumeRefreshTokenReturns
+	fake.recordInvocation("ConsumeRefreshToken", []interface{}{arg1})
+	fake.consumeRefreshTokenMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeUserRepository) ConsumeRefreshTokenCallCount() int {
+	fake.consumeRefreshTokenMutex.RLock()
+	defer fake.consumeRefreshTokenMutex.RUnlock()
+	return len(fake.consumeRefreshTokenArgsForCall)
+}
+
+func (fake *FakeUserRepository) ConsumeRefreshTokenCalls(stub func(string) (*models.User, error)) {
+	fake.consumeRefreshTokenMutex.Lock()
+	defer fake.consumeRefreshTokenMutex.Unlock()
+	fake.ConsumeRefreshTokenStub = stub
+}
+
+func (fake *FakeUserRepository) ConsumeRefreshTokenArgsForCall(i int) string {
+	fake.consumeRefreshTokenMutex.RLock()
+	defer fake.consumeRefreshTokenMutex.RUnlock()
+	argsForCall := fake.consumeRefreshTokenArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeUserRepository) ConsumeRefreshTokenReturns(result1 *models.User, result2 error) {
+	fake.consumeRefreshTokenMutex.Lock()
+	defer fake.consumeRefreshTokenMutex.Unlock()
+	fake.ConsumeRefreshTokenStub = nil
+	fake.consumeRefreshTokenReturns = struct {
+		result1 *models.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) ConsumeRefreshTokenReturnsOnCall(i int, result1 *models.User, result2 error) {
+	fake.consumeRefreshTokenMutex.Lock()
+	defer fake.consumeRefreshTokenMutex.Unlock()
+	fake.ConsumeRefreshTokenStub = nil
+	if fake.consumeRefreshTokenReturnsOnCall == nil {
+		fake.consumeRefreshTokenReturnsOnCall = make(map[int]struct {
+			result1 *models.User
+			result2 error
+		})
+	}
+	fake.consumeRefreshTokenReturnsOnCall[i] = struct {
+		result1 *models.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) ConsumeVerificationToken(arg1 string) (*models.User, error) {
+	fake.consumeVerificationTokenMutex.Lock()
+	ret, specificReturn := fake.consumeVerificationTokenReturnsOnCall[len(fake.consumeVerificationTokenArgsForCall)]
+	fake.consumeVerificationTokenArgsForCall = append(fake.consumeVerificationTokenArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ConsumeVerificationTokenStub
+	fakeReturns := fake.consumeVerificationTokenReturns
+	fake.recordInvocation("ConsumeVerificationToken", []interface{}{arg1})
+	fake.consumeVerificationTokenMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeUserRepository) ConsumeVerificationTokenCallCount() int {
+	fake.consumeVerificationTokenMutex.RLock()
+	defer fake.consumeVerificationTokenMutex.RUnlock()
+	return len(fake.consumeVerificationTokenArgsForCall)
+}
+
+func (fake *FakeUserRepository) ConsumeVerificationTokenCalls(stub func(string) (*models.User, error)) {
+	fake.consumeVerificationTokenMutex.Lock()
+	defer fake.consumeVerificationTokenMutex.Unlock()
+	fake.ConsumeVerificationTokenStub = stub
+}
+
+func (fake *FakeUserRepository) ConsumeVerificationTokenArgsForCall(i int) string {
+	fake.consumeVerificationTokenMutex.RLock()
+	defer fake.consumeVerificationTokenMutex.RUnlock()
+	argsForCall := fake.consumeVerificationTokenArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeUserRepository) ConsumeVerificationTokenReturns(result1 *models.User, result2 error) {
+	fake.consumeVerificationTokenMutex.Lock()
+	defer fake.consumeVerificationTokenMutex.Unlock()
+	fake.ConsumeVerificationTokenStub = nil
+	fake.consumeVerificationTokenReturns = struct {
+		result1 *models.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) ConsumeVerificationTokenReturnsOnCall(i int, result1 *models.User, result2 error) {
+	fake.consumeVerificationTokenMutex.Lock()
+	defer fake.consumeVerificationTokenMutex.Unlock()
+	fake.ConsumeVerificationTokenStub = nil
+	if fake.consumeVerificationTokenReturnsOnCall == nil {
+		fake.consumeVerificationTokenReturnsOnCall = make(map[int]struct {
+			result1 *models.User
+			result2 error
+		})
+	}
+	fake.consumeVerificationTokenReturnsOnCall[i] = struct {
+		result1 *models.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) Count() int {
+	fake.countMutex.Lock()
+	ret, specificReturn := fake.countReturnsOnCall[len(fake.countArgsForCall)]
+	fake.countArgsForCall = append(fake.countArgsForCall, struct {
+	}{})
+	stub := fake.CountStub
+	fakeReturns := fake.countReturns
+	fake.recordInvocation("Count", []interface{}{})
+	fake.countMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeUserRepository) CountCallCount() int {
+	fake.countMutex.RLock()
+	defer fake.countMutex.RUnlock()
+	return len(fake.countArgsForCall)
+}
+
+func (fake *FakeUserRepository) CountCalls(stub func() int) {
+	fake.countMutex.Lock()
+	defer fake.countMutex.Unlock()
+	fake.CountStub = stub
+}
+
+func (fake *FakeUserRepository) CountReturns(result1 int) {
+	fake.countMutex.Lock()
+	defer fake.countMutex.Unlock()
+	fake.CountStub = nil
+	fake.countReturns = struct {
+		result1 int
+	}{result1}
+}
+
+func (fake *FakeUserRepository) CountReturnsOnCall(i int, result1 int) {
+	fake.countMutex.Lock()
+	defer fake.countMutex.Unlock()
+	fake.CountStub = nil
+	if fake.countReturnsOnCall == nil {
+		fake.countReturnsOnCall = make(map[int]struct {
+			result1 int
+		})
+	}
+	fake.countReturnsOnCall[i] = struct {
+		result1 int
+	}{result1}
+}
+
+func (fake *FakeUserRepository) CountAuthRequest(arg1 string, arg2 time.Duration) (int64, error) {
+	fake.countAuthRequestMutex.Lock()
+	ret, specificReturn := fake.countAuthRequestReturnsOnCall[len(fake.countAuthRequestArgsForCall)]
+	fake.countAuthRequestArgsForCall = append(fake.countAuthRequestArgsForCall, struct {
+		arg1 string
+		arg2 time.Duration
+	}{arg1, arg2})
+	stub := fake.CountAuthRequestStub
+	fakeReturns := fake.countAuthRequestReturns
+	fake.recordInvocation("CountAuthRequest", []interface{}{arg1, arg2})
+	fake.countAuthRequestMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeUserRepository) CountAuthRequestCallCount() int {
+	fake.countAuthRequestMutex.RLock()
+	defer fake.countAuthRequestMutex.RUnlock()
+	return len(fake.countAuthRequestArgsForCall)
+}
+
+func (fake *FakeUserRepository) CountAuthRequestCalls(stub func(string, time.Duration) (int64, error)) {
+	fake.countAuthRequestMutex.Lock()
+	defer fake.countAuthRequestMutex.Unlock()
+	fake.CountAuthRequestStub = stub
+}
+
+func (fake *FakeUserRepository) CountAuthRequestArgsForCall(i int) (string, time.Duration) {
+	fake.countAuthRequestMutex.RLock()
+	defer fake.countAuthRequestMutex.RUnlock()
+	argsForCall := fake.countAuthRequestArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeUserRepository) CountAuthRequestReturns(result1 int64, result2 error) {
+	fake.countAuthRequestMutex.Lock()
+	defer fake.countAuthRequestMutex.Unlock()
+	fake.CountAuthRequestStub = nil
+	fake.countAuthRequestReturns = struct {
+		result1 int64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) CountAuthRequestReturnsOnCall(i int, result1 int64, result2 error) {
+	fake.countAuthRequestMutex.Lock()
+	defer fake.countAuthRequestMutex.Unlock()
+	fake.CountAuthRequestStub = nil
+	if fake.countAuthRequestReturnsOnCall == nil {
+		fake.countAuthRequestReturnsOnCall = make(map[int]struct {
+			result1 int64
+			result2 error
+		})
+	}
+	fake.countAuthRequestReturnsOnCall[i] = struct {
+		result1 int64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) Delete(arg1 int) (bool, error) {
+	fake.deleteMutex.Lock()
+	ret, specificReturn := fake.deleteReturnsOnCall[len(fake.deleteArgsForCall)]
+	fake.deleteArgsForCall = append(fake.deleteArgsForCall, struct {
+		arg1 int
+	}{arg1})
+	stub := fake.DeleteStub
+	fakeReturns := fake.deleteReturns
+	fake.recordInvocation("Delete", []interface{}{arg1})
+	fake.deleteMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeUserRepository) DeleteCallCount() int {
+	fake.deleteMutex.RLock()
 	defer fake.deleteMutex.RUnlock()
 	return len(fake.deleteArgsForCall)
 }
@@ -500,15 +1311,16 @@ func (fake *FakeUserRepository) Get(arg1 int) (*models.User, error) {
 	fake.getArgsForCall = append(fake.getArgsForCall, struct {
 		arg1 int
 	}{arg1})
+	stub := fake.GetStub
+	fakeReturns := fake.getReturns
 	fake.recordInvocation("Get", []interface{}{arg1})
 	fake.getMutex.Unlock()
-	if fake.GetStub != nil {
-		return fake.GetStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.getReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -557,84 +1369,22 @@ func (fake *FakeUserRepository) GetReturnsOnCall(i int, result1 *models.User, re
 	}{result1, result2}
 }
 
-func (fake *FakeUserRepository) GetByAuthToken(arg1 string) (*models.User, error) {
-	fake.getByAuthTokenMutex.Lock()
-	ret, specificReturn := fake.getByAuthTokenReturnsOnCall[len(fake.getByAuthTokenArgsForCall)]
-	fake.getByAuthTokenArgsForCall = append(fake.getByAuthTokenArgsForCall, struct {
-		arg1 string
-	}{arg1})
-	fake.recordInvocation("GetByAuthToken", []interface{}{arg1})
-	fake.getByAuthTokenMutex.Unlock()
-	if fake.GetByAuthTokenStub != nil {
-		return fake.GetByAuthTokenStub(arg1)
-	}
-	if specificReturn {
-		return ret.result1, ret.result2
-	}
-	fakeReturns := fake.getByAuthTokenReturns
-	return fakeReturns.result1, fakeReturns.result2
-}
-
-func (fake *FakeUserRepository) GetByAuthTokenCallCount() int {
-	fake.getByAuthTokenMutex.RLock()
-	defer fake.getByAuthTokenMutex.RUnlock()
-	return len(fake.getByAuthTokenArgsForCall)
-}
-
-func (fake *FakeUserRepository) GetByAuthTokenCalls(stub func(string) (*models.User, error)) {
-	fake.getByAuthTokenMutex.Lock()
-	defer fake.getByAuthTokenMutex.Unlock()
-	fake.GetByAuthTokenStub = stub
-}
-
-func (fake *FakeUserRepository) GetByAuthTokenArgsForCall(i int) string {
-	fake.getByAuthTokenMutex.RLock()
-	defer fake.getByAuthTokenMutex.RUnlock()
-	argsForCall := fake.getByAuthTokenArgsForCall[i]
-	return argsForCall.arg1
-}
-
-func (fake *FakeUserRepository) GetByAuthTokenReturns(result1 *models.User, result2 error) {
-	fake.getByAuthTokenMutex.Lock()
-	defer fake.getByAuthTokenMutex.Unlock()
-	fake.GetByAuthTokenStub = nil
-	fake.getByAuthTokenReturns = struct {
-		result1 *models.User
-		result2 error
-	}{result1, result2}
-}
-
-func (fake *FakeUserRepository) GetByAuthTokenReturnsOnCall(i int, result1 *models.User, result2 error) {
-	fake.getByAuthTokenMutex.Lock()
-	defer fake.getByAuthTokenMutex.Unlock()
-	fake.GetByAuthTokenStub = nil
-	if fake.getByAuthTokenReturnsOnCall == nil {
-		fake.getByAuthTokenReturnsOnCall = make(map[int]struct {
-			result1 *models.User
-			result2 error
-		})
-	}
-	fake.getByAuthTokenReturnsOnCall[i] = struct {
-		result1 *models.User
-		result2 error
-	}{result1, result2}
-}
-
 func (fake *FakeUserRepository) GetByCredentials(arg1 models.Credentials) (*models.User, error) {
 	fake.getByCredentialsMutex.Lock()
 	ret, specificReturn := fake.getByCredentialsReturnsOnCall[len(fake.getByCredentialsArgsForCall)]
 	fake.getByCredentialsArgsForCall = append(fake.getByCredentialsArgsForCall, struct {
 		arg1 models.Credentials
 	}{arg1})
+	stub := fake.GetByCredentialsStub
+	fakeReturns := fake.getByCredentialsReturns
 	fake.recordInvocation("GetByCredentials", []interface{}{arg1})
 	fake.getByCredentialsMutex.Unlock()
-	if fake.GetByCredentialsStub != nil {
-		return fake.GetByCredentialsStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.getByCredentialsReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -683,21 +1433,86 @@ func (fake *FakeUserRepository) GetByCredentialsReturnsOnCall(i int, result1 *mo
 	}{result1, result2}
 }
 
+func (fake *FakeUserRepository) GetByEmail(arg1 string) (*models.User, error) {
+	fake.getByEmailMutex.Lock()
+	ret, specificReturn := fake.getByEmailReturnsOnCall[len(fake.getByEmailArgsForCall)]
+	fake.getByEmailArgsForCall = append(fake.getByEmailArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.GetByEmailStub
+	fakeReturns := fake.getByEmailReturns
+	fake.recordInvocation("GetByEmail", []interface{}{arg1})
+	fake.getByEmailMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeUserRepository) GetByEmailCallCount() int {
+	fake.getByEmailMutex.RLock()
+	defer fake.getByEmailMutex.RUnlock()
+	return len(fake.getByEmailArgsForCall)
+}
+
+func (fake *FakeUserRepository) GetByEmailCalls(stub func(string) (*models.User, error)) {
+	fake.getByEmailMutex.Lock()
+	defer fake.getByEmailMutex.Unlock()
+	fake.GetByEmailStub = stub
+}
+
+func (fake *FakeUserRepository) GetByEmailArgsForCall(i int) string {
+	fake.getByEmailMutex.RLock()
+	defer fake.getByEmailMutex.RUnlock()
+	argsForCall := fake.getByEmailArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeUserRepository) GetByEmailReturns(result1 *models.User, result2 error) {
+	fake.getByEmailMutex.Lock()
+	defer fake.getByEmailMutex.Unlock()
+	fake.GetByEmailStub = nil
+	fake.getByEmailReturns = struct {
+		result1 *models.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) GetByEmailReturnsOnCall(i int, result1 *models.User, result2 error) {
+	fake.getByEmailMutex.Lock()
+	defer fake.getByEmailMutex.Unlock()
+	fake.GetByEmailStub = nil
+	if fake.getByEmailReturnsOnCall == nil {
+		fake.getByEmailReturnsOnCall = make(map[int]struct {
+			result1 *models.User
+			result2 error
+		})
+	}
+	fake.getByEmailReturnsOnCall[i] = struct {
+		result1 *models.User
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeUserRepository) GetByPhone(arg1 string) (*models.User, error) {
 	fake.getByPhoneMutex.Lock()
 	ret, specificReturn := fake.getByPhoneReturnsOnCall[len(fake.getByPhoneArgsForCall)]
 	fake.getByPhoneArgsForCall = append(fake.getByPhoneArgsForCall, struct {
 		arg1 string
 	}{arg1})
+	stub := fake.GetByPhoneStub
+	fakeReturns := fake.getByPhoneReturns
 	fake.recordInvocation("GetByPhone", []interface{}{arg1})
 	fake.getByPhoneMutex.Unlock()
-	if fake.GetByPhoneStub != nil {
-		return fake.GetByPhoneStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.getByPhoneReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -752,15 +1567,16 @@ func (fake *FakeUserRepository) GetByUUID(arg1 uuid.UUID) (*models.User, error)
 	fake.getByUUIDArgsForCall = append(fake.getByUUIDArgsForCall, struct {
 		arg1 uuid.UUID
 	}{arg1})
+	stub := fake.GetByUUIDStub
+	fakeReturns := fake.getByUUIDReturns
 	fake.recordInvocation("GetByUUID", []interface{}{arg1})
 	fake.getByUUIDMutex.Unlock()
-	if fake.GetByUUIDStub != nil {
-		return fake.GetByUUIDStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.getByUUIDReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -809,21 +1625,150 @@ func (fake *FakeUserRepository) GetByUUIDReturnsOnCall(i int, result1 *models.Us
 	}{result1, result2}
 }
 
+func (fake *FakeUserRepository) GetFlavorSubscribers(arg1 int64) ([]*models.User, error) {
+	fake.getFlavorSubscribersMutex.Lock()
+	ret, specificReturn := fake.getFlavorSubscribersReturnsOnCall[len(fake.getFlavorSubscribersArgsForCall)]
+	fake.getFlavorSubscribersArgsForCall = append(fake.getFlavorSubscribersArgsForCall, struct {
+		arg1 int64
+	}{arg1})
+	stub := fake.GetFlavorSubscribersStub
+	fakeReturns := fake.getFlavorSubscribersReturns
+	fake.recordInvocation("GetFlavorSubscribers", []interface{}{arg1})
+	fake.getFlavorSubscribersMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeUserRepository) GetFlavorSubscribersCallCount() int {
+	fake.getFlavorSubscribersMutex.RLock()
+	defer fake.getFlavorSubscribersMutex.RUnlock()
+	return len(fake.getFlavorSubscribersArgsForCall)
+}
+
+func (fake *FakeUserRepository) GetFlavorSubscribersCalls(stub func(int64) ([]*models.User, error)) {
+	fake.getFlavorSubscribersMutex.Lock()
+	defer fake.getFlavorSubscribersMutex.Unlock()
+	fake.GetFlavorSubscribersStub = stub
+}
+
+func (fake *FakeUserRepository) GetFlavorSubscribersArgsForCall(i int) int64 {
+	fake.getFlavorSubscribersMutex.RLock()
+	defer fake.getFlavorSubscribersMutex.RUnlock()
+	argsForCall := fake.getFlavorSubscribersArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeUserRepository) GetFlavorSubscribersReturns(result1 []*models.User, result2 error) {
+	fake.getFlavorSubscribersMutex.Lock()
+	defer fake.getFlavorSubscribersMutex.Unlock()
+	fake.GetFlavorSubscribersStub = nil
+	fake.getFlavorSubscribersReturns = struct {
+		result1 []*models.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) GetFlavorSubscribersReturnsOnCall(i int, result1 []*models.User, result2 error) {
+	fake.getFlavorSubscribersMutex.Lock()
+	defer fake.getFlavorSubscribersMutex.Unlock()
+	fake.GetFlavorSubscribersStub = nil
+	if fake.getFlavorSubscribersReturnsOnCall == nil {
+		fake.getFlavorSubscribersReturnsOnCall = make(map[int]struct {
+			result1 []*models.User
+			result2 error
+		})
+	}
+	fake.getFlavorSubscribersReturnsOnCall[i] = struct {
+		result1 []*models.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) GetFlavors(arg1 int64) ([]*models.UserFlavor, error) {
+	fake.getFlavorsMutex.Lock()
+	ret, specificReturn := fake.getFlavorsReturnsOnCall[len(fake.getFlavorsArgsForCall)]
+	fake.getFlavorsArgsForCall = append(fake.getFlavorsArgsForCall, struct {
+		arg1 int64
+	}{arg1})
+	stub := fake.GetFlavorsStub
+	fakeReturns := fake.getFlavorsReturns
+	fake.recordInvocation("GetFlavors", []interface{}{arg1})
+	fake.getFlavorsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeUserRepository) GetFlavorsCallCount() int {
+	fake.getFlavorsMutex.RLock()
+	defer fake.getFlavorsMutex.RUnlock()
+	return len(fake.getFlavorsArgsForCall)
+}
+
+func (fake *FakeUserRepository) GetFlavorsCalls(stub func(int64) ([]*models.UserFlavor, error)) {
+	fake.getFlavorsMutex.Lock()
+	defer fake.getFlavorsMutex.Unlock()
+	fake.GetFlavorsStub = stub
+}
+
+func (fake *FakeUserRepository) GetFlavorsArgsForCall(i int) int64 {
+	fake.getFlavorsMutex.RLock()
+	defer fake.getFlavorsMutex.RUnlock()
+	argsForCall := fake.getFlavorsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeUserRepository) GetFlavorsReturns(result1 []*models.UserFlavor, result2 error) {
+	fake.getFlavorsMutex.Lock()
+	defer fake.getFlavorsMutex.Unlock()
+	fake.GetFlavorsStub = nil
+	fake.getFlavorsReturns = struct {
+		result1 []*models.UserFlavor
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) GetFlavorsReturnsOnCall(i int, result1 []*models.UserFlavor, result2 error) {
+	fake.getFlavorsMutex.Lock()
+	defer fake.getFlavorsMutex.Unlock()
+	fake.GetFlavorsStub = nil
+	if fake.getFlavorsReturnsOnCall == nil {
+		fake.getFlavorsReturnsOnCall = make(map[int]struct {
+			result1 []*models.UserFlavor
+			result2 error
+		})
+	}
+	fake.getFlavorsReturnsOnCall[i] = struct {
+		result1 []*models.UserFlavor
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeUserRepository) GetIngredients(arg1 int64) ([]*models.UserIngredient, error) {
 	fake.getIngredientsMutex.Lock()
 	ret, specificReturn := fake.getIngredientsReturnsOnCall[len(fake.getIngredientsArgsForCall)]
 	fake.getIngredientsArgsForCall = append(fake.getIngredientsArgsForCall, struct {
 		arg1 int64
 	}{arg1})
+	stub := fake.GetIngredientsStub
+	fakeReturns := fake.getIngredientsReturns
 	fake.recordInvocation("GetIngredients", []interface{}{arg1})
 	fake.getIngredientsMutex.Unlock()
-	if fake.GetIngredientsStub != nil {
-		return fake.GetIngredientsStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.getIngredientsReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -878,15 +1823,16 @@ func (fake *FakeUserRepository) GetPermissions(arg1 int) ([]models.UserPermissio
 	fake.getPermissionsArgsForCall = append(fake.getPermissionsArgsForCall, struct {
 		arg1 int
 	}{arg1})
+	stub := fake.GetPermissionsStub
+	fakeReturns := fake.getPermissionsReturns
 	fake.recordInvocation("GetPermissions", []interface{}{arg1})
 	fake.getPermissionsMutex.Unlock()
-	if fake.GetPermissionsStub != nil {
-		return fake.GetPermissionsStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.getPermissionsReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -935,6 +1881,203 @@ func (fake *FakeUserRepository) GetPermissionsReturnsOnCall(i int, result1 []mod
 	}{result1, result2}
 }
 
+func (fake *FakeUserRepository) GetRoles(arg1 int64) ([]models.UserRole, error) {
+	fake.getRolesMutex.Lock()
+	ret, specificReturn := fake.getRolesReturnsOnCall[len(fake.getRolesArgsForCall)]
+	fake.getRolesArgsForCall = append(fake.getRolesArgsForCall, struct {
+		arg1 int64
+	}{arg1})
+	stub := fake.GetRolesStub
+	fakeReturns := fake.getRolesReturns
+	fake.recordInvocation("GetRoles", []interface{}{arg1})
+	fake.getRolesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeUserRepository) GetRolesCallCount() int {
+	fake.getRolesMutex.RLock()
+	defer fake.getRolesMutex.RUnlock()
+	return len(fake.getRolesArgsForCall)
+}
+
+func (fake *FakeUserRepository) GetRolesCalls(stub func(int64) ([]models.UserRole, error)) {
+	fake.getRolesMutex.Lock()
+	defer fake.getRolesMutex.Unlock()
+	fake.GetRolesStub = stub
+}
+
+func (fake *FakeUserRepository) GetRolesArgsForCall(i int) int64 {
+	fake.getRolesMutex.RLock()
+	defer fake.getRolesMutex.RUnlock()
+	argsForCall := fake.getRolesArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeUserRepository) GetRolesReturns(result1 []models.UserRole, result2 error) {
+	fake.getRolesMutex.Lock()
+	defer fake.getRolesMutex.Unlock()
+	fake.GetRolesStub = nil
+	fake.getRolesReturns = struct {
+		result1 []models.UserRole
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) GetRolesReturnsOnCall(i int, result1 []models.UserRole, result2 error) {
+	fake.getRolesMutex.Lock()
+	defer fake.getRolesMutex.Unlock()
+	fake.GetRolesStub = nil
+	if fake.getRolesReturnsOnCall == nil {
+		fake.getRolesReturnsOnCall = make(map[int]struct {
+			result1 []models.UserRole
+			result2 error
+		})
+	}
+	fake.getRolesReturnsOnCall[i] = struct {
+		result1 []models.UserRole
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) GetStoreStaff(arg1 int64) ([]*models.User, error) {
+	fake.getStoreStaffMutex.Lock()
+	ret, specificReturn := fake.getStoreStaffReturnsOnCall[len(fake.getStoreStaffArgsForCall)]
+	fake.getStoreStaffArgsForCall = append(fake.getStoreStaffArgsForCall, struct {
+		arg1 int64
+	}{arg1})
+	stub := fake.GetStoreStaffStub
+	fakeReturns := fake.getStoreStaffReturns
+	fake.recordInvocation("GetStoreStaff", []interface{}{arg1})
+	fake.getStoreStaffMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeUserRepository) GetStoreStaffCallCount() int {
+	fake.getStoreStaffMutex.RLock()
+	defer fake.getStoreStaffMutex.RUnlock()
+	return len(fake.getStoreStaffArgsForCall)
+}
+
+func (fake *FakeUserRepository) GetStoreStaffCalls(stub func(int64) ([]*models.User, error)) {
+	fake.getStoreStaffMutex.Lock()
+	defer fake.getStoreStaffMutex.Unlock()
+	fake.GetStoreStaffStub = stub
+}
+
+func (fake *FakeUserRepository) GetStoreStaffArgsForCall(i int) int64 {
+	fake.getStoreStaffMutex.RLock()
+	defer fake.getStoreStaffMutex.RUnlock()
+	argsForCall := fake.getStoreStaffArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeUserRepository) GetStoreStaffReturns(result1 []*models.User, result2 error) {
+	fake.getStoreStaffMutex.Lock()
+	defer fake.getStoreStaffMutex.Unlock()
+	fake.GetStoreStaffStub = nil
+	fake.getStoreStaffReturns = struct {
+		result1 []*models.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) GetStoreStaffReturnsOnCall(i int, result1 []*models.User, result2 error) {
+	fake.getStoreStaffMutex.Lock()
+	defer fake.getStoreStaffMutex.Unlock()
+	fake.GetStoreStaffStub = nil
+	if fake.getStoreStaffReturnsOnCall == nil {
+		fake.getStoreStaffReturnsOnCall = make(map[int]struct {
+			result1 []*models.User
+			result2 error
+		})
+	}
+	fake.getStoreStaffReturnsOnCall[i] = struct {
+		result1 []*models.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) GetSubscribers(arg1 []int64) ([]*models.User, error) {
+	var arg1Copy []int64
+	if arg1 != nil {
+		arg1Copy = make([]int64, len(arg1))
+		copy(arg1Copy, arg1)
+	}
+	fake.getSubscribersMutex.Lock()
+	ret, specificReturn := fake.getSubscribersReturnsOnCall[len(fake.getSubscribersArgsForCall)]
+	fake.getSubscribersArgsForCall = append(fake.getSubscribersArgsForCall, struct {
+		arg1 []int64
+	}{arg1Copy})
+	stub := fake.GetSubscribersStub
+	fakeReturns := fake.getSubscribersReturns
+	fake.recordInvocation("GetSubscribers", []interface{}{arg1Copy})
+	fake.getSubscribersMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeUserRepository) GetSubscribersCallCount() int {
+	fake.getSubscribersMutex.RLock()
+	defer fake.getSubscribersMutex.RUnlock()
+	return len(fake.getSubscribersArgsForCall)
+}
+
+func (fake *FakeUserRepository) GetSubscribersCalls(stub func([]int64) ([]*models.User, error)) {
+	fake.getSubscribersMutex.Lock()
+	defer fake.getSubscribersMutex.Unlock()
+	fake.GetSubscribersStub = stub
+}
+
+func (fake *FakeUserRepository) GetSubscribersArgsForCall(i int) []int64 {
+	fake.getSubscribersMutex.RLock()
+	defer fake.getSubscribersMutex.RUnlock()
+	argsForCall := fake.getSubscribersArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeUserRepository) GetSubscribersReturns(result1 []*models.User, result2 error) {
+	fake.getSubscribersMutex.Lock()
+	defer fake.getSubscribersMutex.Unlock()
+	fake.GetSubscribersStub = nil
+	fake.getSubscribersReturns = struct {
+		result1 []*models.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) GetSubscribersReturnsOnCall(i int, result1 []*models.User, result2 error) {
+	fake.getSubscribersMutex.Lock()
+	defer fake.getSubscribersMutex.Unlock()
+	fake.GetSubscribersStub = nil
+	if fake.getSubscribersReturnsOnCall == nil {
+		fake.getSubscribersReturnsOnCall = make(map[int]struct {
+			result1 []*models.User
+			result2 error
+		})
+	}
+	fake.getSubscribersReturnsOnCall[i] = struct {
+		result1 []*models.User
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeUserRepository) Insert(arg1 uuid.UUID, arg2 models.NullString, arg3 models.NullString, arg4 models.NullString, arg5 string, arg6 int, arg7 string) (*models.User, error) {
 	fake.insertMutex.Lock()
 	ret, specificReturn := fake.insertReturnsOnCall[len(fake.insertArgsForCall)]
@@ -947,15 +2090,16 @@ func (fake *FakeUserRepository) Insert(arg1 uuid.UUID, arg2 models.NullString, a
 		arg6 int
 		arg7 string
 	}{arg1, arg2, arg3, arg4, arg5, arg6, arg7})
+	stub := fake.InsertStub
+	fakeReturns := fake.insertReturns
 	fake.recordInvocation("Insert", []interface{}{arg1, arg2, arg3, arg4, arg5, arg6, arg7})
 	fake.insertMutex.Unlock()
-	if fake.InsertStub != nil {
-		return fake.InsertStub(arg1, arg2, arg3, arg4, arg5, arg6, arg7)
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5, arg6, arg7)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.insertReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -965,41 +2109,105 @@ func (fake *FakeUserRepository) InsertCallCount() int {
 	return len(fake.insertArgsForCall)
 }
 
-func (fake *FakeUserRepository) InsertCalls(stub func(uuid.UUID, models.NullString, models.NullString, models.NullString, string, int, string) (*models.User, error)) {
-	fake.insertMutex.Lock()
-	defer fake.insertMutex.Unlock()
-	fake.InsertStub = stub
+func (fake *FakeUserRepository) InsertCalls(stub func(uuid.UUID, models.NullString, models.NullString, models.NullString, string, int, string) (*models.User, error)) {
+	fake.insertMutex.Lock()
+	defer fake.insertMutex.Unlock()
+	fake.InsertStub = stub
+}
+
+func (fake *FakeUserRepository) InsertArgsForCall(i int) (uuid.UUID, models.NullString, models.NullString, models.NullString, string, int, string) {
+	fake.insertMutex.RLock()
+	defer fake.insertMutex.RUnlock()
+	argsForCall := fake.insertArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5, argsForCall.arg6, argsForCall.arg7
+}
+
+func (fake *FakeUserRepository) InsertReturns(result1 *models.User, result2 error) {
+	fake.insertMutex.Lock()
+	defer fake.insertMutex.Unlock()
+	fake.InsertStub = nil
+	fake.insertReturns = struct {
+		result1 *models.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) InsertReturnsOnCall(i int, result1 *models.User, result2 error) {
+	fake.insertMutex.Lock()
+	defer fake.insertMutex.Unlock()
+	fake.InsertStub = nil
+	if fake.insertReturnsOnCall == nil {
+		fake.insertReturnsOnCall = make(map[int]struct {
+			result1 *models.User
+			result2 error
+		})
+	}
+	fake.insertReturnsOnCall[i] = struct {
+		result1 *models.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeUserRepository) IsTokenRevoked(arg1 string) (bool, error) {
+	fake.isTokenRevokedMutex.Lock()
+	ret, specificReturn := fake.isTokenRevokedReturnsOnCall[len(fake.isTokenRevokedArgsForCall)]
+	fake.isTokenRevokedArgsForCall = append(fake.isTokenRevokedArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.IsTokenRevokedStub
+	fakeReturns := fake.isTokenRevokedReturns
+	fake.recordInvocation("IsTokenRevoked", []interface{}{arg1})
+	fake.isTokenRevokedMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeUserRepository) IsTokenRevokedCallCount() int {
+	fake.isTokenRevokedMutex.RLock()
+	defer fake.isTokenRevokedMutex.RUnlock()
+	return len(fake.isTokenRevokedArgsForCall)
+}
+
+func (fake *FakeUserRepository) IsTokenRevokedCalls(stub func(string) (bool, error)) {
+	fake.isTokenRevokedMutex.Lock()
+	defer fake.isTokenRevokedMutex.Unlock()
+	fake.IsTokenRevokedStub = stub
 }
 
-func (fake *FakeUserRepository) InsertArgsForCall(i int) (uuid.UUID, models.NullString, models.NullString, models.NullString, string, int, string) {
-	fake.insertMutex.RLock()
-	defer fake.insertMutex.RUnlock()
-	argsForCall := fake.insertArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5, argsForCall.arg6, argsForCall.arg7
+func (fake *FakeUserRepository) IsTokenRevokedArgsForCall(i int) string {
+	fake.isTokenRevokedMutex.RLock()
+	defer fake.isTokenRevokedMutex.RUnlock()
+	argsForCall := fake.isTokenRevokedArgsForCall[i]
+	return argsForCall.arg1
 }
 
-func (fake *FakeUserRepository) InsertReturns(result1 *models.User, result2 error) {
-	fake.insertMutex.Lock()
-	defer fake.insertMutex.Unlock()
-	fake.InsertStub = nil
-	fake.insertReturns = struct {
-		result1 *models.User
+func (fake *FakeUserRepository) IsTokenRevokedReturns(result1 bool, result2 error) {
+	fake.isTokenRevokedMutex.Lock()
+	defer fake.isTokenRevokedMutex.Unlock()
+	fake.IsTokenRevokedStub = nil
+	fake.isTokenRevokedReturns = struct {
+		result1 bool
 		result2 error
 	}{result1, result2}
 }
 
-func (fake *FakeUserRepository) InsertReturnsOnCall(i int, result1 *models.User, result2 error) {
-	fake.insertMutex.Lock()
-	defer fake.insertMutex.Unlock()
-	fake.InsertStub = nil
-	if fake.insertReturnsOnCall == nil {
-		fake.insertReturnsOnCall = make(map[int]struct {
-			result1 *models.User
+func (fake *FakeUserRepository) IsTokenRevokedReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.isTokenRevokedMutex.Lock()
+	defer fake.isTokenRevokedMutex.Unlock()
+	fake.IsTokenRevokedStub = nil
+	if fake.isTokenRevokedReturnsOnCall == nil {
+		fake.isTokenRevokedReturnsOnCall = make(map[int]struct {
+			result1 bool
 			result2 error
 		})
 	}
-	fake.insertReturnsOnCall[i] = struct {
-		result1 *models.User
+	fake.isTokenRevokedReturnsOnCall[i] = struct {
+		result1 bool
 		result2 error
 	}{result1, result2}
 }
@@ -1012,15 +2220,16 @@ func (fake *FakeUserRepository) List(arg1 int, arg2 int, arg3 string) ([]*models
 		arg2 int
 		arg3 string
 	}{arg1, arg2, arg3})
+	stub := fake.ListStub
+	fakeReturns := fake.listReturns
 	fake.recordInvocation("List", []interface{}{arg1, arg2, arg3})
 	fake.listMutex.Unlock()
-	if fake.ListStub != nil {
-		return fake.ListStub(arg1, arg2, arg3)
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.listReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -1075,15 +2284,16 @@ func (fake *FakeUserRepository) RemoveAllPermissions(arg1 int) error {
 	fake.removeAllPermissionsArgsForCall = append(fake.removeAllPermissionsArgsForCall, struct {
 		arg1 int
 	}{arg1})
+	stub := fake.RemoveAllPermissionsStub
+	fakeReturns := fake.removeAllPermissionsReturns
 	fake.recordInvocation("RemoveAllPermissions", []interface{}{arg1})
 	fake.removeAllPermissionsMutex.Unlock()
-	if fake.RemoveAllPermissionsStub != nil {
-		return fake.RemoveAllPermissionsStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.removeAllPermissionsReturns
 	return fakeReturns.result1
 }
 
@@ -1135,15 +2345,16 @@ func (fake *FakeUserRepository) RemovePermission(arg1 int) (bool, error) {
 	fake.removePermissionArgsForCall = append(fake.removePermissionArgsForCall, struct {
 		arg1 int
 	}{arg1})
+	stub := fake.RemovePermissionStub
+	fakeReturns := fake.removePermissionReturns
 	fake.recordInvocation("RemovePermission", []interface{}{arg1})
 	fake.removePermissionMutex.Unlock()
-	if fake.RemovePermissionStub != nil {
-		return fake.RemovePermissionStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.removePermissionReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -1192,21 +2403,144 @@ func (fake *FakeUserRepository) RemovePermissionReturnsOnCall(i int, result1 boo
 	}{result1, result2}
 }
 
+func (fake *FakeUserRepository) RemoveRole(arg1 int64) error {
+	fake.removeRoleMutex.Lock()
+	ret, specificReturn := fake.removeRoleReturnsOnCall[len(fake.removeRoleArgsForCall)]
+	fake.removeRoleArgsForCall = append(fake.removeRoleArgsForCall, struct {
+		arg1 int64
+	}{arg1})
+	stub := fake.RemoveRoleStub
+	fakeReturns := fake.removeRoleReturns
+	fake.recordInvocation("RemoveRole", []interface{}{arg1})
+	fake.removeRoleMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeUserRepository) RemoveRoleCallCount() int {
+	fake.removeRoleMutex.RLock()
+	defer fake.removeRoleMutex.RUnlock()
+	return len(fake.removeRoleArgsForCall)
+}
+
+func (fake *FakeUserRepository) RemoveRoleCalls(stub func(int64) error) {
+	fake.removeRoleMutex.Lock()
+	defer fake.removeRoleMutex.Unlock()
+	fake.RemoveRoleStub = stub
+}
+
+func (fake *FakeUserRepository) RemoveRoleArgsForCall(i int) int64 {
+	fake.removeRoleMutex.RLock()
+	defer fake.removeRoleMutex.RUnlock()
+	argsForCall := fake.removeRoleArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeUserRepository) RemoveRoleReturns(result1 error) {
+	fake.removeRoleMutex.Lock()
+	defer fake.removeRoleMutex.Unlock()
+	fake.RemoveRoleStub = nil
+	fake.removeRoleReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeUserRepository) RemoveRoleReturnsOnCall(i int, result1 error) {
+	fake.removeRoleMutex.Lock()
+	defer fake.removeRoleMutex.Unlock()
+	fake.RemoveRoleStub = nil
+	if fake.removeRoleReturnsOnCall == nil {
+		fake.removeRoleReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.removeRoleReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeUserRepository) RemoveUserFlavor(arg1 int64) error {
+	fake.removeUserFlavorMutex.Lock()
+	ret, specificReturn := fake.removeUserFlavorReturnsOnCall[len(fake.removeUserFlavorArgsForCall)]
+	fake.removeUserFlavorArgsForCall = append(fake.removeUserFlavorArgsForCall, struct {
+		arg1 int64
+	}{arg1})
+	stub := fake.RemoveUserFlavorStub
+	fakeReturns := fake.removeUserFlavorReturns
+	fake.recordInvocation("RemoveUserFlavor", []interface{}{arg1})
+	fake.removeUserFlavorMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeUserRepository) RemoveUserFlavorCallCount() int {
+	fake.removeUserFlavorMutex.RLock()
+	defer fake.removeUserFlavorMutex.RUnlock()
+	return len(fake.removeUserFlavorArgsForCall)
+}
+
+func (fake *FakeUserRepository) RemoveUserFlavorCalls(stub func(int64) error) {
+	fake.removeUserFlavorMutex.Lock()
+	defer fake.removeUserFlavorMutex.Unlock()
+	fake.RemoveUserFlavorStub = stub
+}
+
+func (fake *FakeUserRepository) RemoveUserFlavorArgsForCall(i int) int64 {
+	fake.removeUserFlavorMutex.RLock()
+	defer fake.removeUserFlavorMutex.RUnlock()
+	argsForCall := fake.removeUserFlavorArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeUserRepository) RemoveUserFlavorReturns(result1 error) {
+	fake.removeUserFlavorMutex.Lock()
+	defer fake.removeUserFlavorMutex.Unlock()
+	fake.RemoveUserFlavorStub = nil
+	fake.removeUserFlavorReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeUserRepository) RemoveUserFlavorReturnsOnCall(i int, result1 error) {
+	fake.removeUserFlavorMutex.Lock()
+	defer fake.removeUserFlavorMutex.Unlock()
+	fake.RemoveUserFlavorStub = nil
+	if fake.removeUserFlavorReturnsOnCall == nil {
+		fake.removeUserFlavorReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.removeUserFlavorReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeUserRepository) RemoveUserIngredient(arg1 int64) error {
 	fake.removeUserIngredientMutex.Lock()
 	ret, specificReturn := fake.removeUserIngredientReturnsOnCall[len(fake.removeUserIngredientArgsForCall)]
 	fake.removeUserIngredientArgsForCall = append(fake.removeUserIngredientArgsForCall, struct {
 		arg1 int64
 	}{arg1})
+	stub := fake.RemoveUserIngredientStub
+	fakeReturns := fake.removeUserIngredientReturns
 	fake.recordInvocation("RemoveUserIngredient", []interface{}{arg1})
 	fake.removeUserIngredientMutex.Unlock()
-	if fake.RemoveUserIngredientStub != nil {
-		return fake.RemoveUserIngredientStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.removeUserIngredientReturns
 	return fakeReturns.result1
 }
 
@@ -1252,6 +2586,131 @@ func (fake *FakeUserRepository) RemoveUserIngredientReturnsOnCall(i int, result1
 	}{result1}
 }
 
+func (fake *FakeUserRepository) RevokeToken(arg1 string, arg2 time.Duration) error {
+	fake.revokeTokenMutex.Lock()
+	ret, specificReturn := fake.revokeTokenReturnsOnCall[len(fake.revokeTokenArgsForCall)]
+	fake.revokeTokenArgsForCall = append(fake.revokeTokenArgsForCall, struct {
+		arg1 string
+		arg2 time.Duration
+	}{arg1, arg2})
+	stub := fake.RevokeTokenStub
+	fakeReturns := fake.revokeTokenReturns
+	fake.recordInvocation("RevokeToken", []interface{}{arg1, arg2})
+	fake.revokeTokenMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeUserRepository) RevokeTokenCallCount() int {
+	fake.revokeTokenMutex.RLock()
+	defer fake.revokeTokenMutex.RUnlock()
+	return len(fake.revokeTokenArgsForCall)
+}
+
+func (fake *FakeUserRepository) RevokeTokenCalls(stub func(string, time.Duration) error) {
+	fake.revokeTokenMutex.Lock()
+	defer fake.revokeTokenMutex.Unlock()
+	fake.RevokeTokenStub = stub
+}
+
+func (fake *FakeUserRepository) RevokeTokenArgsForCall(i int) (string, time.Duration) {
+	fake.revokeTokenMutex.RLock()
+	defer fake.revokeTokenMutex.RUnlock()
+	argsForCall := fake.revokeTokenArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeUserRepository) RevokeTokenReturns(result1 error) {
+	fake.revokeTokenMutex.Lock()
+	defer fake.revokeTokenMutex.Unlock()
+	fake.RevokeTokenStub = nil
+	fake.revokeTokenReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeUserRepository) RevokeTokenReturnsOnCall(i int, result1 error) {
+	fake.revokeTokenMutex.Lock()
+	defer fake.revokeTokenMutex.Unlock()
+	fake.RevokeTokenStub = nil
+	if fake.revokeTokenReturnsOnCall == nil {
+		fake.revokeTokenReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.revokeTokenReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeUserRepository) SaveAuthCode(arg1 int64, arg2 string, arg3 time.Duration) error {
+	fake.saveAuthCodeMutex.Lock()
+	ret, specificReturn := fake.saveAuthCodeReturnsOnCall[len(fake.saveAuthCodeArgsForCall)]
+	fake.saveAuthCodeArgsForCall = append(fake.saveAuthCodeArgsForCall, struct {
+		arg1 int64
+		arg2 string
+		arg3 time.Duration
+	}{arg1, arg2, arg3})
+	stub := fake.SaveAuthCodeStub
+	fakeReturns := fake.saveAuthCodeReturns
+	fake.recordInvocation("SaveAuthCode", []interface{}{arg1, arg2, arg3})
+	fake.saveAuthCodeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeUserRepository) SaveAuthCodeCallCount() int {
+	fake.saveAuthCodeMutex.RLock()
+	defer fake.saveAuthCodeMutex.RUnlock()
+	return len(fake.saveAuthCodeArgsForCall)
+}
+
+func (fake *FakeUserRepository) SaveAuthCodeCalls(stub func(int64, string, time.Duration) error) {
+	fake.saveAuthCodeMutex.Lock()
+	defer fake.saveAuthCodeMutex.Unlock()
+	fake.SaveAuthCodeStub = stub
+}
+
+func (fake *FakeUserRepository) SaveAuthCodeArgsForCall(i int) (int64, string, time.Duration) {
+	fake.saveAuthCodeMutex.RLock()
+	defer fake.saveAuthCodeMutex.RUnlock()
+	argsForCall := fake.saveAuthCodeArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeUserRepository) SaveAuthCodeReturns(result1 error) {
+	fake.saveAuthCodeMutex.Lock()
+	defer fake.saveAuthCodeMutex.Unlock()
+	fake.SaveAuthCodeStub = nil
+	fake.saveAuthCodeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeUserRepository) SaveAuthCodeReturnsOnCall(i int, result1 error) {
+	fake.saveAuthCodeMutex.Lock()
+	defer fake.saveAuthCodeMutex.Unlock()
+	fake.SaveAuthCodeStub = nil
+	if fake.saveAuthCodeReturnsOnCall == nil {
+		fake.saveAuthCodeReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.saveAuthCodeReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeUserRepository) SaveAuthToken(arg1 string, arg2 int) error {
 	fake.saveAuthTokenMutex.Lock()
 	ret, specificReturn := fake.saveAuthTokenReturnsOnCall[len(fake.saveAuthTokenArgsForCall)]
@@ -1259,15 +2718,16 @@ func (fake *FakeUserRepository) SaveAuthToken(arg1 string, arg2 int) error {
 		arg1 string
 		arg2 int
 	}{arg1, arg2})
+	stub := fake.SaveAuthTokenStub
+	fakeReturns := fake.saveAuthTokenReturns
 	fake.recordInvocation("SaveAuthToken", []interface{}{arg1, arg2})
 	fake.saveAuthTokenMutex.Unlock()
-	if fake.SaveAuthTokenStub != nil {
-		return fake.SaveAuthTokenStub(arg1, arg2)
+	if stub != nil {
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.saveAuthTokenReturns
 	return fakeReturns.result1
 }
 
@@ -1313,21 +2773,211 @@ func (fake *FakeUserRepository) SaveAuthTokenReturnsOnCall(i int, result1 error)
 	}{result1}
 }
 
+func (fake *FakeUserRepository) SavePasswordResetToken(arg1 string, arg2 int64, arg3 time.Duration) error {
+	fake.savePasswordResetTokenMutex.Lock()
+	ret, specificReturn := fake.savePasswordResetTokenReturnsOnCall[len(fake.savePasswordResetTokenArgsForCall)]
+	fake.savePasswordResetTokenArgsForCall = append(fake.savePasswordResetTokenArgsForCall, struct {
+		arg1 string
+		arg2 int64
+		arg3 time.Duration
+	}{arg1, arg2, arg3})
+	stub := fake.SavePasswordResetTokenStub
+	fakeReturns := fake.savePasswordResetTokenReturns
+	fake.recordInvocation("SavePasswordResetToken", []interface{}{arg1, arg2, arg3})
+	fake.savePasswordResetTokenMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeUserRepository) SavePasswordResetTokenCallCount() int {
+	fake.savePasswordResetTokenMutex.RLock()
+	defer fake.savePasswordResetTokenMutex.RUnlock()
+	return len(fake.savePasswordResetTokenArgsForCall)
+}
+
+func (fake *FakeUserRepository) SavePasswordResetTokenCalls(stub func(string, int64, time.Duration) error) {
+	fake.savePasswordResetTokenMutex.Lock()
+	defer fake.savePasswordResetTokenMutex.Unlock()
+	fake.SavePasswordResetTokenStub = stub
+}
+
+func (fake *FakeUserRepository) SavePasswordResetTokenArgsForCall(i int) (string, int64, time.Duration) {
+	fake.savePasswordResetTokenMutex.RLock()
+	defer fake.savePasswordResetTokenMutex.RUnlock()
+	argsForCall := fake.savePasswordResetTokenArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeUserRepository) SavePasswordResetTokenReturns(result1 error) {
+	fake.savePasswordResetTokenMutex.Lock()
+	defer fake.savePasswordResetTokenMutex.Unlock()
+	fake.SavePasswordResetTokenStub = nil
+	fake.savePasswordResetTokenReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeUserRepository) SavePasswordResetTokenReturnsOnCall(i int, result1 error) {
+	fake.savePasswordResetTokenMutex.Lock()
+	defer fake.savePasswordResetTokenMutex.Unlock()
+	fake.SavePasswordResetTokenStub = nil
+	if fake.savePasswordResetTokenReturnsOnCall == nil {
+		fake.savePasswordResetTokenReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.savePasswordResetTokenReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeUserRepository) SaveRefreshToken(arg1 string, arg2 int64, arg3 time.Duration) error {
+	fake.saveRefreshTokenMutex.Lock()
+	ret, specificReturn := fake.saveRefreshTokenReturnsOnCall[len(fake.saveRefreshTokenArgsForCall)]
+	fake.saveRefreshTokenArgsForCall = append(fake.saveRefreshTokenArgsForCall, struct {
+		arg1 string
+		arg2 int64
+		arg3 time.Duration
+	}{arg1, arg2, arg3})
+	stub := fake.SaveRefreshTokenStub
+	fakeReturns := fake.saveRefreshTokenReturns
+	fake.recordInvocation("SaveRefreshToken", []interface{}{arg1, arg2, arg3})
+	fake.saveRefreshTokenMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeUserRepository) SaveRefreshTokenCallCount() int {
+	fake.saveRefreshTokenMutex.RLock()
+	defer fake.saveRefreshTokenMutex.RUnlock()
+	return len(fake.saveRefreshTokenArgsForCall)
+}
+
+func (fake *FakeUserRepository) SaveRefreshTokenCalls(stub func(string, int64, time.Duration) error) {
+	fake.saveRefreshTokenMutex.Lock()
+	defer fake.saveRefreshTokenMutex.Unlock()
+	fake.SaveRefreshTokenStub = stub
+}
+
+func (fake *FakeUserRepository) SaveRefreshTokenArgsForCall(i int) (string, int64, time.Duration) {
+	fake.saveRefreshTokenMutex.RLock()
+	defer fake.saveRefreshTokenMutex.RUnlock()
+	argsForCall := fake.saveRefreshTokenArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeUserRepository) SaveRefreshTokenReturns(result1 error) {
+	fake.saveRefreshTokenMutex.Lock()
+	defer fake.saveRefreshTokenMutex.Unlock()
+	fake.SaveRefreshTokenStub = nil
+	fake.saveRefreshTokenReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeUserRepository) SaveRefreshTokenReturnsOnCall(i int, result1 error) {
+	fake.saveRefreshTokenMutex.Lock()
+	defer fake.saveRefreshTokenMutex.Unlock()
+	fake.SaveRefreshTokenStub = nil
+	if fake.saveRefreshTokenReturnsOnCall == nil {
+		fake.saveRefreshTokenReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.saveRefreshTokenReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeUserRepository) SaveVerificationToken(arg1 string, arg2 int64, arg3 time.Duration) error {
+	fake.saveVerificationTokenMutex.Lock()
+	ret, specificReturn := fake.saveVerificationTokenReturnsOnCall[len(fake.saveVerificationTokenArgsForCall)]
+	fake.saveVerificationTokenArgsForCall = append(fake.saveVerificationTokenArgsForCall, struct {
+		arg1 string
+		arg2 int64
+		arg3 time.Duration
+	}{arg1, arg2, arg3})
+	stub := fake.SaveVerificationTokenStub
+	fakeReturns := fake.saveVerificationTokenReturns
+	fake.recordInvocation("SaveVerificationToken", []interface{}{arg1, arg2, arg3})
+	fake.saveVerificationTokenMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeUserRepository) SaveVerificationTokenCallCount() int {
+	fake.saveVerificationTokenMutex.RLock()
+	defer fake.saveVerificationTokenMutex.RUnlock()
+	return len(fake.saveVerificationTokenArgsForCall)
+}
+
+func (fake *FakeUserRepository) SaveVerificationTokenCalls(stub func(string, int64, time.Duration) error) {
+	fake.saveVerificationTokenMutex.Lock()
+	defer fake.saveVerificationTokenMutex.Unlock()
+	fake.SaveVerificationTokenStub = stub
+}
+
+func (fake *FakeUserRepository) SaveVerificationTokenArgsForCall(i int) (string, int64, time.Duration) {
+	fake.saveVerificationTokenMutex.RLock()
+	defer fake.saveVerificationTokenMutex.RUnlock()
+	argsForCall := fake.saveVerificationTokenArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeUserRepository) SaveVerificationTokenReturns(result1 error) {
+	fake.saveVerificationTokenMutex.Lock()
+	defer fake.saveVerificationTokenMutex.Unlock()
+	fake.SaveVerificationTokenStub = nil
+	fake.saveVerificationTokenReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeUserRepository) SaveVerificationTokenReturnsOnCall(i int, result1 error) {
+	fake.saveVerificationTokenMutex.Lock()
+	defer fake.saveVerificationTokenMutex.Unlock()
+	fake.SaveVerificationTokenStub = nil
+	if fake.saveVerificationTokenReturnsOnCall == nil {
+		fake.saveVerificationTokenReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.saveVerificationTokenReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeUserRepository) Update(arg1 *models.User) (*models.User, error) {
 	fake.updateMutex.Lock()
 	ret, specificReturn := fake.updateReturnsOnCall[len(fake.updateArgsForCall)]
 	fake.updateArgsForCall = append(fake.updateArgsForCall, struct {
 		arg1 *models.User
 	}{arg1})
+	stub := fake.UpdateStub
+	fakeReturns := fake.updateReturns
 	fake.recordInvocation("Update", []interface{}{arg1})
 	fake.updateMutex.Unlock()
-	if fake.UpdateStub != nil {
-		return fake.UpdateStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.updateReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -1376,45 +3026,151 @@ func (fake *FakeUserRepository) UpdateReturnsOnCall(i int, result1 *models.User,
 	}{result1, result2}
 }
 
+func (fake *FakeUserRepository) UpdatePassword(arg1 int64, arg2 string) error {
+	fake.updatePasswordMutex.Lock()
+	ret, specificReturn := fake.updatePasswordReturnsOnCall[len(fake.updatePasswordArgsForCall)]
+	fake.updatePasswordArgsForCall = append(fake.updatePasswordArgsForCall, struct {
+		arg1 int64
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.UpdatePasswordStub
+	fakeReturns := fake.updatePasswordReturns
+	fake.recordInvocation("UpdatePassword", []interface{}{arg1, arg2})
+	fake.updatePasswordMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeUserRepository) UpdatePasswordCallCount() int {
+	fake.updatePasswordMutex.RLock()
+	defer fake.updatePasswordMutex.RUnlock()
+	return len(fake.updatePasswordArgsForCall)
+}
+
+func (fake *FakeUserRepository) UpdatePasswordCalls(stub func(int64, string) error) {
+	fake.updatePasswordMutex.Lock()
+	defer fake.updatePasswordMutex.Unlock()
+	fake.UpdatePasswordStub = stub
+}
+
+func (fake *FakeUserRepository) UpdatePasswordArgsForCall(i int) (int64, string) {
+	fake.updatePasswordMutex.RLock()
+	defer fake.updatePasswordMutex.RUnlock()
+	argsForCall := fake.updatePasswordArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeUserRepository) UpdatePasswordReturns(result1 error) {
+	fake.updatePasswordMutex.Lock()
+	defer fake.updatePasswordMutex.Unlock()
+	fake.UpdatePasswordStub = nil
+	fake.updatePasswordReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeUserRepository) UpdatePasswordReturnsOnCall(i int, result1 error) {
+	fake.updatePasswordMutex.Lock()
+	defer fake.updatePasswordMutex.Unlock()
+	fake.UpdatePasswordStub = nil
+	if fake.updatePasswordReturnsOnCall == nil {
+		fake.updatePasswordReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.updatePasswordReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeUserRepository) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.addFlavorMutex.RLock()
+	defer fake.addFlavorMutex.RUnlock()
 	fake.addIngredientMutex.RLock()
 	defer fake.addIngredientMutex.RUnlock()
 	fake.addPermissionMutex.RLock()
 	defer fake.addPermissionMutex.RUnlock()
+	fake.addRoleMutex.RLock()
+	defer fake.addRoleMutex.RUnlock()
+	fake.consumeAuthCodeMutex.RLock()
+	defer fake.consumeAuthCodeMutex.RUnlock()
+	fake.consumeAuthTokenMutex.RLock()
+	defer fake.consumeAuthTokenMutex.RUnlock()
+	fake.consumePasswordResetTokenMutex.RLock()
+	defer fake.consumePasswordResetTokenMutex.RUnlock()
+	fake.consumeRefreshTokenMutex.RLock()
+	defer fake.consumeRefreshTokenMutex.RUnlock()
+	fake.consumeVerificationTokenMutex.RLock()
+	defer fake.consumeVerificationTokenMutex.RUnlock()
 	fake.countMutex.RLock()
 	defer fake.countMutex.RUnlock()
+	fake.countAuthRequestMutex.RLock()
+	defer fake.countAuthRequestMutex.RUnlock()
 	fake.deleteMutex.RLock()
 	defer fake.deleteMutex.RUnlock()
 	fake.getMutex.RLock()
 	defer fake.getMutex.RUnlock()
-	fake.getByAuthTokenMutex.RLock()
-	defer fake.getByAuthTokenMutex.RUnlock()
 	fake.getByCredentialsMutex.RLock()
 	defer fake.getByCredentialsMutex.RUnlock()
+	fake.getByEmailMutex.RLock()
+	defer fake.getByEmailMutex.RUnlock()
 	fake.getByPhoneMutex.RLock()
 	defer fake.getByPhoneMutex.RUnlock()
 	fake.getByUUIDMutex.RLock()
 	defer fake.getByUUIDMutex.RUnlock()
+	fake.getFlavorSubscribersMutex.RLock()
+	defer fake.getFlavorSubscribersMutex.RUnlock()
+	fake.getFlavorsMutex.RLock()
+	defer fake.getFlavorsMutex.RUnlock()
 	fake.getIngredientsMutex.RLock()
 	defer fake.getIngredientsMutex.RUnlock()
 	fake.getPermissionsMutex.RLock()
 	defer fake.getPermissionsMutex.RUnlock()
+	fake.getRolesMutex.RLock()
+	defer fake.getRolesMutex.RUnlock()
+	fake.getStoreStaffMutex.RLock()
+	defer fake.getStoreStaffMutex.RUnlock()
+	fake.getSubscribersMutex.RLock()
+	defer fake.getSubscribersMutex.RUnlock()
 	fake.insertMutex.RLock()
 	defer fake.insertMutex.RUnlock()
+	fake.isTokenRevokedMutex.RLock()
+	defer fake.isTokenRevokedMutex.RUnlock()
 	fake.listMutex.RLock()
 	defer fake.listMutex.RUnlock()
 	fake.removeAllPermissionsMutex.RLock()
 	defer fake.removeAllPermissionsMutex.RUnlock()
 	fake.removePermissionMutex.RLock()
 	defer fake.removePermissionMutex.RUnlock()
+	fake.removeRoleMutex.RLock()
+	defer fake.removeRoleMutex.RUnlock()
+	fake.removeUserFlavorMutex.RLock()
+	defer fake.removeUserFlavorMutex.RUnlock()
 	fake.removeUserIngredientMutex.RLock()
 	defer fake.removeUserIngredientMutex.RUnlock()
+	fake.revokeTokenMutex.RLock()
+	defer fake.revokeTokenMutex.RUnlock()
+	fake.saveAuthCodeMutex.RLock()
+	defer fake.saveAuthCodeMutex.RUnlock()
 	fake.saveAuthTokenMutex.RLock()
 	defer fake.saveAuthTokenMutex.RUnlock()
+	fake.savePasswordResetTokenMutex.RLock()
+	defer fake.savePasswordResetTokenMutex.RUnlock()
+	fake.saveRefreshTokenMutex.RLock()
+	defer fake.saveRefreshTokenMutex.RUnlock()
+	fake.saveVerificationTokenMutex.RLock()
+	defer fake.saveVerificationTokenMutex.RUnlock()
 	fake.updateMutex.RLock()
 	defer fake.updateMutex.RUnlock()
+	fake.updatePasswordMutex.RLock()
+	defer fake.updatePasswordMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -6,6 +6,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/go-sql-driver/mysql"
+
 	"github.com/jcorry/morellis/pkg/models"
 )
 
@@ -22,7 +24,8 @@ func (m *FlavorModel) Get(id int) (*models.Flavor, error) {
 		  LEFT JOIN ingredient AS i ON i.id = fi.ingredient_id
 			  WHERE f.id = ?`
 
-	flavor := &models.Flavor{}
+	flavor := &models.Flavor{Ingredients: []models.Ingredient{}}
+	found := false
 
 	rows, err := m.DB.Query(stmt, id)
 
@@ -32,31 +35,34 @@ func (m *FlavorModel) Get(id int) (*models.Flavor, error) {
 
 	defer rows.Close()
 
-	if rows.Next() {
-		ingredient := &models.Ingredient{}
+	for rows.Next() {
+		// A Flavor without Ingredients is a single row with NULL ingredient columns
+		var (
+			iID   sql.NullInt64
+			iName sql.NullString
+		)
 
-		err = rows.Scan(&flavor.ID, &flavor.Name, &flavor.Description, &flavor.Created, &ingredient.ID, &ingredient.Name)
+		err = rows.Scan(&flavor.ID, &flavor.Name, &flavor.Description, &flavor.Created, &iID, &iName)
 
 		if err != nil {
 			return nil, err
 		}
 
-		for rows.Next() {
-			err = rows.Scan(&flavor.ID, &flavor.Name, &flavor.Description, &flavor.Created, &ingredient.ID, &ingredient.Name)
-
-			if err != nil {
-				return nil, err
-			}
+		found = true
 
-			flavor.Ingredients = append(flavor.Ingredients, *ingredient)
+		if iID.Valid {
+			flavor.Ingredients = append(flavor.Ingredients, models.Ingredient{ID: iID.Int64, Name: iName.String})
 		}
-	} else {
-		return nil, models.ErrNoRecord
 	}
 
 	if err = rows.Err(); err != nil {
 		return nil, err
 	}
+
+	if !found {
+		return nil, models.ErrNoRecord
+	}
+
 	return flavor, nil
 }
 
@@ -172,50 +178,117 @@ func (m *FlavorModel) Insert(flavor *models.Flavor) (*models.Flavor, error) {
 	flavor.Created = created
 
 	// now handle each of the ingredients
-	ingredientsModel := IngredientModel{DB: m.DB}
-
-	for index, ingredient := range flavor.Ingredients {
-		i, err := ingredientsModel.GetByName(ingredient.Name)
-		if err == models.ErrNoRecord {
-			i, err = ingredientsModel.Insert(&ingredient)
-		}
-
+	for index := range flavor.Ingredients {
+		err = addFlavorIngredient(tx, flavorId, &flavor.Ingredients[index])
 		if err != nil {
 			tx.Rollback()
 			return nil, err
 		}
+	}
+
+	err = tx.Commit()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return flavor, nil
+}
+
+// Update the name and description of a Flavor identified by its ID, and replace its
+// Ingredients with flavor.Ingredients.
+func (m *FlavorModel) Update(id int, flavor *models.Flavor) (*models.Flavor, error) {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var flavorId int64
+	err = tx.QueryRow(`SELECT id FROM flavor WHERE id = ? FOR UPDATE`, id).Scan(&flavorId)
+	if err == sql.ErrNoRows {
+		return nil, models.ErrNoRecord
+	} else if err != nil {
+		return nil, err
+	}
 
-		flavor.Ingredients[index].ID = i.ID
+	stmt := `UPDATE flavor SET name = ?, description = ? WHERE id = ?`
+	_, err = tx.Exec(stmt, flavor.Name, flavor.Description, flavorId)
+	if err != nil {
+		return nil, err
+	}
 
-		stmt = `INSERT INTO flavor_ingredient (flavor_id, ingredient_id) VALUES (?, ?)`
-		_, err = tx.Exec(stmt, flavorId, i.ID)
+	stmt = `DELETE FROM flavor_ingredient WHERE flavor_id = ?`
+	_, err = tx.Exec(stmt, flavorId)
+	if err != nil {
+		return nil, err
+	}
 
+	for index := range flavor.Ingredients {
+		err = addFlavorIngredient(tx, flavorId, &flavor.Ingredients[index])
 		if err != nil {
-			tx.Rollback()
 			return nil, err
 		}
 	}
 
 	err = tx.Commit()
-
 	if err != nil {
 		return nil, err
 	}
 
-	return flavor, nil
+	return m.Get(id)
 }
 
-// Update a Flavor identified by its ID.
-func (m *FlavorModel) Update(id int, flavor *models.Flavor) (*models.Flavor, error) {
-	return nil, nil
+// addFlavorIngredient associates the Ingredient with the Flavor identified by flavorId, getting
+// the Ingredient by name or creating it if there is none, and sets its ID.
+func addFlavorIngredient(tx *sql.Tx, flavorId int64, ingredient *models.Ingredient) error {
+	stmt := `SELECT id, name FROM ingredient WHERE LOWER(name) = ?`
+	err := tx.QueryRow(stmt, ingredient.Name).Scan(&ingredient.ID, &ingredient.Name)
+	if err == sql.ErrNoRows {
+		stmt = `INSERT INTO ingredient (name, created) VALUES (?, ?)`
+		res, err := tx.Exec(stmt, ingredient.Name, time.Now())
+		if err != nil {
+			return err
+		}
+
+		ingredient.ID, err = res.LastInsertId()
+		if err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+
+	stmt = `INSERT INTO flavor_ingredient (flavor_id, ingredient_id) VALUES (?, ?)`
+	_, err = tx.Exec(stmt, flavorId, ingredient.ID)
+
+	return err
 }
 
-// Delete a Flavor identified by ID.
+// Delete a Flavor identified by ID, along with the Users' subscriptions to it. A Flavor which
+// is active at a Store can't be deleted.
 func (m *FlavorModel) Delete(id int) (bool, error) {
 	tx, _ := m.DB.Begin()
 	defer tx.Rollback()
 
-	stmt := `DELETE FROM flavor_ingredient WHERE flavor_id = ?`
+	var active int
+	stmt := `SELECT COUNT(*) FROM flavor_store WHERE flavor_id = ? AND is_active = 1 FOR UPDATE`
+	err := tx.QueryRow(stmt, id).Scan(&active)
+	if err != nil {
+		return false, err
+	}
+
+	if active > 0 {
+		return false, models.ErrActiveFlavor
+	}
+
+	stmt = `DELETE FROM flavor_user WHERE flavor_id = ?`
+	_, err = tx.Exec(stmt, id)
+	if err != nil {
+		return false, err
+	}
+
+	stmt = `DELETE FROM flavor_ingredient WHERE flavor_id = ?`
 	res, err := tx.Exec(stmt, id)
 	if err != nil {
 		return false, err
@@ -224,6 +297,10 @@ func (m *FlavorModel) Delete(id int) (bool, error) {
 	stmt = `DELETE FROM flavor WHERE id = ?`
 	res, err = tx.Exec(stmt, id)
 	if err != nil {
+		// The Flavor's history at Stores, or Notifications about it, still refer to it
+		if mysqlErr, ok := err.(*mysql.MySQLError); ok && mysqlErr.Number == 1451 {
+			return false, models.ErrFlavorInUse
+		}
 		return false, err
 	}
 
@@ -450,6 +450,7 @@ func TestFlavorModel_DeleteCommits(t *testing.T) {
 	flavorID := 12
 
 	mock.ExpectBegin()
+	expectFlavorNotActive(mock, flavorID)
 
 	stmt := `^DELETE FROM flavor_ingredient WHERE flavor_id = (.+)$`
 	mock.ExpectExec(stmt).WithArgs(flavorID).WillReturnResult(sqlmock.NewResult(1, 3))
@@ -481,6 +482,7 @@ func TestFlavorModel_DeleteReturnsFalseWhenNoAffectedRows(t *testing.T) {
 	flavorID := 12
 
 	mock.ExpectBegin()
+	expectFlavorNotActive(mock, flavorID)
 
 	stmt := `^DELETE FROM flavor_ingredient WHERE flavor_id = (.+)$`
 	mock.ExpectExec(stmt).WithArgs(flavorID).WillReturnResult(sqlmock.NewResult(1, 3))
@@ -517,6 +519,7 @@ func TestFlavorModel_DeleteRollsBackOnFlavorIngredientError(t *testing.T) {
 	expectedErr := fmt.Errorf("delete flavor_ingredient err")
 
 	mock.ExpectBegin()
+	expectFlavorNotActive(mock, flavorID)
 
 	stmt := `^DELETE FROM flavor_ingredient WHERE flavor_id = (.+)$`
 	mock.ExpectExec(stmt).WithArgs(flavorID).WillReturnError(expectedErr)
@@ -546,6 +549,7 @@ func TestFlavorModel_DeleteRollsBackOnFlavorError(t *testing.T) {
 	expectedErr := fmt.Errorf("Delete flavor err")
 
 	mock.ExpectBegin()
+	expectFlavorNotActive(mock, flavorID)
 
 	stmt := `^DELETE FROM flavor_ingredient WHERE flavor_id = (.+)$`
 	mock.ExpectExec(stmt).WithArgs(flavorID).WillReturnResult(sqlmock.NewResult(1, 3))
@@ -578,6 +582,7 @@ func TestFlavorModel_DeleteCommitReturnsErr(t *testing.T) {
 	expectedErr := fmt.Errorf("commit err")
 
 	mock.ExpectBegin()
+	expectFlavorNotActive(mock, flavorID)
 
 	stmt := `^DELETE FROM flavor_ingredient WHERE flavor_id = (.+)$`
 	mock.ExpectExec(stmt).WithArgs(flavorID).WillReturnResult(sqlmock.NewResult(1, 3))
@@ -598,3 +603,182 @@ func TestFlavorModel_DeleteCommitReturnsErr(t *testing.T) {
 		t.Errorf("There were unfulfilled expectations: %s", err)
 	}
 }
+
+func TestFlavorModel_DeleteActiveFlavor(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening stub DB connection", err)
+	}
+	defer db.Close()
+
+	flavorID := 12
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT COUNT\(\*\) FROM flavor_store WHERE flavor_id = (.+) AND is_active = 1 FOR UPDATE$`).
+		WithArgs(flavorID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectRollback()
+
+	f := FlavorModel{DB: db}
+
+	_, err = f.Delete(flavorID)
+	if err != models.ErrActiveFlavor {
+		t.Errorf("Unexpected err; Got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestFlavorModel_UpdateReplacesIngredients(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening stub DB connection", err)
+	}
+	defer db.Close()
+
+	flavorID := 12
+	created := time.Now()
+
+	flavor := &models.Flavor{
+		Name:        "Coconut Jalapeno",
+		Description: "Coconut ice cream with fresh jalapenos",
+		Ingredients: []models.Ingredient{
+			{Name: "coconut"},
+			{Name: "jalapeno"},
+		},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT id FROM flavor WHERE id = (.+) FOR UPDATE$`).
+		WithArgs(flavorID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(flavorID))
+	mock.ExpectExec(`^UPDATE flavor SET name = (.+), description = (.+) WHERE id = (.+)$`).
+		WithArgs(flavor.Name, flavor.Description, flavorID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`^DELETE FROM flavor_ingredient WHERE flavor_id = (.+)$`).
+		WithArgs(flavorID).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	getByNameQuery := `^SELECT id, name FROM ingredient WHERE LOWER\(name\) = (.+)$`
+	insertFlavorIngredientQuery := `^INSERT INTO flavor_ingredient \(flavor_id, ingredient_id\) VALUES \((.+), (.+)\)$`
+
+	// coconut exists, jalapeno is created
+	mock.ExpectQuery(getByNameQuery).WithArgs("coconut").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "coconut"))
+	mock.ExpectExec(insertFlavorIngredientQuery).WithArgs(flavorID, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(getByNameQuery).WithArgs("jalapeno").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectExec(`^INSERT INTO ingredient \(name, created\) VALUES \((.+), (.+)\)$`).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectExec(insertFlavorIngredientQuery).WithArgs(flavorID, 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	mock.ExpectQuery(`^SELECT f.id, f.name, f.description, f.created, i.id, i.name`).
+		WithArgs(flavorID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "created", "id", "name"}).
+			AddRow(flavorID, flavor.Name, flavor.Description, created, 1, "coconut").
+			AddRow(flavorID, flavor.Name, flavor.Description, created, 2, "jalapeno"))
+
+	f := FlavorModel{DB: db}
+
+	updated, err := f.Update(flavorID, flavor)
+	if err != nil {
+		t.Fatalf("Unexpected err; Got %s", err)
+	}
+
+	if len(updated.Ingredients) != 2 || updated.Ingredients[1].ID != 2 {
+		t.Errorf("Unexpected ingredients; Got %v", updated.Ingredients)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestFlavorModel_UpdateEmptyIngredients(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening stub DB connection", err)
+	}
+	defer db.Close()
+
+	flavorID := 12
+	flavor := &models.Flavor{
+		Name:        "Sweet Cream",
+		Description: "Just cream and sugar",
+		Ingredients: []models.Ingredient{},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT id FROM flavor WHERE id = (.+) FOR UPDATE$`).
+		WithArgs(flavorID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(flavorID))
+	mock.ExpectExec(`^UPDATE flavor SET name = (.+), description = (.+) WHERE id = (.+)$`).
+		WithArgs(flavor.Name, flavor.Description, flavorID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`^DELETE FROM flavor_ingredient WHERE flavor_id = (.+)$`).
+		WithArgs(flavorID).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	// The LEFT JOINs leave the ingredient columns NULL
+	mock.ExpectQuery(`^SELECT f.id, f.name, f.description, f.created, i.id, i.name`).
+		WithArgs(flavorID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "created", "id", "name"}).
+			AddRow(flavorID, flavor.Name, flavor.Description, time.Now(), nil, nil))
+
+	f := FlavorModel{DB: db}
+
+	updated, err := f.Update(flavorID, flavor)
+	if err != nil {
+		t.Fatalf("Unexpected err; Got %s", err)
+	}
+
+	if updated.Name != flavor.Name || len(updated.Ingredients) != 0 {
+		t.Errorf("Unexpected flavor; Got %v", updated)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestFlavorModel_UpdateNoRecord(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening stub DB connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT id FROM flavor WHERE id = (.+) FOR UPDATE$`).
+		WithArgs(12).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+
+	f := FlavorModel{DB: db}
+
+	_, err = f.Update(12, &models.Flavor{Name: "Vanilla"})
+	if err != models.ErrNoRecord {
+		t.Errorf("Unexpected err; Got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There were unfulfilled expectations: %s", err)
+	}
+}
+
+// expectFlavorNotActive expects Delete to check that the Flavor isn't active and remove the
+// Users' subscriptions to it.
+func expectFlavorNotActive(mock sqlmock.Sqlmock, flavorID int) {
+	mock.ExpectQuery(`^SELECT COUNT\(\*\) FROM flavor_store WHERE flavor_id = (.+) AND is_active = 1 FOR UPDATE$`).
+		WithArgs(flavorID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectExec(`^DELETE FROM flavor_user WHERE flavor_id = (.+)$`).
+		WithArgs(flavorID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+}
@@ -0,0 +1,132 @@
+package mysql
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jcorry/morellis/pkg/models"
+)
+
+// InventoryModel records the fill levels of the Barrels active at each Store.
+type InventoryModel struct {
+	DB *sql.DB
+}
+
+// GetBarrel gets the Barrel active in the Position at the Store, with its levels
+func (m *InventoryModel) GetBarrel(storeID int64, position int) (*models.Barrel, error) {
+	barrels, err := m.listBarrels(`AND fs.position = ?`, storeID, position)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(barrels) == 0 {
+		return nil, models.ErrNoRecord
+	}
+
+	return barrels[0], nil
+}
+
+// ListBarrels lists the Barrels active at the Store, with their levels, ordered by Position
+func (m *InventoryModel) ListBarrels(storeID int64) ([]*models.Barrel, error) {
+	return m.listBarrels(``, storeID)
+}
+
+func (m *InventoryModel) listBarrels(where string, args ...interface{}) ([]*models.Barrel, error) {
+	stmt := `SELECT fs.id, fs.store_id, fs.position, fs.activated, f.id, f.name,
+					IFNULL(bl.id, 0), IFNULL(bl.level, 0), IFNULL(bl.user_id, 0), IFNULL(bl.created, fs.activated)
+			   FROM flavor_store AS fs
+			   JOIN flavor AS f ON f.id = fs.flavor_id
+		  LEFT JOIN barrel_level AS bl ON bl.flavor_store_id = fs.id
+			  WHERE fs.store_id = ?
+				AND fs.is_active = 1 ` + where + `
+		   ORDER BY fs.position, bl.created, bl.id`
+
+	rows, err := m.DB.Query(stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	barrels := []*models.Barrel{}
+	var barrel *models.Barrel
+
+	for rows.Next() {
+		b := &models.Barrel{Flavor: &models.Flavor{}}
+		var bl models.BarrelLevel
+
+		err = rows.Scan(&b.ID, &b.StoreID, &b.Position, &b.Activated, &b.Flavor.ID, &b.Flavor.Name,
+			&bl.ID, &bl.Level, &bl.UserID, &bl.Created)
+		if err != nil {
+			return nil, err
+		}
+
+		if barrel == nil || barrel.ID != b.ID {
+			barrel = b
+			barrels = append(barrels, barrel)
+		}
+
+		if bl.ID > 0 {
+			bl.BarrelID = barrel.ID
+			barrel.Levels = append(barrel.Levels, bl)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return barrels, nil
+}
+
+// RecordLevel records the fill level of the Barrel, in percent, as measured by the User
+func (m *InventoryModel) RecordLevel(barrelID int64, level int, userID int64) (*models.BarrelLevel, error) {
+	var user sql.NullInt64
+	if userID > 0 {
+		user = sql.NullInt64{Int64: userID, Valid: true}
+	}
+
+	created := time.Now()
+	stmt := `INSERT INTO barrel_level (flavor_store_id, level, user_id, created) VALUES (?, ?, ?, ?)`
+
+	res, err := m.DB.Exec(stmt, barrelID, level, user, created)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.BarrelLevel{
+		ID:       id,
+		BarrelID: barrelID,
+		Level:    level,
+		UserID:   userID,
+		Created:  created,
+	}, nil
+}
+
+// GetLowLevel gets the fill level below which Barrels at the Store are low, in percent
+func (m *InventoryModel) GetLowLevel(storeID int64) (int, error) {
+	var level int
+
+	err := m.DB.QueryRow(`SELECT low_level FROM inventory_threshold WHERE store_id = ?`, storeID).Scan(&level)
+	if err == sql.ErrNoRows {
+		return models.DEFAULT_LOW_BARREL_LEVEL, nil
+	} else if err != nil {
+		return 0, err
+	}
+
+	return level, nil
+}
+
+// SetLowLevel sets the fill level below which Barrels at the Store are low, in percent
+func (m *InventoryModel) SetLowLevel(storeID int64, level int) error {
+	stmt := `INSERT INTO inventory_threshold (store_id, low_level, updated) VALUES (?, ?, ?)
+				 ON DUPLICATE KEY UPDATE low_level = VALUES(low_level), updated = VALUES(updated)`
+
+	_, err := m.DB.Exec(stmt, storeID, level, time.Now())
+
+	return err
+}
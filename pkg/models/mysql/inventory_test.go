@@ -0,0 +1,67 @@
+package mysql_test
+
+import (
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jcorry/morellis/pkg/models"
+	repo "github.com/jcorry/morellis/pkg/models/mysql"
+)
+
+func TestInventoryModel_ListBarrels(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening stub DB connection", err)
+	}
+	defer db.Close()
+
+	activated := time.Now().Add(-time.Hour * 4)
+	cols := []string{"id", "store_id", "position", "activated", "id", "name", "id", "level", "user_id", "created"}
+	rows := sqlmock.NewRows(cols).
+		AddRow(40, 3, 1, activated, 5, "Vanilla", 1, 80, 7, activated.Add(time.Hour)).
+		AddRow(40, 3, 1, activated, 5, "Vanilla", 2, 60, 7, activated.Add(time.Hour*2)).
+		AddRow(41, 3, 2, activated, 6, "Pecan", 0, 0, 0, activated)
+
+	mock.ExpectQuery(`SELECT (.+) FROM flavor_store AS fs (.+) WHERE fs.store_id = \? AND fs.is_active = 1`).
+		WithArgs(3).
+		WillReturnRows(rows)
+
+	m := repo.InventoryModel{DB: db}
+	barrels, err := m.ListBarrels(3)
+	require.NoError(t, err)
+	require.Len(t, barrels, 2)
+
+	require.Equal(t, int64(40), barrels[0].ID)
+	require.Equal(t, "Vanilla", barrels[0].Flavor.Name)
+	require.Len(t, barrels[0].Levels, 2)
+	require.Equal(t, 60, barrels[0].Levels[1].Level)
+	require.Equal(t, int64(40), barrels[0].Levels[1].BarrelID)
+
+	require.Equal(t, int64(41), barrels[1].ID)
+	require.Equal(t, 2, barrels[1].Position)
+	require.Empty(t, barrels[1].Levels)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInventoryModel_GetLowLevel(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening stub DB connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT low_level FROM inventory_threshold WHERE store_id = \?`).
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"low_level"}))
+
+	m := repo.InventoryModel{DB: db}
+	level, err := m.GetLowLevel(3)
+	require.NoError(t, err)
+	require.Equal(t, models.DEFAULT_LOW_BARREL_LEVEL, level)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
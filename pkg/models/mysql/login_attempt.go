@@ -0,0 +1,47 @@
+package mysql
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jcorry/morellis/pkg/models"
+)
+
+// LoginAttemptModel is the audit log of attempts to log in.
+type LoginAttemptModel struct {
+	DB *sql.DB
+}
+
+// Insert records the LoginAttempt
+func (m *LoginAttemptModel) Insert(attempt *models.LoginAttempt) (*models.LoginAttempt, error) {
+	var userID sql.NullInt64
+	if attempt.UserID > 0 {
+		userID = sql.NullInt64{Int64: attempt.UserID, Valid: true}
+	}
+
+	var phone string
+	if attempt.Phone != "" {
+		phone = NormalizePhone(attempt.Phone)
+	}
+
+	created := time.Now()
+	stmt := `INSERT INTO login_attempt (user_id, phone, method, success, reason, remote_addr, created)
+				  VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	res, err := m.DB.Exec(stmt, userID, phone, attempt.Method, attempt.Success, attempt.Reason, attempt.RemoteAddr, created)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	a := *attempt
+	a.ID = id
+	a.Phone = phone
+	a.Created = created
+
+	return &a, nil
+}
@@ -0,0 +1,316 @@
+package mysql
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/jcorry/morellis/pkg/models"
+)
+
+// MessageModel is the outbox of SMS messages waiting to be sent.
+type MessageModel struct {
+	DB *sql.DB
+}
+
+// Insert queues a new pending Message for the phone number. The Message is associated
+// with the User having that phone number, if there is one.
+func (m *MessageModel) Insert(phone string, body string) (*models.Message, error) {
+	created := time.Now()
+	stmt := `INSERT INTO message (user_id, phone, body, status, next_attempt, created)
+			 VALUES ((SELECT id FROM user WHERE phone = ? LIMIT 1), ?, ?, ?, ?, ?)`
+
+	normalized := NormalizePhone(phone)
+	res, err := m.DB.Exec(stmt, normalized, phone, body, models.MESSAGE_STATUS_PENDING, created, created)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.Message{
+		ID:          id,
+		Phone:       phone,
+		Body:        body,
+		Status:      models.MESSAGE_STATUS_PENDING,
+		NextAttempt: created,
+		Created:     created,
+		Updated:     created,
+	}, nil
+}
+
+// Get a single Message by ID
+func (m *MessageModel) Get(ID int64) (*models.Message, error) {
+	stmt := `SELECT id, IFNULL(user_id, 0), phone, body, status, attempts, next_attempt, IFNULL(last_error, ''), IFNULL(sid, ''), IFNULL(delivery_status, ''), created, updated
+			   FROM message
+			  WHERE id = ?`
+
+	return m.get(stmt, ID)
+}
+
+// GetBySID gets the Message the provider identified by `sid` when it was sent.
+func (m *MessageModel) GetBySID(sid string) (*models.Message, error) {
+	stmt := `SELECT id, IFNULL(user_id, 0), phone, body, status, attempts, next_attempt, IFNULL(last_error, ''), IFNULL(sid, ''), IFNULL(delivery_status, ''), created, updated
+			   FROM message
+			  WHERE sid = ?`
+
+	return m.get(stmt, sid)
+}
+
+func (m *MessageModel) get(stmt string, args ...interface{}) (*models.Message, error) {
+	msg := &models.Message{}
+	err := m.DB.QueryRow(stmt, args...).Scan(&msg.ID, &msg.UserID, &msg.Phone, &msg.Body, &msg.Status, &msg.Attempts, &msg.NextAttempt, &msg.LastError, &msg.SID, &msg.DeliveryStatus, &msg.Created, &msg.Updated)
+
+	if err == sql.ErrNoRows {
+		return nil, models.ErrNoRecord
+	} else if err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+// ListByUser lists the Messages sent to the User, most recent first, limiting results by
+// `limit` beginning at `offset`. Each Message's Events hold its delivery history.
+func (m *MessageModel) ListByUser(userID int64, limit int, offset int) ([]*models.Message, error) {
+	stmt := `SELECT id, IFNULL(user_id, 0), phone, body, status, attempts, next_attempt, IFNULL(last_error, ''), IFNULL(sid, ''), IFNULL(delivery_status, ''), created, updated
+			   FROM message
+			  WHERE user_id = ?
+		   ORDER BY created DESC, id DESC
+			  LIMIT ?, ?`
+
+	if limit < 1 {
+		limit = DEFAULT_LIMIT
+	}
+
+	rows, err := m.DB.Query(stmt, userID, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := []*models.Message{}
+	byID := make(map[int64]*models.Message)
+	var IDs []interface{}
+
+	for rows.Next() {
+		msg := &models.Message{}
+		err = rows.Scan(&msg.ID, &msg.UserID, &msg.Phone, &msg.Body, &msg.Status, &msg.Attempts, &msg.NextAttempt, &msg.LastError, &msg.SID, &msg.DeliveryStatus, &msg.Created, &msg.Updated)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, msg)
+		byID[msg.ID] = msg
+		IDs = append(IDs, msg.ID)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(IDs) == 0 {
+		return messages, nil
+	}
+
+	stmt = `SELECT id, message_id, status, IFNULL(error_code, ''), created
+			  FROM message_event
+			 WHERE message_id IN (?` + strings.Repeat(", ?", len(IDs)-1) + `)
+		  ORDER BY created ASC, id ASC`
+
+	events, err := m.DB.Query(stmt, IDs...)
+	if err != nil {
+		return nil, err
+	}
+	defer events.Close()
+
+	for events.Next() {
+		e := models.MessageEvent{}
+		err = events.Scan(&e.ID, &e.MessageID, &e.Status, &e.ErrorCode, &e.Created)
+		if err != nil {
+			return nil, err
+		}
+		byID[e.MessageID].Events = append(byID[e.MessageID].Events, e)
+	}
+	if err = events.Err(); err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+// CountByUser gets the total number of Messages sent to the User
+func (m *MessageModel) CountByUser(userID int64) int {
+	var count int
+	row := m.DB.QueryRow(`SELECT COUNT(id) FROM message WHERE user_id = ?`, userID)
+
+	err := row.Scan(&count)
+	if err != nil {
+		return 0
+	}
+
+	return count
+}
+
+// AddEvent records a delivery status reported by the provider for the Message, and makes it
+// the Message's current DeliveryStatus. `errorCode` is the provider's error code for failed
+// deliveries, and empty otherwise.
+func (m *MessageModel) AddEvent(messageID int64, status string, errorCode string) (*models.MessageEvent, error) {
+	created := time.Now()
+
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	stmt := `INSERT INTO message_event (message_id, status, error_code, created) VALUES (?, ?, NULLIF(?, ''), ?)`
+	res, err := tx.Exec(stmt, messageID, status, errorCode, created)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	stmt = `UPDATE message
+			   SET delivery_status = ?
+			 WHERE id = ?`
+	_, err = tx.Exec(stmt, status, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.MessageEvent{
+		ID:        id,
+		MessageID: messageID,
+		Status:    status,
+		ErrorCode: errorCode,
+		Created:   created,
+	}, nil
+}
+
+// Claim locks up to `limit` Messages that are due to be sent and marks them as sending,
+// incrementing their attempt count. Messages left sending for longer than `lease`, by a
+// worker that died mid-send, are claimed again.
+func (m *MessageModel) Claim(limit int, lease time.Duration) ([]*models.Message, error) {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	stmt := `SELECT id, IFNULL(user_id, 0), phone, body, status, attempts, next_attempt, IFNULL(last_error, ''), IFNULL(sid, ''), created, updated
+			   FROM message
+			  WHERE (status = ? AND next_attempt <= ?)
+			     OR (status = ? AND updated <= ?)
+		   ORDER BY next_attempt ASC
+			  LIMIT ?
+		 FOR UPDATE SKIP LOCKED`
+
+	rows, err := tx.Query(stmt, models.MESSAGE_STATUS_PENDING, now, models.MESSAGE_STATUS_SENDING, now.Add(-lease), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := []*models.Message{}
+	var IDs []interface{}
+
+	for rows.Next() {
+		msg := &models.Message{}
+		err = rows.Scan(&msg.ID, &msg.UserID, &msg.Phone, &msg.Body, &msg.Status, &msg.Attempts, &msg.NextAttempt, &msg.LastError, &msg.SID, &msg.Created, &msg.Updated)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		messages = append(messages, msg)
+		IDs = append(IDs, msg.ID)
+	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(messages) == 0 {
+		return messages, nil
+	}
+
+	stmt = `UPDATE message
+			   SET status = ?, attempts = attempts + 1, updated = ?
+			 WHERE id IN (?` + strings.Repeat(", ?", len(IDs)-1) + `)`
+
+	args := append([]interface{}{models.MESSAGE_STATUS_SENDING, now}, IDs...)
+	_, err = tx.Exec(stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, msg := range messages {
+		msg.Status = models.MESSAGE_STATUS_SENDING
+		msg.Attempts++
+		msg.Updated = now
+	}
+
+	return messages, nil
+}
+
+// MarkSent records that the Message was accepted by the provider, which identified it by `sid`.
+func (m *MessageModel) MarkSent(ID int64, sid string) error {
+	stmt := `UPDATE message
+				SET status = ?, sid = ?, last_error = NULL
+			  WHERE id = ?`
+
+	return m.exec(stmt, models.MESSAGE_STATUS_SENT, sid, ID)
+}
+
+// MarkFailed records a failed send and returns the Message to pending to be retried
+// at `nextAttempt`.
+func (m *MessageModel) MarkFailed(ID int64, reason string, nextAttempt time.Time) error {
+	stmt := `UPDATE message
+				SET status = ?, last_error = ?, next_attempt = ?
+			  WHERE id = ?`
+
+	return m.exec(stmt, models.MESSAGE_STATUS_PENDING, reason, nextAttempt, ID)
+}
+
+// MarkDead records a failed send for a Message that will not be retried.
+func (m *MessageModel) MarkDead(ID int64, reason string) error {
+	stmt := `UPDATE message
+				SET status = ?, last_error = ?
+			  WHERE id = ?`
+
+	return m.exec(stmt, models.MESSAGE_STATUS_DEAD, reason, ID)
+}
+
+func (m *MessageModel) exec(stmt string, args ...interface{}) error {
+	res, err := m.DB.Exec(stmt, args...)
+	if err != nil {
+		return err
+	}
+
+	a, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if a < 1 {
+		return models.ErrNoneAffected
+	}
+
+	return nil
+}
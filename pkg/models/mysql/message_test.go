@@ -0,0 +1,89 @@
+package mysql
+
+import (
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jcorry/morellis/pkg/models"
+)
+
+func TestMessageModel_Claim(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening stub DB connection", err)
+	}
+	defer db.Close()
+
+	now := time.Now()
+	cols := []string{"id", "user_id", "phone", "body", "status", "attempts", "next_attempt", "last_error", "sid", "created", "updated"}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT (.+) FROM message (.+) FOR UPDATE SKIP LOCKED$`).
+		WithArgs(models.MESSAGE_STATUS_PENDING, AnyTime{}, models.MESSAGE_STATUS_SENDING, AnyTime{}, 10).
+		WillReturnRows(sqlmock.NewRows(cols).
+			AddRow(1, 4, "4045551212", "hello", "pending", 0, now, "", "", now, now).
+			AddRow(2, 0, "4045551313", "world", "pending", 2, now, "twilio error", "", now, now))
+	mock.ExpectExec(`^UPDATE message (.+) WHERE id IN \(\?, \?\)$`).
+		WithArgs(models.MESSAGE_STATUS_SENDING, AnyTime{}, int64(1), int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	m := MessageModel{DB: db}
+	messages, err := m.Claim(10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	require.Equal(t, models.MESSAGE_STATUS_SENDING, messages[0].Status)
+	require.Equal(t, 1, messages[0].Attempts)
+	require.Equal(t, 3, messages[1].Attempts)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMessageModel_ClaimNoneDue(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening stub DB connection", err)
+	}
+	defer db.Close()
+
+	cols := []string{"id", "user_id", "phone", "body", "status", "attempts", "next_attempt", "last_error", "sid", "created", "updated"}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT (.+) FROM message`).WillReturnRows(sqlmock.NewRows(cols))
+	mock.ExpectRollback()
+
+	m := MessageModel{DB: db}
+	messages, err := m.Claim(10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, messages, 0)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMessageModel_AddEvent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening stub DB connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`^INSERT INTO message_event (.+)`).
+		WithArgs(int64(42), "undelivered", "30003", AnyTime{}).
+		WillReturnResult(sqlmock.NewResult(7, 1))
+	mock.ExpectExec(`^UPDATE message SET delivery_status = \? WHERE id = \?$`).
+		WithArgs("undelivered", int64(42)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	m := MessageModel{DB: db}
+	event, err := m.AddEvent(42, "undelivered", "30003")
+	require.NoError(t, err)
+	require.Equal(t, int64(7), event.ID)
+	require.Equal(t, "30003", event.ErrorCode)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
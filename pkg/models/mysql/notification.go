@@ -0,0 +1,260 @@
+package mysql
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/jcorry/morellis/pkg/models"
+)
+
+// NotificationModel holds the Notifications sent, or waiting to be sent, to Users and the
+// NotificationPolicy limiting how often each User is sent them.
+type NotificationModel struct {
+	DB *sql.DB
+}
+
+// Insert a new pending Notification for the User
+func (m *NotificationModel) Insert(userID int64, storeID int64, flavorID int64, body string) (*models.Notification, error) {
+	created := time.Now()
+	stmt := `INSERT INTO notification (user_id, store_id, flavor_id, body, created) VALUES (?, ?, ?, ?, ?)`
+
+	res, err := m.DB.Exec(stmt, userID, storeID, flavorID, body, created)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.Notification{
+		ID:       id,
+		UserID:   userID,
+		StoreID:  storeID,
+		FlavorID: flavorID,
+		Body:     body,
+		Created:  created,
+	}, nil
+}
+
+// Claim claims the User's pending Notifications as `claim` and lists them, oldest first, so that
+// each is sent once however many Dispatchers are flushing them. Nothing is claimed while the
+// User has Notifications claimed by an earlier claim which hasn't been sent, released or timed
+// out, so the messages sent to each User are sent, and counted by their NotificationPolicy, one
+// at a time.
+func (m *NotificationModel) Claim(userID int64, claim string) ([]*models.Notification, error) {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var id int64
+	err = tx.QueryRow(`SELECT id FROM user WHERE id = ? FOR UPDATE`, userID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, models.ErrNoRecord
+	} else if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	var claimed bool
+	stmt := `SELECT EXISTS(SELECT 1 FROM notification WHERE user_id = ? AND sent IS NULL AND claimed_at > ?)`
+
+	err = tx.QueryRow(stmt, userID, now.Add(-models.NOTIFICATION_CLAIM_TIMEOUT)).Scan(&claimed)
+	if err != nil {
+		return nil, err
+	}
+
+	if claimed {
+		return []*models.Notification{}, nil
+	}
+
+	stmt = `UPDATE notification
+			   SET claimed = ?, claimed_at = ?
+			 WHERE user_id = ?
+			   AND sent IS NULL`
+
+	_, err = tx.Exec(stmt, claim, now, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	stmt = `SELECT n.id, n.user_id, u.phone, n.store_id, s.name, s.timezone, n.flavor_id, f.name, n.body, n.created
+			  FROM notification AS n
+			  JOIN user AS u ON n.user_id = u.id
+			  JOIN store AS s ON n.store_id = s.id
+			  JOIN flavor AS f ON n.flavor_id = f.id
+			 WHERE n.claimed = ?
+			   AND n.sent IS NULL
+		  ORDER BY n.created ASC, n.id ASC`
+
+	rows, err := m.DB.Query(stmt, claim)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := []*models.Notification{}
+	for rows.Next() {
+		n := &models.Notification{}
+		err = rows.Scan(&n.ID, &n.UserID, &n.Phone, &n.StoreID, &n.StoreName, &n.Timezone, &n.FlavorID, &n.FlavorName, &n.Body, &n.Created)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, n)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+// Release releases the Notifications claimed as `claim` which weren't sent, so they can be
+// claimed again.
+func (m *NotificationModel) Release(claim string) error {
+	stmt := `UPDATE notification
+				SET claimed = NULL, claimed_at = NULL
+			  WHERE claimed = ?
+			    AND sent IS NULL`
+
+	_, err := m.DB.Exec(stmt, claim)
+	return err
+}
+
+// PendingUsers gets the IDs of the Users who are notified at `frequency` and have
+// Notifications waiting to be sent.
+func (m *NotificationModel) PendingUsers(frequency models.Frequency) ([]int64, error) {
+	stmt := `SELECT DISTINCT n.user_id
+			   FROM notification AS n
+			   JOIN user AS u ON n.user_id = u.id
+			  WHERE n.sent IS NULL
+			    AND u.notification_frequency = ?
+		   ORDER BY n.user_id`
+
+	rows, err := m.DB.Query(stmt, frequency)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var IDs []int64
+	for rows.Next() {
+		var id int64
+		err = rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+		IDs = append(IDs, id)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return IDs, nil
+}
+
+// MarkSent records that the Notifications identified by IDs were sent together in the
+// message identified by `messageRef`.
+func (m *NotificationModel) MarkSent(IDs []int64, messageRef string) error {
+	if len(IDs) == 0 {
+		return nil
+	}
+
+	args := []interface{}{messageRef, time.Now()}
+	for _, id := range IDs {
+		args = append(args, id)
+	}
+
+	stmt := `UPDATE notification
+				SET message_ref = ?, sent = ?
+			  WHERE id IN (?` + strings.Repeat(", ?", len(IDs)-1) + `)`
+
+	res, err := m.DB.Exec(stmt, args...)
+	if err != nil {
+		return err
+	}
+
+	a, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if a < 1 {
+		return models.ErrNoneAffected
+	}
+
+	return nil
+}
+
+// CountSent counts the messages sent to the User since `since`. A digest of several
+// Notifications counts as one message.
+func (m *NotificationModel) CountSent(userID int64, since time.Time) (int, error) {
+	var count int
+	stmt := `SELECT COUNT(DISTINCT message_ref)
+			   FROM notification
+			  WHERE user_id = ?
+			    AND sent >= ?`
+
+	err := m.DB.QueryRow(stmt, userID, since).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// LastSent gets the time the User was last sent a message, or the zero time if they never have been.
+func (m *NotificationModel) LastSent(userID int64) (time.Time, error) {
+	var sent sql.NullTime
+	err := m.DB.QueryRow(`SELECT MAX(sent) FROM notification WHERE user_id = ?`, userID).Scan(&sent)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return sent.Time, nil
+}
+
+// GetPolicy gets the NotificationPolicy saved by the User
+func (m *NotificationModel) GetPolicy(userID int64) (*models.NotificationPolicy, error) {
+	stmt := `SELECT user_id, max_per_day, min_interval_minutes, quiet_start, quiet_end
+			   FROM notification_policy
+			  WHERE user_id = ?`
+
+	p := &models.NotificationPolicy{}
+	err := m.DB.QueryRow(stmt, userID).Scan(&p.UserID, &p.MaxPerDay, &p.MinIntervalMinutes, &p.QuietStart, &p.QuietEnd)
+	if err == sql.ErrNoRows {
+		return nil, models.ErrNoRecord
+	} else if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// SavePolicy saves the NotificationPolicy for its User, replacing any they had before.
+func (m *NotificationModel) SavePolicy(policy *models.NotificationPolicy) (*models.NotificationPolicy, error) {
+	stmt := `INSERT INTO notification_policy (user_id, max_per_day, min_interval_minutes, quiet_start, quiet_end)
+				  VALUES (?, ?, ?, ?, ?)
+				  ON DUPLICATE KEY UPDATE
+					 max_per_day = VALUES(max_per_day),
+					 min_interval_minutes = VALUES(min_interval_minutes),
+					 quiet_start = VALUES(quiet_start),
+					 quiet_end = VALUES(quiet_end)`
+
+	_, err := m.DB.Exec(stmt, policy.UserID, policy.MaxPerDay, policy.MinIntervalMinutes, policy.QuietStart, policy.QuietEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.GetPolicy(policy.UserID)
+}
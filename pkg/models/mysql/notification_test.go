@@ -0,0 +1,71 @@
+package mysql
+
+import (
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationModel_Claim(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening stub DB connection", err)
+	}
+	defer db.Close()
+
+	created := time.Now()
+	cols := []string{"id", "user_id", "phone", "store_id", "name", "timezone", "flavor_id", "name", "body", "created"}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT id FROM user WHERE id = \? FOR UPDATE$`).
+		WithArgs(4).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+	mock.ExpectQuery(`^SELECT EXISTS\(SELECT 1 FROM notification WHERE user_id = \? AND sent IS NULL AND claimed_at > \?\)$`).
+		WithArgs(4, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"claimed"}).AddRow(0))
+	mock.ExpectExec(`^UPDATE notification SET claimed = \?, claimed_at = \? WHERE user_id = \? AND sent IS NULL$`).
+		WithArgs("claim-1", sqlmock.AnyArg(), 4).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+	mock.ExpectQuery(`^SELECT (.+) FROM notification AS n (.+) WHERE n.claimed = \? AND n.sent IS NULL`).
+		WithArgs("claim-1").
+		WillReturnRows(sqlmock.NewRows(cols).
+			AddRow(1, 4, "4045551212", 1, "Morellis On Moreland", "America/New_York", 3, "Coconut Jalapeno", "Coconut Jalapeno is in the cooler!", created.Add(-time.Hour)).
+			AddRow(2, 4, "4045551212", 1, "Morellis On Moreland", "America/New_York", 5, "Salted Caramel", "Salted Caramel is in the cooler!", created))
+
+	m := NotificationModel{DB: db}
+	pending, err := m.Claim(4, "claim-1")
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	require.Equal(t, int64(1), pending[0].ID)
+	require.Equal(t, "Salted Caramel", pending[1].FlavorName)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNotificationModel_ClaimHeld(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening stub DB connection", err)
+	}
+	defer db.Close()
+
+	// Nothing is claimed while an earlier claim is outstanding
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT id FROM user WHERE id = \? FOR UPDATE$`).
+		WithArgs(4).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+	mock.ExpectQuery(`^SELECT EXISTS\(SELECT 1 FROM notification WHERE user_id = \? AND sent IS NULL AND claimed_at > \?\)$`).
+		WithArgs(4, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"claimed"}).AddRow(1))
+	mock.ExpectRollback()
+
+	m := NotificationModel{DB: db}
+	pending, err := m.Claim(4, "claim-2")
+	require.NoError(t, err)
+	require.Empty(t, pending)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
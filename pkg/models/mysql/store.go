@@ -3,6 +3,7 @@ package mysql
 import (
 	"database/sql"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -17,7 +18,7 @@ type StoreModel struct {
 
 // List stores. Length of list is defined by `limit`, beginning at `offset`. List is sorted by `order`.
 func (s *StoreModel) List(limit int, offset int, order string) ([]*models.Store, error) {
-	stmt := fmt.Sprintf(`SELECT s.id, s.name, s.phone, s.email, s.url, s.address, s.city, s.state, s.zip, s.lat, s.lng, s.created
+	stmt := fmt.Sprintf(`SELECT s.id, s.name, s.phone, s.email, s.url, s.address, s.city, s.state, s.zip, s.lat, s.lng, s.timezone, s.created
 								  FROM store AS s
 							  ORDER BY %s
 								 LIMIT ?, ?`, order)
@@ -38,7 +39,7 @@ func (s *StoreModel) List(limit int, offset int, order string) ([]*models.Store,
 
 	for rows.Next() {
 		store := &models.Store{}
-		err = rows.Scan(&store.ID, &store.Name, &store.Phone, &store.Email, &store.URL, &store.Address, &store.City, &store.State, &store.Zip, &store.Lat, &store.Lng, &store.Created)
+		err = rows.Scan(&store.ID, &store.Name, &store.Phone, &store.Email, &store.URL, &store.Address, &store.City, &store.State, &store.Zip, &store.Lat, &store.Lng, &store.Timezone, &store.Created)
 		if err != nil {
 			return nil, err
 		}
@@ -88,18 +89,19 @@ func (s *StoreModel) Insert(name string, phone string, email string, url string,
 	}
 
 	store := &models.Store{
-		ID:      id,
-		Name:    name,
-		Phone:   phone,
-		Email:   email,
-		URL:     url,
-		Address: address,
-		City:    city,
-		State:   state,
-		Zip:     zip,
-		Lat:     lat,
-		Lng:     lng,
-		Created: created,
+		ID:       id,
+		Name:     name,
+		Phone:    phone,
+		Email:    email,
+		URL:      url,
+		Address:  address,
+		City:     city,
+		State:    state,
+		Zip:      zip,
+		Lat:      lat,
+		Lng:      lng,
+		Timezone: models.DEFAULT_TIMEZONE,
+		Created:  created,
 	}
 
 	return store, nil
@@ -107,12 +109,12 @@ func (s *StoreModel) Insert(name string, phone string, email string, url string,
 
 // Get a single Store by ID
 func (s *StoreModel) Get(id int) (*models.Store, error) {
-	stmt := `SELECT id, name, phone, email, url, phone, address, city, state, zip, lat, lng, created
+	stmt := `SELECT id, name, phone, email, url, phone, address, city, state, zip, lat, lng, timezone, created
 			   FROM store
 		  	  WHERE id = ?`
 
 	store := &models.Store{}
-	err := s.DB.QueryRow(stmt, id).Scan(&store.ID, &store.Name, &store.Phone, &store.Email, &store.URL, &store.Phone, &store.Address, &store.City, &store.State, &store.Zip, &store.Lat, &store.Lng, &store.Created)
+	err := s.DB.QueryRow(stmt, id).Scan(&store.ID, &store.Name, &store.Phone, &store.Email, &store.URL, &store.Phone, &store.Address, &store.City, &store.State, &store.Zip, &store.Lat, &store.Lng, &store.Timezone, &store.Created)
 
 	if err == sql.ErrNoRows {
 		return nil, models.ErrNoRecord
@@ -167,34 +169,76 @@ func (s *StoreModel) Count() int {
 }
 
 // ActivateFlavor adds an active Flavor to the indicated Position at a Store, deactivating the Flavor
-// currently occupying that Position.
+// currently occupying that Position. The Store is locked until both are done, so concurrent
+// changes to its lineup and Layout are made one at a time. A LayoutConflictError is returned if
+// the Position isn't available in the Store's Layout, and ErrFlavorConflict if the activation
+// couldn't be made because of a concurrent change, and may be retried.
 func (s *StoreModel) ActivateFlavor(storeID int64, flavorID int64, position int) error {
-	tx, _ := s.DB.Begin()
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
 	defer tx.Rollback()
 
+	err = lockStore(tx, storeID)
+	if err != nil {
+		return activationError(err)
+	}
+
+	layout, err := getLayout(tx, storeID)
+	if err != nil {
+		return err
+	}
+
+	if !layout.Available(position) {
+		return &models.LayoutConflictError{StoreID: storeID, Position: position}
+	}
+
 	stmt := `UPDATE flavor_store 
 				SET is_active = NULL, deactivated = CURRENT_TIMESTAMP 
 			  WHERE store_id = ?
-				AND position = ?`
+				AND position = ?
+				AND is_active = 1`
 
-	_, err := s.DB.Exec(stmt, storeID, position)
+	_, err = tx.Exec(stmt, storeID, position)
 	if err != nil {
-		tx.Rollback()
-		return err
+		return activationError(err)
 	}
 
 	stmt = `INSERT INTO flavor_store (store_id, flavor_id, position, is_active, activated)
 			VALUES(?, ?, ?, 1, CURRENT_TIMESTAMP)`
 
-	_, err = s.DB.Exec(stmt, storeID, flavorID, position)
+	_, err = tx.Exec(stmt, storeID, flavorID, position)
+	if err != nil {
+		return activationError(err)
+	}
+
+	return activationError(tx.Commit())
+}
+
+// lockStore locks the Store's row until the transaction ends, so changes to the Flavors active
+// at the Store, and to its Layout, are made one at a time.
+func lockStore(tx *sql.Tx, storeID int64) error {
+	var id int64
+	err := tx.QueryRow(`SELECT id FROM store WHERE id = ? FOR UPDATE`, storeID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return models.ErrNoRecord
+	}
+
+	return err
+}
+
+// activationError returns ErrDuplicateFlavor if a Flavor was already active in the Position, and
+// ErrFlavorConflict if the activation deadlocked or timed out waiting for a concurrent change.
+func activationError(err error) error {
 	if mysqlErr, ok := err.(*mysql.MySQLError); ok {
-		// If line 175 executed, this should never happen
-		if mysqlErr.Number == 1062 && strings.Contains(mysqlErr.Message, "uk_flavor_store_is_active_store_id_position_id") {
-			tx.Rollback()
+		switch {
+		case mysqlErr.Number == 1062 && strings.Contains(mysqlErr.Message, "uk_flavor_store_is_active_store_id_position_id"):
 			return models.ErrDuplicateFlavor
+		case mysqlErr.Number == 1205 || mysqlErr.Number == 1213:
+			return models.ErrFlavorConflict
 		}
 	}
-	tx.Commit()
 
 	return err
 }
@@ -206,7 +250,8 @@ func (s *StoreModel) DeactivateFlavor(storeID int64, flavorID int64) (bool, erro
 	stmt := `UPDATE flavor_store
 				SET is_active = NULL, deactivated = CURRENT_TIMESTAMP
 			  WHERE store_id = ?
-				AND flavor_id = ?`
+				AND flavor_id = ?
+				AND is_active = 1`
 	res, err := s.DB.Exec(stmt, storeID, flavorID)
 	if err != nil {
 		return false, err
@@ -231,7 +276,8 @@ func (s *StoreModel) DeactivateFlavorAtPosition(storeID int64, position int) (bo
 	stmt := `UPDATE flavor_store
 				SET is_active = NULL, deactivated = CURRENT_TIMESTAMP
 			  WHERE store_id = ?
-			    AND position = ?`
+			    AND position = ?
+			    AND is_active = 1`
 
 	res, err := s.DB.Exec(stmt, storeID, position)
 
@@ -279,27 +325,31 @@ func (s *StoreModel) GetActiveFlavors(storeID int64) ([]*models.Flavor, error) {
 		name        string
 		description string
 		created     time.Time
-		ingredient  models.Ingredient
+		iID         sql.NullInt64
+		iName       sql.NullString
 	)
 
 	for rows.Next() {
-		err = rows.Scan(&id, &name, &description, &created, &ingredient.ID, &ingredient.Name)
+		err = rows.Scan(&id, &name, &description, &created, &iID, &iName)
 		if err != nil {
 			return nil, err
 		}
 
-		if flavor != nil && flavor.ID == id {
-			flavor.Ingredients = append(flavor.Ingredients, ingredient)
-		} else {
+		if flavor == nil || flavor.ID != id {
 			flavor = &models.Flavor{
 				ID:          id,
 				Name:        name,
 				Description: description,
 				Created:     created,
-				Ingredients: []models.Ingredient{ingredient},
+				Ingredients: []models.Ingredient{},
 			}
 		}
 
+		// A Flavor without Ingredients is a single row with NULL ingredient columns
+		if iID.Valid {
+			flavor.Ingredients = append(flavor.Ingredients, models.Ingredient{ID: iID.Int64, Name: iName.String})
+		}
+
 		if flavor.ID != flavorID {
 			flavors = append(flavors, flavor)
 		}
@@ -313,3 +363,466 @@ func (s *StoreModel) GetActiveFlavors(storeID int64) ([]*models.Flavor, error) {
 
 	return flavors, nil
 }
+
+// ListLineups lists the Flavors active at each Store, with their Ingredients, ordered by Store
+// and Position. Stores with no active Flavors have an empty Lineup. A storeID of 0 lists the
+// Lineups of every Store.
+func (s *StoreModel) ListLineups(storeID int64) ([]*models.Lineup, error) {
+	stmt := `SELECT s.id, s.name, s.phone, s.email, s.url, s.address, s.city, s.state, s.zip, s.lat, s.lng, s.timezone, s.created,
+					fs.id, fs.position, fs.activated, f.id, f.name, f.description, f.created, i.id, i.name
+			   FROM store AS s
+		  LEFT JOIN flavor_store AS fs ON fs.store_id = s.id AND fs.is_active = 1
+		  LEFT JOIN flavor AS f ON f.id = fs.flavor_id
+		  LEFT JOIN flavor_ingredient AS fi ON fi.flavor_id = f.id
+		  LEFT JOIN ingredient AS i ON i.id = fi.ingredient_id`
+	args := []interface{}{}
+
+	if storeID > 0 {
+		stmt += ` WHERE s.id = ?`
+		args = append(args, storeID)
+	}
+
+	stmt += ` ORDER BY s.id, fs.position, fs.id, i.name`
+
+	rows, err := s.DB.Query(stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lineups := []*models.Lineup{}
+	var lineup *models.Lineup
+	var flavor *models.LineupFlavor
+	var flavorStoreID int64
+
+	for rows.Next() {
+		store := &models.Store{}
+		var (
+			fsID         sql.NullInt64
+			position     sql.NullInt64
+			activated    sql.NullTime
+			fID          sql.NullInt64
+			fName        sql.NullString
+			fDescription sql.NullString
+			fCreated     sql.NullTime
+			iID          sql.NullInt64
+			iName        sql.NullString
+		)
+
+		err = rows.Scan(&store.ID, &store.Name, &store.Phone, &store.Email, &store.URL, &store.Address, &store.City, &store.State, &store.Zip, &store.Lat, &store.Lng, &store.Timezone, &store.Created,
+			&fsID, &position, &activated, &fID, &fName, &fDescription, &fCreated, &iID, &iName)
+		if err != nil {
+			return nil, err
+		}
+
+		if lineup == nil || lineup.Store.ID != store.ID {
+			lineup = &models.Lineup{Store: store, Flavors: []*models.LineupFlavor{}}
+			lineups = append(lineups, lineup)
+		}
+
+		if !fsID.Valid {
+			continue
+		}
+
+		if flavor == nil || flavorStoreID != fsID.Int64 {
+			flavorStoreID = fsID.Int64
+			flavor = &models.LineupFlavor{
+				Position:  int(position.Int64),
+				Activated: activated.Time,
+				Flavor: &models.Flavor{
+					ID:          fID.Int64,
+					Name:        fName.String,
+					Description: fDescription.String,
+					Created:     fCreated.Time,
+					Ingredients: []models.Ingredient{},
+				},
+			}
+			lineup.Flavors = append(lineup.Flavors, flavor)
+		}
+
+		if iID.Valid {
+			flavor.Flavor.Ingredients = append(flavor.Flavor.Ingredients, models.Ingredient{ID: iID.Int64, Name: iName.String})
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	layouts, err := listLayouts(s.DB, storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, lineup := range lineups {
+		lineup.Layout = layouts[lineup.Store.ID]
+		if lineup.Layout == nil {
+			lineup.Layout = models.NewLayout(lineup.Store.ID, models.DEFAULT_POSITIONS, nil)
+		}
+	}
+
+	return lineups, nil
+}
+
+// GetLayout gets the Layout of the Store's cooler, which has DEFAULT_POSITIONS enabled and
+// unlabeled Positions if the Store hasn't set its own.
+func (s *StoreModel) GetLayout(storeID int64) (*models.Layout, error) {
+	return getLayout(s.DB, storeID)
+}
+
+// SaveLayout replaces the Layout of the Store's cooler. Only labeled or disabled Slots are stored.
+// The Store is locked as ActivateFlavor locks it, and a LayoutConflictError is returned if the
+// Layout would leave an active Flavor in a Position which is disabled, or no longer in the cooler.
+func (s *StoreModel) SaveLayout(layout *models.Layout) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = lockStore(tx, layout.StoreID)
+	if err != nil {
+		return activationError(err)
+	}
+
+	rows, err := tx.Query(`SELECT position FROM flavor_store WHERE store_id = ? AND is_active = 1 ORDER BY position`, layout.StoreID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var position int
+		err = rows.Scan(&position)
+		if err != nil {
+			return err
+		}
+
+		if !layout.Available(position) {
+			return &models.LayoutConflictError{StoreID: layout.StoreID, Position: position}
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	stmt := `INSERT INTO store_layout (store_id, positions, updated) VALUES (?, ?, ?)
+				 ON DUPLICATE KEY UPDATE positions = VALUES(positions), updated = VALUES(updated)`
+
+	_, err = tx.Exec(stmt, layout.StoreID, layout.Positions, time.Now())
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM store_position WHERE store_id = ?`, layout.StoreID)
+	if err != nil {
+		return err
+	}
+
+	for _, slot := range layout.Slots {
+		if slot.Label == "" && !slot.Disabled {
+			continue
+		}
+
+		var label sql.NullString
+		if slot.Label != "" {
+			label = sql.NullString{String: slot.Label, Valid: true}
+		}
+
+		stmt := `INSERT INTO store_position (store_id, position, label, disabled) VALUES (?, ?, ?, ?)`
+
+		_, err = tx.Exec(stmt, layout.StoreID, slot.Position, label, slot.Disabled)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// queryer is a *sql.DB or *sql.Tx
+type queryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// getLayout gets the Layout of the Store's cooler, or the default Layout if it hasn't set its own
+func getLayout(q queryer, storeID int64) (*models.Layout, error) {
+	layouts, err := listLayouts(q, storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if layout, ok := layouts[storeID]; ok {
+		return layout, nil
+	}
+
+	return models.NewLayout(storeID, models.DEFAULT_POSITIONS, nil), nil
+}
+
+// listLayouts gets the Layouts set by each Store, or only by the Store identified by storeID if it
+// isn't 0, keyed by Store ID.
+func listLayouts(q queryer, storeID int64) (map[int64]*models.Layout, error) {
+	where := ``
+	args := []interface{}{}
+
+	if storeID > 0 {
+		where = ` WHERE store_id = ?`
+		args = append(args, storeID)
+	}
+
+	rows, err := q.Query(`SELECT store_id, positions FROM store_layout`+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	positions := map[int64]int{}
+	for rows.Next() {
+		var id int64
+		var n int
+
+		err = rows.Scan(&id, &n)
+		if err != nil {
+			return nil, err
+		}
+		positions[id] = n
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	layouts := map[int64]*models.Layout{}
+	if len(positions) == 0 {
+		return layouts, nil
+	}
+
+	rows, err = q.Query(`SELECT store_id, position, IFNULL(label, ''), disabled FROM store_position`+where+` ORDER BY store_id, position`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	slots := map[int64][]models.Slot{}
+	for rows.Next() {
+		var id int64
+		var slot models.Slot
+
+		err = rows.Scan(&id, &slot.Position, &slot.Label, &slot.Disabled)
+		if err != nil {
+			return nil, err
+		}
+		slots[id] = append(slots[id], slot)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for id, n := range positions {
+		layouts[id] = models.NewLayout(id, n, slots[id])
+	}
+
+	return layouts, nil
+}
+
+// ReplaceLineup replaces the Flavors active at the Store with those in the lineup, which maps each
+// Position to the ID of the Flavor to be active in it. Positions not in the lineup are emptied.
+// Positions whose Flavor hasn't changed are left as they were, so their rotation continues.
+// Every change is made in a single transaction, with the Store locked as ActivateFlavor locks it,
+// and the changes made are returned. A LayoutConflictError is returned if any Position isn't
+// available in the Store's Layout.
+func (s *StoreModel) ReplaceLineup(storeID int64, lineup map[int]int64) (*models.LineupDiff, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	err = lockStore(tx, storeID)
+	if err != nil {
+		return nil, activationError(err)
+	}
+
+	layout, err := getLayout(tx, storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Positions are checked in order, so the conflict reported is always the first
+	requested := make([]int, 0, len(lineup))
+	for position := range lineup {
+		requested = append(requested, position)
+	}
+	sort.Ints(requested)
+
+	for _, position := range requested {
+		if !layout.Available(position) {
+			return nil, &models.LayoutConflictError{StoreID: storeID, Position: position}
+		}
+	}
+
+	stmt := `SELECT fs.id, fs.position, fs.activated, f.id, f.name
+			   FROM flavor_store AS fs
+			   JOIN flavor AS f ON f.id = fs.flavor_id
+			  WHERE fs.store_id = ?
+				AND fs.is_active = 1
+		   ORDER BY fs.position
+			    FOR UPDATE`
+
+	rows, err := tx.Query(stmt, storeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	active := map[int]*models.LineupFlavor{}
+	flavorStoreIDs := map[int]int64{}
+	positions := []int{}
+
+	for rows.Next() {
+		var id int64
+		lf := &models.LineupFlavor{Flavor: &models.Flavor{}}
+
+		err = rows.Scan(&id, &lf.Position, &lf.Activated, &lf.Flavor.ID, &lf.Flavor.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		active[lf.Position] = lf
+		flavorStoreIDs[lf.Position] = id
+		positions = append(positions, lf.Position)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	for position := range lineup {
+		if _, ok := active[position]; !ok {
+			positions = append(positions, position)
+		}
+	}
+	sort.Ints(positions)
+
+	diff := &models.LineupDiff{
+		Activated:   []*models.LineupFlavor{},
+		Deactivated: []*models.LineupFlavor{},
+		Unchanged:   []*models.LineupFlavor{},
+	}
+	now := time.Now()
+
+	// Positions are emptied before any are filled, so no Position has two active Flavors
+	for _, position := range positions {
+		current, ok := active[position]
+		if !ok {
+			continue
+		}
+
+		if flavorID, ok := lineup[position]; ok && flavorID == current.Flavor.ID {
+			diff.Unchanged = append(diff.Unchanged, current)
+			continue
+		}
+
+		stmt := `UPDATE flavor_store
+					SET is_active = NULL, deactivated = ?
+				  WHERE id = ?`
+
+		_, err = tx.Exec(stmt, now, flavorStoreIDs[position])
+		if err != nil {
+			return nil, activationError(err)
+		}
+
+		diff.Deactivated = append(diff.Deactivated, current)
+	}
+
+	for _, position := range positions {
+		flavorID, ok := lineup[position]
+		if !ok {
+			continue
+		}
+
+		if current, ok := active[position]; ok && current.Flavor.ID == flavorID {
+			continue
+		}
+
+		stmt := `INSERT INTO flavor_store (store_id, flavor_id, position, is_active, activated)
+				 VALUES (?, ?, ?, 1, ?)`
+
+		_, err = tx.Exec(stmt, storeID, flavorID, position, now)
+		if err != nil {
+			return nil, activationError(err)
+		}
+
+		diff.Activated = append(diff.Activated, &models.LineupFlavor{
+			Position:  position,
+			Activated: now,
+			Flavor:    &models.Flavor{ID: flavorID},
+		})
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, activationError(err)
+	}
+
+	return diff, nil
+}
+
+// ListRotations lists the Rotations of Flavors at Stores which were active at any time between
+// `from` and `to`, ordered by Store, Position and when they were activated. A storeID or
+// flavorID of 0 lists Rotations at every Store or of every Flavor.
+func (s *StoreModel) ListRotations(from time.Time, to time.Time, storeID int64, flavorID int64) ([]*models.Rotation, error) {
+	stmt := `SELECT fs.id, fs.store_id, s.name, fs.position, fs.activated, fs.deactivated, f.id, f.name
+			   FROM flavor_store AS fs
+			   JOIN store AS s ON s.id = fs.store_id
+			   JOIN flavor AS f ON f.id = fs.flavor_id
+			  WHERE fs.activated < ?
+				AND (fs.deactivated IS NULL OR fs.deactivated > ?)`
+	args := []interface{}{to, from}
+
+	if storeID > 0 {
+		stmt += ` AND fs.store_id = ?`
+		args = append(args, storeID)
+	}
+
+	if flavorID > 0 {
+		stmt += ` AND fs.flavor_id = ?`
+		args = append(args, flavorID)
+	}
+
+	stmt += ` ORDER BY fs.store_id, fs.position, fs.activated, fs.id`
+
+	rows, err := s.DB.Query(stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rotations := []*models.Rotation{}
+
+	for rows.Next() {
+		r := &models.Rotation{Flavor: &models.Flavor{}}
+		var deactivated sql.NullTime
+
+		err = rows.Scan(&r.ID, &r.StoreID, &r.StoreName, &r.Position, &r.Activated, &deactivated, &r.Flavor.ID, &r.Flavor.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		if deactivated.Valid {
+			r.Deactivated = &deactivated.Time
+		}
+
+		rotations = append(rotations, r)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return rotations, nil
+}
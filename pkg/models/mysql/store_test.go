@@ -2,10 +2,16 @@ package mysql
 
 import (
 	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"fmt"
+	"sync"
 	"testing"
 	"time"
 
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+
 	"github.com/jcorry/morellis/pkg/models"
 )
 
@@ -291,3 +297,474 @@ func TestStoreModel_DeactivateFlavorAtPosition(t *testing.T) {
 		})
 	}
 }
+
+func TestStoreModel_ListRotations(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening stub DB connection", err)
+	}
+	defer db.Close()
+
+	from := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	activated := from.Add(time.Hour * 12)
+	deactivated := activated.Add(time.Hour * 24)
+
+	cols := []string{"id", "store_id", "name", "position", "activated", "deactivated", "id", "name"}
+	rows := sqlmock.NewRows(cols).
+		AddRow(40, 3, "Midtown", 1, activated, deactivated, 5, "Vanilla").
+		AddRow(41, 3, "Midtown", 1, deactivated, nil, 6, "Pecan")
+
+	mock.ExpectQuery(`SELECT (.+) FROM flavor_store AS fs (.+) WHERE fs.activated < \? AND \(fs.deactivated IS NULL OR fs.deactivated > \?\) AND fs.store_id = \? ORDER BY`).
+		WithArgs(to, from, 3).
+		WillReturnRows(rows)
+
+	s := StoreModel{db}
+	rotations, err := s.ListRotations(from, to, 3, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rotations) != 2 {
+		t.Fatalf("Want 2 rotations; Got %d", len(rotations))
+	}
+	if rotations[0].Deactivated == nil || !rotations[0].Deactivated.Equal(deactivated) {
+		t.Errorf("Want deactivated %s; Got %v", deactivated, rotations[0].Deactivated)
+	}
+	if rotations[1].Deactivated != nil {
+		t.Errorf("Want active rotation; Got deactivated %s", rotations[1].Deactivated)
+	}
+	if rotations[1].Flavor.Name != "Pecan" || rotations[1].StoreName != "Midtown" {
+		t.Errorf("Want Pecan at Midtown; Got %s at %s", rotations[1].Flavor.Name, rotations[1].StoreName)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStoreModel_ListLineups(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening stub DB connection", err)
+	}
+	defer db.Close()
+
+	created := time.Date(2019, 3, 27, 0, 0, 0, 0, time.UTC)
+	activated := created.AddDate(2, 0, 0)
+
+	cols := []string{"id", "name", "phone", "email", "url", "address", "city", "state", "zip", "lat", "lng", "timezone", "created",
+		"id", "position", "activated", "id", "name", "description", "created", "id", "name"}
+	store := func(id int64, name string) []driver.Value {
+		return []driver.Value{id, name, "404-622-0210", "info@morellisicecream.com", "", "", "Atlanta", "GA", "30316", 33.7, -84.3, "America/New_York", created}
+	}
+	rows := sqlmock.NewRows(cols).
+		AddRow(append(store(1, "Moreland"), 40, 1, activated, 5, "Vanilla", "", created, 1, "milk")...).
+		AddRow(append(store(1, "Moreland"), 40, 1, activated, 5, "Vanilla", "", created, 2, "vanilla bean")...).
+		AddRow(append(store(1, "Moreland"), 41, 2, activated, 5, "Vanilla", "", created, 1, "milk")...).
+		AddRow(append(store(1, "Moreland"), 41, 2, activated, 5, "Vanilla", "", created, 2, "vanilla bean")...).
+		AddRow(append(store(1, "Moreland"), 42, 3, activated, 6, "Sorbet", "", created, nil, nil)...).
+		AddRow(append(store(2, "Dunwoody"), nil, nil, nil, nil, nil, nil, nil, nil, nil)...)
+
+	mock.ExpectQuery(`SELECT (.+) FROM store AS s LEFT JOIN flavor_store AS fs ON fs.store_id = s.id AND fs.is_active = 1 (.+) ORDER BY s.id, fs.position`).
+		WillReturnRows(rows)
+	mock.ExpectQuery(`SELECT store_id, positions FROM store_layout$`).
+		WillReturnRows(sqlmock.NewRows([]string{"store_id", "positions"}).AddRow(1, 10))
+	mock.ExpectQuery(`SELECT store_id, position, IFNULL\(label, ''\), disabled FROM store_position ORDER BY store_id, position`).
+		WillReturnRows(sqlmock.NewRows([]string{"store_id", "position", "label", "disabled"}).
+			AddRow(1, 1, "A1", false).
+			AddRow(1, 7, "", true))
+
+	s := StoreModel{db}
+	lineups, err := s.ListLineups(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(lineups) != 2 {
+		t.Fatalf("Want 2 lineups; Got %d", len(lineups))
+	}
+
+	// The same Flavor may be active in more than one Position
+	flavors := lineups[0].Flavors
+	if len(flavors) != 3 {
+		t.Fatalf("Want 3 flavors; Got %d", len(flavors))
+	}
+	for i, want := range []int{2, 2, 0} {
+		if flavors[i].Position != i+1 {
+			t.Errorf("Want position %d; Got %d", i+1, flavors[i].Position)
+		}
+		if len(flavors[i].Flavor.Ingredients) != want {
+			t.Errorf("Want %d ingredients in position %d; Got %d", want, i+1, len(flavors[i].Flavor.Ingredients))
+		}
+	}
+
+	if lineups[1].Store.Name != "Dunwoody" || len(lineups[1].Flavors) != 0 {
+		t.Errorf("Want an empty Dunwoody lineup; Got %d flavors at %s", len(lineups[1].Flavors), lineups[1].Store.Name)
+	}
+
+	layout := lineups[0].Layout
+	if layout.Positions != 10 || layout.Slots[0].Label != "A1" || !layout.Slots[6].Disabled {
+		t.Errorf("Want the Moreland layout; Got %+v", layout)
+	}
+	if lineups[1].Layout.Positions != models.DEFAULT_POSITIONS {
+		t.Errorf("Want the default layout at Dunwoody; Got %d positions", lineups[1].Layout.Positions)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStoreModel_ReplaceLineup(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening stub DB connection", err)
+	}
+	defer db.Close()
+
+	activated := time.Now().Add(-time.Hour * 24)
+	rows := sqlmock.NewRows([]string{"id", "position", "activated", "id", "name"}).
+		AddRow(40, 1, activated, 5, "Vanilla").
+		AddRow(41, 2, activated, 6, "Pecan").
+		AddRow(42, 3, activated, 7, "Mint")
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id FROM store WHERE id = \? FOR UPDATE`).
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	expectLayout(mock, 3, 0)
+	mock.ExpectQuery(`SELECT (.+) FROM flavor_store AS fs (.+) WHERE fs.store_id = \? AND fs.is_active = 1 ORDER BY fs.position FOR UPDATE`).
+		WithArgs(3).
+		WillReturnRows(rows)
+	// Positions are emptied before they're filled
+	mock.ExpectExec(`UPDATE flavor_store SET is_active = NULL, deactivated = \? WHERE id = \?`).
+		WithArgs(sqlmock.AnyArg(), 41).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE flavor_store SET is_active = NULL, deactivated = \? WHERE id = \?`).
+		WithArgs(sqlmock.AnyArg(), 42).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO flavor_store \(store_id, flavor_id, position, is_active, activated\)`).
+		WithArgs(3, 7, 2, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(43, 1))
+	mock.ExpectExec(`INSERT INTO flavor_store \(store_id, flavor_id, position, is_active, activated\)`).
+		WithArgs(3, 8, 4, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(44, 1))
+	mock.ExpectCommit()
+
+	s := StoreModel{db}
+	diff, err := s.ReplaceLineup(3, map[int]int64{1: 5, 2: 7, 4: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(diff.Unchanged) != 1 || diff.Unchanged[0].Position != 1 {
+		t.Errorf("Want position 1 unchanged; Got %d unchanged", len(diff.Unchanged))
+	}
+	if len(diff.Deactivated) != 2 || diff.Deactivated[1].Flavor.Name != "Mint" {
+		t.Errorf("Want positions 2 and 3 deactivated; Got %d deactivated", len(diff.Deactivated))
+	}
+	if len(diff.Activated) != 2 || diff.Activated[1].Position != 4 || diff.Activated[1].Flavor.ID != 8 {
+		t.Errorf("Want positions 2 and 4 activated; Got %d activated", len(diff.Activated))
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStoreModel_ReplaceLineupLayoutConflict(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening stub DB connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id FROM store WHERE id = \? FOR UPDATE`).
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	expectLayout(mock, 3, 2)
+	mock.ExpectRollback()
+
+	s := StoreModel{db}
+	_, err = s.ReplaceLineup(3, map[int]int64{1: 5, 3: 6})
+
+	var conflict *models.LayoutConflictError
+	if !errors.As(err, &conflict) || conflict.Position != 3 {
+		t.Errorf("Want LayoutConflictError in position 3; Got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStoreModel_ReplaceLineupRollsBack(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening stub DB connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id FROM store WHERE id = \? FOR UPDATE`).
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	expectLayout(mock, 3, 0)
+	mock.ExpectQuery(`SELECT (.+) FROM flavor_store AS fs`).
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "position", "activated", "id", "name"}))
+	mock.ExpectExec(`INSERT INTO flavor_store`).
+		WithArgs(3, 5, 1, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(40, 1))
+	mock.ExpectExec(`INSERT INTO flavor_store`).
+		WithArgs(3, 6, 2, sqlmock.AnyArg()).
+		WillReturnError(fmt.Errorf("insert failed"))
+	mock.ExpectRollback()
+
+	s := StoreModel{db}
+	_, err = s.ReplaceLineup(3, map[int]int64{1: 5, 2: 6})
+	if err == nil {
+		t.Fatal("Want error; Got nil")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStoreModel_ActivateFlavorTransaction(t *testing.T) {
+	lockQuery := `SELECT id FROM store WHERE id = \? FOR UPDATE`
+	updateStmt := `UPDATE flavor_store SET is_active = NULL, deactivated = CURRENT_TIMESTAMP WHERE store_id = \? AND position = \? AND is_active = 1`
+	insertStmt := `INSERT INTO flavor_store \(store_id, flavor_id, position, is_active, activated\)`
+
+	tests := []struct {
+		name    string
+		expect  func(mock sqlmock.Sqlmock)
+		wantErr error
+	}{
+		{
+			"activated",
+			func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockQuery).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				expectLayout(mock, 1, 0)
+				mock.ExpectExec(updateStmt).WithArgs(1, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(insertStmt).WithArgs(1, 5, 2).WillReturnResult(sqlmock.NewResult(40, 1))
+				mock.ExpectCommit()
+			},
+			nil,
+		},
+		{
+			"begin fails",
+			func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin().WillReturnError(fmt.Errorf("begin failed"))
+			},
+			fmt.Errorf("begin failed"),
+		},
+		{
+			"store not found",
+			func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockQuery).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectRollback()
+			},
+			models.ErrNoRecord,
+		},
+		{
+			"position not in layout",
+			func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockQuery).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				expectLayout(mock, 1, 1)
+				mock.ExpectRollback()
+			},
+			&models.LayoutConflictError{StoreID: 1, Position: 2},
+		},
+		{
+			"deadlock",
+			func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockQuery).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				expectLayout(mock, 1, 0)
+				mock.ExpectExec(updateStmt).WithArgs(1, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(insertStmt).WithArgs(1, 5, 2).WillReturnError(&mysql.MySQLError{Number: 1213, Message: "Deadlock found"})
+				mock.ExpectRollback()
+			},
+			models.ErrFlavorConflict,
+		},
+		{
+			"duplicate",
+			func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockQuery).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				expectLayout(mock, 1, 0)
+				mock.ExpectExec(updateStmt).WithArgs(1, 2).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(insertStmt).WithArgs(1, 5, 2).WillReturnError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry for key 'uk_flavor_store_is_active_store_id_position_id'"})
+				mock.ExpectRollback()
+			},
+			models.ErrDuplicateFlavor,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening stub DB connection", err)
+			}
+			defer db.Close()
+
+			tt.expect(mock)
+
+			s := StoreModel{db}
+			err = s.ActivateFlavor(1, 5, 2)
+			if fmt.Sprint(err) != fmt.Sprint(tt.wantErr) {
+				t.Errorf("Want err %v; Got err %v", tt.wantErr, err)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestStoreModel_ActivateFlavorConcurrently(t *testing.T) {
+	if testing.Short() {
+		t.Skip("mysql: skipping integration test")
+	}
+	db := NewTestDB(t)
+
+	s := StoreModel{db}
+
+	const activations = 50
+	errs := make(chan error, activations)
+
+	var wg sync.WaitGroup
+	for i := 0; i < activations; i++ {
+		wg.Add(1)
+		go func(flavorID int64) {
+			defer wg.Done()
+			errs <- s.ActivateFlavor(1, flavorID, 1)
+		}(int64(i%2 + 1))
+	}
+	wg.Wait()
+	close(errs)
+
+	succeeded := 0
+	for err := range errs {
+		if err == nil {
+			succeeded++
+		} else if err != models.ErrFlavorConflict {
+			t.Errorf("Want nil or models.ErrFlavorConflict; Got %v", err)
+		}
+	}
+
+	var active, rows int
+	err := db.QueryRow(`SELECT COUNT(is_active), COUNT(id) FROM flavor_store WHERE store_id = 1 AND position = 1`).Scan(&active, &rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if active != 1 {
+		t.Errorf("Want 1 active flavor in the position; Got %d", active)
+	}
+	if rows != succeeded {
+		t.Errorf("Want a row for each of the %d activations; Got %d", succeeded, rows)
+	}
+}
+
+func TestStoreModel_SaveLayout(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening stub DB connection", err)
+	}
+	defer db.Close()
+
+	layout := models.NewLayout(3, 4, []models.Slot{{Position: 1, Label: "A1"}, {Position: 3, Disabled: true}})
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id FROM store WHERE id = \? FOR UPDATE`).
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	mock.ExpectQuery(`SELECT position FROM flavor_store WHERE store_id = \? AND is_active = 1`).
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"position"}).AddRow(1).AddRow(2))
+	mock.ExpectExec(`INSERT INTO store_layout \(store_id, positions, updated\) VALUES \(\?, \?, \?\) ON DUPLICATE KEY UPDATE`).
+		WithArgs(3, 4, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM store_position WHERE store_id = \?`).
+		WithArgs(3).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	// Only the labeled and disabled Slots are stored
+	mock.ExpectExec(`INSERT INTO store_position`).
+		WithArgs(3, 1, "A1", false).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO store_position`).
+		WithArgs(3, 3, nil, true).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	s := StoreModel{db}
+	err = s.SaveLayout(layout)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStoreModel_SaveLayoutConflict(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening stub DB connection", err)
+	}
+	defer db.Close()
+
+	// A Flavor is active in the Position being disabled
+	layout := models.NewLayout(3, 4, []models.Slot{{Position: 3, Disabled: true}})
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id FROM store WHERE id = \? FOR UPDATE`).
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	mock.ExpectQuery(`SELECT position FROM flavor_store WHERE store_id = \? AND is_active = 1`).
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"position"}).AddRow(1).AddRow(3))
+	mock.ExpectRollback()
+
+	s := StoreModel{db}
+	err = s.SaveLayout(layout)
+
+	var conflict *models.LayoutConflictError
+	if !errors.As(err, &conflict) || conflict.Position != 3 {
+		t.Errorf("Want LayoutConflictError in position 3; Got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// expectLayout expects the Layout of the Store to be read, finding one with the number of
+// Positions, or none, so the default Layout is used, if it's 0.
+func expectLayout(mock sqlmock.Sqlmock, storeID int64, positions int) {
+	rows := sqlmock.NewRows([]string{"store_id", "positions"})
+	if positions > 0 {
+		rows.AddRow(storeID, positions)
+	}
+
+	mock.ExpectQuery(`SELECT store_id, positions FROM store_layout WHERE store_id = \?`).
+		WithArgs(storeID).
+		WillReturnRows(rows)
+
+	if positions > 0 {
+		mock.ExpectQuery(`SELECT store_id, position, IFNULL\(label, ''\), disabled FROM store_position WHERE store_id = \?`).
+			WithArgs(storeID).
+			WillReturnRows(sqlmock.NewRows([]string{"store_id", "position", "label", "disabled"}))
+	}
+}
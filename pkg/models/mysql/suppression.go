@@ -0,0 +1,122 @@
+package mysql
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jcorry/morellis/pkg/models"
+)
+
+// SuppressionModel is the list of phone numbers which have opted out of receiving messages.
+type SuppressionModel struct {
+	DB *sql.DB
+}
+
+// IsSuppressed reports whether the phone number has opted out of receiving messages.
+func (m *SuppressionModel) IsSuppressed(phone string) (bool, error) {
+	var suppressed bool
+	stmt := `SELECT EXISTS(SELECT 1 FROM suppression WHERE phone = ?)`
+
+	err := m.DB.QueryRow(stmt, NormalizePhone(phone)).Scan(&suppressed)
+	if err != nil {
+		return false, err
+	}
+
+	return suppressed, nil
+}
+
+// Suppress opts the phone number out of receiving messages. `keyword` is the word the customer
+// texted to opt out. Suppressing a number which is already suppressed replaces its keyword.
+func (m *SuppressionModel) Suppress(phone string, keyword string) (*models.Suppression, error) {
+	created := time.Now()
+	stmt := `INSERT INTO suppression (phone, keyword, created) VALUES (?, ?, ?)
+				 ON DUPLICATE KEY UPDATE keyword = VALUES(keyword)`
+
+	_, err := m.DB.Exec(stmt, NormalizePhone(phone), keyword, created)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.get(phone)
+}
+
+// Unsuppress opts the phone number back in to receiving messages.
+func (m *SuppressionModel) Unsuppress(phone string) error {
+	res, err := m.DB.Exec(`DELETE FROM suppression WHERE phone = ?`, NormalizePhone(phone))
+	if err != nil {
+		return err
+	}
+
+	a, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if a < 1 {
+		return models.ErrNoneAffected
+	}
+
+	return nil
+}
+
+// List suppressed phone numbers, most recent first, limiting results by `limit` beginning at `offset`.
+func (m *SuppressionModel) List(limit int, offset int) ([]*models.Suppression, error) {
+	stmt := `SELECT id, phone, keyword, created
+			   FROM suppression
+		   ORDER BY created DESC, id DESC
+			  LIMIT ?, ?`
+
+	if limit < 1 {
+		limit = DEFAULT_LIMIT
+	}
+
+	rows, err := m.DB.Query(stmt, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	suppressions := []*models.Suppression{}
+	for rows.Next() {
+		s := &models.Suppression{}
+		err = rows.Scan(&s.ID, &s.Phone, &s.Keyword, &s.Created)
+		if err != nil {
+			return nil, err
+		}
+		suppressions = append(suppressions, s)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return suppressions, nil
+}
+
+// Count gets the total number of suppressed phone numbers
+func (m *SuppressionModel) Count() int {
+	var count int
+	row := m.DB.QueryRow(`SELECT COUNT(id) FROM suppression`)
+
+	err := row.Scan(&count)
+	if err != nil {
+		return 0
+	}
+
+	return count
+}
+
+func (m *SuppressionModel) get(phone string) (*models.Suppression, error) {
+	stmt := `SELECT id, phone, keyword, created
+			   FROM suppression
+			  WHERE phone = ?`
+
+	s := &models.Suppression{}
+	err := m.DB.QueryRow(stmt, NormalizePhone(phone)).Scan(&s.ID, &s.Phone, &s.Keyword, &s.Created)
+	if err == sql.ErrNoRows {
+		return nil, models.ErrNoRecord
+	} else if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
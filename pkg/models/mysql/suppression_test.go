@@ -0,0 +1,47 @@
+package mysql
+
+import (
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jcorry/morellis/pkg/models"
+)
+
+func TestSuppressionModel_IsSuppressed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening stub DB connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`^SELECT EXISTS\(SELECT 1 FROM suppression WHERE phone = \?\)$`).
+		WithArgs("4045551212").
+		WillReturnRows(sqlmock.NewRows([]string{"suppressed"}).AddRow(1))
+
+	m := SuppressionModel{DB: db}
+	suppressed, err := m.IsSuppressed("(404) 555-1212")
+	require.NoError(t, err)
+	require.True(t, suppressed)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSuppressionModel_Unsuppress(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening stub DB connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec(`^DELETE FROM suppression WHERE phone = \?$`).
+		WithArgs("4045551212").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	m := SuppressionModel{DB: db}
+	err = m.Unsuppress("404-555-1212")
+	require.Equal(t, models.ErrNoneAffected, err)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
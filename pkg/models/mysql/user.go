@@ -2,7 +2,9 @@ package mysql
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"regexp"
@@ -26,13 +28,22 @@ type UserModel struct {
 }
 
 const (
-	DEFAULT_LIMIT         int = 25
-	PW_HASH_COST          int = 12
-	AUTH_TOKEN_KEY_PREFIX     = `auth-token`
+	DEFAULT_LIMIT             int = 25
+	PW_HASH_COST              int = 12
+	AUTH_TOKEN_KEY_PREFIX         = `auth-token`
+	REFRESH_TOKEN_KEY_PREFIX      = `refresh-token`
+	REVOKED_TOKEN_KEY_PREFIX      = `revoked-token`
+	AUTH_REQUEST_KEY_PREFIX       = `auth-requests`
+	AUTH_CODE_KEY_PREFIX          = `auth-code`
+	PASSWORD_RESET_KEY_PREFIX     = `password-reset`
+	VERIFICATION_KEY_PREFIX       = `email-verification`
 )
 
 var (
-	ErrNoAuthTokenFound = errors.New("no auth token found")
+	ErrNoAuthTokenFound          = errors.New("no auth token found")
+	ErrNoRefreshTokenFound       = errors.New("no refresh token found")
+	ErrNoPasswordResetTokenFound = errors.New("no password reset token found")
+	ErrNoVerificationTokenFound  = errors.New("no email verification token found")
 )
 
 // Insert a new User
@@ -89,6 +100,7 @@ func (u *UserModel) Insert(uid uuid.UUID, firstName models.NullString, lastName
 		LastName:  lastName,
 		Email:     email,
 		Phone:     phone,
+		Frequency: models.FREQUENCY_IMMEDIATE,
 		Status:    userStatus.Slug(),
 		Created:   created,
 	}
@@ -103,13 +115,19 @@ func (u *UserModel) Update(user *models.User) (*models.User, error) {
 			last_name = ?,
 			email = ?,
 			phone = ?,
+			notification_frequency = ?,
 			status_id = ?
 		WHERE id = ?`
 
 	var userStatus models.UserStatus
 	userStatusID := userStatus.GetID(user.Status)
 
-	_, err := u.DB.Exec(stmt, user.FirstName.String, user.LastName.String, user.Email.String, NormalizePhone(user.Phone), userStatusID, user.ID)
+	frequency := user.Frequency
+	if frequency == "" {
+		frequency = models.FREQUENCY_IMMEDIATE
+	}
+
+	_, err := u.DB.Exec(stmt, user.FirstName.String, user.LastName.String, user.Email.String, NormalizePhone(user.Phone), frequency, userStatusID, user.ID)
 	if err != nil {
 		if mysqlErr, ok := err.(*mysql.MySQLError); ok {
 			if mysqlErr.Number == 1062 && strings.Contains(mysqlErr.Message, "uk_user_email") {
@@ -132,13 +150,13 @@ func (u *UserModel) Update(user *models.User) (*models.User, error) {
 
 // Get a single User by ID
 func (u *UserModel) Get(id int) (*models.User, error) {
-	stmt := `SELECT u.id, u.uuid, u.first_name, u.last_name, u.email, u.phone, s.slug, u.created
+	stmt := `SELECT u.id, u.uuid, u.first_name, u.last_name, u.email, u.phone, u.notification_frequency, s.slug, u.created
 			   FROM user AS u
 		  LEFT JOIN ref_user_status AS s ON u.status_id = s.id
 			  WHERE u.id = ?`
 
 	user := &models.User{}
-	err := u.DB.QueryRow(stmt, id).Scan(&user.ID, &user.UUID, &user.FirstName, &user.LastName, &user.Email, &user.Phone, &user.Status, &user.Created)
+	err := u.DB.QueryRow(stmt, id).Scan(&user.ID, &user.UUID, &user.FirstName, &user.LastName, &user.Email, &user.Phone, &user.Frequency, &user.Status, &user.Created)
 
 	if err == sql.ErrNoRows {
 		return nil, models.ErrNoRecord
@@ -151,7 +169,7 @@ func (u *UserModel) Get(id int) (*models.User, error) {
 
 // Get a single User by UUID
 func (u *UserModel) GetByUUID(uuid uuid.UUID) (*models.User, error) {
-	stmt := `SELECT u.id, u.uuid, u.first_name, u.last_name, u.email, u.phone, s.slug, u.created, pu.id AS "pu_id", p.id AS "p_id", p.name
+	stmt := `SELECT u.id, u.uuid, u.first_name, u.last_name, u.email, u.phone, u.notification_frequency, s.slug, u.created, pu.id AS "pu_id", p.id AS "p_id", p.name
 			   FROM user AS u
 		  LEFT JOIN ref_user_status AS s ON u.status_id = s.id
 		  	   JOIN permission_user AS pu ON pu.user_id = u.id
@@ -170,7 +188,7 @@ func (u *UserModel) GetByUUID(uuid uuid.UUID) (*models.User, error) {
 	if rows.Next() {
 		p := &models.UserPermission{}
 
-		err = rows.Scan(&user.ID, &user.UUID, &user.FirstName, &user.LastName, &user.Email, &user.Phone, &user.Status, &user.Created, &p.UserPermissionID, &p.ID, &p.Name)
+		err = rows.Scan(&user.ID, &user.UUID, &user.FirstName, &user.LastName, &user.Email, &user.Phone, &user.Frequency, &user.Status, &user.Created, &p.UserPermissionID, &p.ID, &p.Name)
 
 		if err != nil {
 			return nil, err
@@ -179,7 +197,7 @@ func (u *UserModel) GetByUUID(uuid uuid.UUID) (*models.User, error) {
 		user.Permissions = append(user.Permissions, *p)
 
 		for rows.Next() {
-			err = rows.Scan(&user.ID, &user.UUID, &user.FirstName, &user.LastName, &user.Email, &user.Phone, &user.Status, &user.Created, &p.UserPermissionID, &p.ID, &p.Name)
+			err = rows.Scan(&user.ID, &user.UUID, &user.FirstName, &user.LastName, &user.Email, &user.Phone, &user.Frequency, &user.Status, &user.Created, &p.UserPermissionID, &p.ID, &p.Name)
 
 			if err != nil {
 				return nil, err
@@ -196,14 +214,14 @@ func (u *UserModel) GetByUUID(uuid uuid.UUID) (*models.User, error) {
 func (u *UserModel) GetByCredentials(c models.Credentials) (*models.User, error) {
 	var pwHash []byte = nil
 
-	stmt := `SELECT u.id, u.uuid, u.first_name, u.last_name, u.email, u.hashed_password, u.phone, s.slug, u.created
+	stmt := `SELECT u.id, u.uuid, u.first_name, u.last_name, u.email, u.hashed_password, u.phone, u.notification_frequency, s.slug, u.created
 			   FROM user AS u
 		  LEFT JOIN ref_user_status AS s ON u.status_id = s.id
 			  WHERE u.email = ?`
 
 	user := &models.User{}
 
-	err := u.DB.QueryRow(stmt, c.Email).Scan(&user.ID, &user.UUID, &user.FirstName, &user.LastName, &user.Email, &pwHash, &user.Phone, &user.Status, &user.Created)
+	err := u.DB.QueryRow(stmt, c.Email).Scan(&user.ID, &user.UUID, &user.FirstName, &user.LastName, &user.Email, &pwHash, &user.Phone, &user.Frequency, &user.Status, &user.Created)
 
 	if err == sql.ErrNoRows {
 		return nil, models.ErrInvalidCredentials
@@ -222,18 +240,61 @@ func (u *UserModel) GetByCredentials(c models.Credentials) (*models.User, error)
 	return user, nil
 }
 
+// GetByEmail retrieves a user by their email address
+func (u *UserModel) GetByEmail(email string) (*models.User, error) {
+	stmt := `SELECT u.id, u.uuid, u.first_name, u.last_name, u.email, u.phone, u.notification_frequency, s.slug, u.created
+			   FROM user AS u
+		  LEFT JOIN ref_user_status AS s ON u.status_id = s.id
+			  WHERE u.email = ?`
+
+	user := &models.User{}
+	err := u.DB.QueryRow(stmt, email).Scan(&user.ID, &user.UUID, &user.FirstName, &user.LastName, &user.Email, &user.Phone, &user.Frequency, &user.Status, &user.Created)
+
+	if err == sql.ErrNoRows {
+		return nil, models.ErrNoRecord
+	} else if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// UpdatePassword replaces the User's password
+func (u *UserModel) UpdatePassword(userID int64, password string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), PW_HASH_COST)
+	if err != nil {
+		return err
+	}
+
+	res, err := u.DB.Exec(`UPDATE user SET hashed_password = ? WHERE id = ?`, hashedPassword, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows < 1 {
+		return models.ErrNoneAffected
+	}
+
+	return nil
+}
+
 // GetByPhone retrieves a user by their phone number
 func (u *UserModel) GetByPhone(phone string) (*models.User, error) {
 	reg := regexp.MustCompile("[^0-9]")
 	phone = reg.ReplaceAllString(phone, "")
 
-	stmt := `SELECT u.id, u.uuid, u.first_name, u.last_name, u.email, u.phone, s.slug, u.created
+	stmt := `SELECT u.id, u.uuid, u.first_name, u.last_name, u.email, u.phone, u.notification_frequency, s.slug, u.created
 			   FROM user AS u
 		  LEFT JOIN ref_user_status AS s ON u.status_id = s.id
 			  WHERE REGEXP_REPLACE(u.phone, '[^0-9]', '') = ?`
 
 	user := &models.User{}
-	err := u.DB.QueryRow(stmt, NormalizePhone(phone)).Scan(&user.ID, &user.UUID, &user.FirstName, &user.LastName, &user.Email, &user.Phone, &user.Status, &user.Created)
+	err := u.DB.QueryRow(stmt, NormalizePhone(phone)).Scan(&user.ID, &user.UUID, &user.FirstName, &user.LastName, &user.Email, &user.Phone, &user.Frequency, &user.Status, &user.Created)
 
 	if err == sql.ErrNoRows {
 		return nil, models.ErrNoRecord
@@ -249,21 +310,146 @@ func (u *UserModel) SaveAuthToken(token string, userID int) error {
 	return u.Redis.Set(context.Background(), fmt.Sprintf(`%s:%s`, AUTH_TOKEN_KEY_PREFIX, token), userID, time.Second*300).Err()
 }
 
-// GetByAuthToken uses an auth token to look up the user ID in Redis, then get the user
-// from MySQL to return
-func (u *UserModel) GetByAuthToken(token string) (*models.User, error) {
-	id, err := u.Redis.Get(context.Background(), fmt.Sprintf(`%s:%s`, AUTH_TOKEN_KEY_PREFIX, token)).Result()
+// ConsumeAuthToken deletes the auth token from Redis and gets the User it was issued to from
+// MySQL. Each auth token can only be used once.
+func (u *UserModel) ConsumeAuthToken(token string) (*models.User, error) {
+	return u.consumeToken(AUTH_TOKEN_KEY_PREFIX, token, ErrNoAuthTokenFound)
+}
+
+// countAuthRequestScript increments the count of requests at KEYS[1], starting the window of
+// ARGV[1] milliseconds it expires after with the first request. Running as a script, the count
+// can't be left without an expiry.
+var countAuthRequestScript = redis.NewScript(`
+local n = redis.call("INCR", KEYS[1])
+if n == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+
+return n
+`)
+
+// CountAuthRequest counts a request to log in by the phone number, and returns the number of
+// requests it has made in the current window, which begins with its first request and lasts
+// for `window`.
+func (u *UserModel) CountAuthRequest(phone string, window time.Duration) (int64, error) {
+	key := fmt.Sprintf(`%s:%s`, AUTH_REQUEST_KEY_PREFIX, NormalizePhone(phone))
+
+	return countAuthRequestScript.Run(context.Background(), u.Redis, []string{key}, window.Milliseconds()).Int64()
+}
+
+// SaveAuthCode writes the one time code the User can log in with to Redis, replacing any
+// previous code, to expire after ttl. Only a hash of the code is stored.
+func (u *UserModel) SaveAuthCode(userID int64, code string, ttl time.Duration) error {
+	ctx := context.Background()
+	key := fmt.Sprintf(`%s:%d`, AUTH_CODE_KEY_PREFIX, userID)
+
+	_, err := u.Redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, key)
+		pipe.HSet(ctx, key, "code", hashAuthCode(code), "attempts", 0)
+		pipe.Expire(ctx, key, ttl)
+		return nil
+	})
+
+	return err
+}
+
+// consumeAuthCodeScript compares the hash of a guess (ARGV[1]) with the hash of the code saved
+// at KEYS[1], deleting the code if they match, or if the guess is wrong and ARGV[2] wrong
+// guesses have been made. It never creates the key, so an expired code stays expired. Running
+// as a script, concurrent guesses are counted one at a time.
+var consumeAuthCodeScript = redis.NewScript(`
+local saved = redis.call("HGET", KEYS[1], "code")
+if not saved then
+	return 0
+end
+
+if saved == ARGV[1] then
+	redis.call("DEL", KEYS[1])
+	return 1
+end
+
+local attempts = redis.call("HINCRBY", KEYS[1], "attempts", 1)
+if attempts >= tonumber(ARGV[2]) then
+	redis.call("DEL", KEYS[1])
+end
+
+return 0
+`)
+
+// ConsumeAuthCode reports whether code is the User's one time code, deleting it if it is. The
+// code is also deleted after maxAttempts wrong guesses.
+func (u *UserModel) ConsumeAuthCode(userID int64, code string, maxAttempts int) (bool, error) {
+	key := fmt.Sprintf(`%s:%d`, AUTH_CODE_KEY_PREFIX, userID)
+
+	n, err := consumeAuthCodeScript.Run(context.Background(), u.Redis, []string{key}, hashAuthCode(code), maxAttempts).Int()
 	if err != nil {
-		if err == redis.Nil {
-			return nil, ErrNoAuthTokenFound
-		}
+		return false, err
 	}
 
-	if id == "" {
-		return nil, ErrNoAuthTokenFound
+	return n == 1, nil
+}
+
+// hashAuthCode hashes a one time code, so that comparing codes in Redis doesn't take longer the
+// more of a guess is right.
+func hashAuthCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// SaveRefreshToken writes the refresh token to redis, to expire after ttl
+func (u *UserModel) SaveRefreshToken(token string, userID int64, ttl time.Duration) error {
+	return u.Redis.Set(context.Background(), fmt.Sprintf(`%s:%s`, REFRESH_TOKEN_KEY_PREFIX, token), userID, ttl).Err()
+}
+
+// ConsumeRefreshToken deletes the refresh token from redis and returns the User it was issued to.
+// Each refresh token can only be consumed once.
+func (u *UserModel) ConsumeRefreshToken(token string) (*models.User, error) {
+	return u.consumeToken(REFRESH_TOKEN_KEY_PREFIX, token, ErrNoRefreshTokenFound)
+}
+
+// SavePasswordResetToken writes the token the User can reset their password with to Redis, to
+// expire after ttl
+func (u *UserModel) SavePasswordResetToken(token string, userID int64, ttl time.Duration) error {
+	return u.Redis.Set(context.Background(), fmt.Sprintf(`%s:%s`, PASSWORD_RESET_KEY_PREFIX, token), userID, ttl).Err()
+}
+
+// ConsumePasswordResetToken deletes the password reset token from Redis and returns the User it
+// was issued to. Each token can only be used once.
+func (u *UserModel) ConsumePasswordResetToken(token string) (*models.User, error) {
+	return u.consumeToken(PASSWORD_RESET_KEY_PREFIX, token, ErrNoPasswordResetTokenFound)
+}
+
+// SaveVerificationToken writes the token the User can verify their email address with to Redis,
+// to expire after ttl
+func (u *UserModel) SaveVerificationToken(token string, userID int64, ttl time.Duration) error {
+	return u.Redis.Set(context.Background(), fmt.Sprintf(`%s:%s`, VERIFICATION_KEY_PREFIX, token), userID, ttl).Err()
+}
+
+// ConsumeVerificationToken deletes the email verification token from Redis and returns the User
+// it was issued to. Each token can only be used once.
+func (u *UserModel) ConsumeVerificationToken(token string) (*models.User, error) {
+	return u.consumeToken(VERIFICATION_KEY_PREFIX, token, ErrNoVerificationTokenFound)
+}
+
+// consumeToken deletes the token stored under prefix from Redis and gets the User it was issued
+// to from MySQL, or returns notFound if there is no such token.
+func (u *UserModel) consumeToken(prefix string, token string, notFound error) (*models.User, error) {
+	ctx := context.Background()
+	key := fmt.Sprintf(`%s:%s`, prefix, token)
+
+	var get *redis.StringCmd
+	_, err := u.Redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, key)
+		pipe.Del(ctx, key)
+		return nil
+	})
+	if err == redis.Nil {
+		return nil, notFound
+	} else if err != nil {
+		return nil, err
 	}
 
-	userID, err := strconv.Atoi(id)
+	userID, err := strconv.Atoi(get.Val())
 	if err != nil {
 		return nil, err
 	}
@@ -271,6 +457,26 @@ func (u *UserModel) GetByAuthToken(token string) (*models.User, error) {
 	return u.Get(userID)
 }
 
+// RevokeToken records the JWT identified by jti as revoked. The record expires after ttl, once
+// the JWT would have expired anyway. Nothing is recorded if the JWT has already expired.
+func (u *UserModel) RevokeToken(jti string, ttl time.Duration) error {
+	if ttl <= 0 {
+		return nil
+	}
+
+	return u.Redis.Set(context.Background(), fmt.Sprintf(`%s:%s`, REVOKED_TOKEN_KEY_PREFIX, jti), 1, ttl).Err()
+}
+
+// IsTokenRevoked reports whether the JWT identified by jti has been revoked
+func (u *UserModel) IsTokenRevoked(jti string) (bool, error) {
+	n, err := u.Redis.Exists(context.Background(), fmt.Sprintf(`%s:%s`, REVOKED_TOKEN_KEY_PREFIX, jti)).Result()
+	if err != nil {
+		return false, err
+	}
+
+	return n > 0, nil
+}
+
 // List Users limiting results by `limit` beginning at `offset` and ordered by `order`
 func (u *UserModel) List(limit int, offset int, order string) ([]*models.User, error) {
 	orderOpts := map[string]string{
@@ -287,7 +493,7 @@ func (u *UserModel) List(limit int, offset int, order string) ([]*models.User, e
 		order = "created"
 	}
 
-	stmt := fmt.Sprintf(`SELECT u.id, u.uuid, first_name, last_name, email, phone, s.slug, u.created
+	stmt := fmt.Sprintf(`SELECT u.id, u.uuid, first_name, last_name, email, phone, notification_frequency, s.slug, u.created
 			   FROM user AS u
 		  LEFT JOIN ref_user_status AS s ON u.status_id = s.id
 		   ORDER BY %s
@@ -308,7 +514,7 @@ func (u *UserModel) List(limit int, offset int, order string) ([]*models.User, e
 
 	for rows.Next() {
 		u := &models.User{}
-		err = rows.Scan(&u.ID, &u.UUID, &u.FirstName, &u.LastName, &u.Email, &u.Phone, &u.Status, &u.Created)
+		err = rows.Scan(&u.ID, &u.UUID, &u.FirstName, &u.LastName, &u.Email, &u.Phone, &u.Frequency, &u.Status, &u.Created)
 		if err != nil {
 			return nil, err
 		}
@@ -513,14 +719,151 @@ func (u *UserModel) UpdatePermissions(userID int, permissions []*models.UserPerm
 	return nil
 }
 
+// GetRoles gets the Roles granted to the User
+func (u *UserModel) GetRoles(userID int64) ([]models.UserRole, error) {
+	stmt := `SELECT id, role, IFNULL(store_id, 0), created
+			   FROM role_user
+			  WHERE user_id = ?
+		   ORDER BY id`
+
+	rows, err := u.DB.Query(stmt, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	userRoles := []models.UserRole{}
+
+	for rows.Next() {
+		var ur models.UserRole
+		err = rows.Scan(&ur.UserRoleID, &ur.Role, &ur.StoreID, &ur.Created)
+		if err != nil {
+			return nil, err
+		}
+		userRoles = append(userRoles, ur)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return userRoles, nil
+}
+
+// AddRole grants the Role to the User at the Store identified by storeID, or at every Store if
+// storeID is 0.
+func (u *UserModel) AddRole(userID int64, role string, storeID int64) (*models.UserRole, error) {
+	created := time.Now()
+
+	var store sql.NullInt64
+	if storeID > 0 {
+		store = sql.NullInt64{Int64: storeID, Valid: true}
+	}
+
+	stmt := `INSERT INTO role_user (user_id, role, store_id, created) VALUES (?, ?, ?, ?)`
+	res, err := u.DB.Exec(stmt, userID, role, store, created)
+	if err != nil {
+		if mysqlErr, ok := err.(*mysql.MySQLError); ok {
+			if mysqlErr.Number == 1062 && strings.Contains(mysqlErr.Message, "uk_role_user") {
+				return nil, models.ErrDuplicateUserRole
+			}
+		}
+		return nil, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.UserRole{
+		UserRoleID: id,
+		Role:       role,
+		StoreID:    storeID,
+		Created:    created,
+	}, nil
+}
+
+// GetStoreStaff gets the verified Users with a staff or manager Role at the Store
+func (u *UserModel) GetStoreStaff(storeID int64) ([]*models.User, error) {
+	stmt := `SELECT DISTINCT u.id, u.uuid, u.first_name, u.last_name, u.email, u.phone, u.notification_frequency, u.created
+			   FROM role_user AS ru
+			   JOIN user AS u ON u.id = ru.user_id
+			  WHERE ru.store_id = ?
+				AND ru.role IN (?, ?)
+				AND u.status_id = ?
+		   ORDER BY u.id`
+
+	rows, err := u.DB.Query(stmt, storeID, models.ROLE_STAFF, models.ROLE_MANAGER, models.USER_STATUS_VERIFIED)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []*models.User{}
+
+	for rows.Next() {
+		user := &models.User{}
+		err = rows.Scan(&user.ID, &user.UUID, &user.FirstName, &user.LastName, &user.Email, &user.Phone, &user.Frequency, &user.Created)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// RemoveRole revokes the UserRole identified by userRoleID
+func (u *UserModel) RemoveRole(userRoleID int64) error {
+	res, err := u.DB.Exec(`DELETE FROM role_user WHERE id = ?`, userRoleID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows < 1 {
+		return models.ErrNoneAffected
+	}
+
+	return nil
+}
+
 // AddIngredient creates a UserIngredient association. This is used for allowing Users to
-// save Ingredient preferences for notifications.
-func (u *UserModel) AddIngredient(userID int64, ingredient *models.Ingredient, keyword string) (*models.UserIngredient, error) {
-	stmt := `INSERT INTO ingredient_user (ingredient_id, user_id, keyword) VALUES (?, ?, ?)`
-	res, err := u.DB.Exec(stmt, ingredient.ID, userID, keyword)
+// save Ingredient preferences for notifications. `ingredient` is nil for a subscription to
+// `keyword` alone.
+func (u *UserModel) AddIngredient(userID int64, ingredient *models.Ingredient, keyword string, scope models.SubscriptionScope) (*models.UserIngredient, error) {
+	var ingredientID sql.NullInt64
+	if ingredient != nil {
+		ingredientID = sql.NullInt64{Int64: ingredient.ID, Valid: true}
+	}
+
+	var lat, lng, radius sql.NullFloat64
+	if scope.Area != nil {
+		lat = sql.NullFloat64{Float64: scope.Area.Lat, Valid: true}
+		lng = sql.NullFloat64{Float64: scope.Area.Lng, Valid: true}
+		radius = sql.NullFloat64{Float64: scope.Area.RadiusMiles, Valid: true}
+	}
+
+	tx, err := u.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	stmt := `INSERT INTO ingredient_user (ingredient_id, user_id, keyword, lat, lng, radius_miles) VALUES (?, ?, NULLIF(?, ''), ?, ?, ?)`
+	res, err := tx.Exec(stmt, ingredientID, userID, keyword, lat, lng, radius)
 	if err != nil {
 		if mysqlErr, ok := err.(*mysql.MySQLError); ok {
-			if mysqlErr.Number == 1062 && strings.Contains(mysqlErr.Message, "uk_ingredient_user_ingredient") {
+			if mysqlErr.Number == 1062 && (strings.Contains(mysqlErr.Message, "uk_ingredient_user_ingredient") || strings.Contains(mysqlErr.Message, "uk_ingredient_user_keyword")) {
 				return nil, models.ErrDuplicateUserIngredient
 			}
 		}
@@ -532,16 +875,30 @@ func (u *UserModel) AddIngredient(userID int64, ingredient *models.Ingredient, k
 		return nil, err
 	}
 
+	for _, storeID := range scope.StoreIDs {
+		_, err = tx.Exec(`INSERT INTO ingredient_user_store (ingredient_user_id, store_id) VALUES (?, ?)`, lastInsertId, storeID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	var userIngredient = &models.UserIngredient{
 		UserIngredientID: lastInsertId,
 		Ingredient:       ingredient,
+		Keyword:          keyword,
+		Scope:            scope,
 	}
 
 	stmt = `SELECT id, created 
 			   FROM ingredient_user 
 			  WHERE id = ?`
 
-	err = u.DB.QueryRow(stmt, lastInsertId).Scan(&userIngredient.UserIngredientID, &userIngredient.Created)
+	err = tx.QueryRow(stmt, lastInsertId).Scan(&userIngredient.UserIngredientID, &userIngredient.Created)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
 	if err != nil {
 		return nil, err
 	}
@@ -551,7 +908,7 @@ func (u *UserModel) AddIngredient(userID int64, ingredient *models.Ingredient, k
 
 // GetIngredients gets all of the UserIngredient associations for the User
 func (u *UserModel) GetIngredients(userID int64) ([]*models.UserIngredient, error) {
-	stmt := `SELECT iu.id, iu.created, i.id, i.name
+	stmt := `SELECT iu.id, IFNULL(iu.keyword, ''), ` + scopeColumns + `, iu.created, IFNULL(i.id, 0), IFNULL(i.name, '')
 			   FROM ingredient_user iu
 	      LEFT JOIN ingredient i ON iu.ingredient_id = i.id
               WHERE user_id = ? AND deleted = 0`
@@ -570,20 +927,95 @@ func (u *UserModel) GetIngredients(userID int64) ([]*models.UserIngredient, erro
 	for rows.Next() {
 		i := &models.Ingredient{}
 		ui := &models.UserIngredient{}
+		var sc scopeColumnValues
 
-		err = rows.Scan(&ui.UserIngredientID, &ui.Created, &i.ID, &i.Name)
+		err = rows.Scan(&ui.UserIngredientID, &ui.Keyword, &sc.storeIDs, &sc.lat, &sc.lng, &sc.radius, &ui.Created, &i.ID, &i.Name)
+
+		if err != nil {
+			return nil, err
+		}
 
+		ui.Scope, err = sc.scope()
 		if err != nil {
 			return nil, err
 		}
 
-		ui.Ingredient = i
+		if i.ID > 0 {
+			ui.Ingredient = i
+		}
 		userIngredients = append(userIngredients, ui)
 	}
 
 	return userIngredients, nil
 }
 
+// GetSubscribers gets the verified Users who have saved any of the Ingredients identified by
+// ingredientIDs, or who have saved a keyword. Keyword subscriptions may have no Ingredient.
+// Each User's Ingredients holds the UserIngredient associations that caused them to be
+// returned, so that keywords can be matched by the caller.
+func (u *UserModel) GetSubscribers(ingredientIDs []int64) ([]*models.User, error) {
+	args := []interface{}{models.USER_STATUS_VERIFIED}
+
+	where := `iu.keyword <> ''`
+	if len(ingredientIDs) > 0 {
+		where = `iu.ingredient_id IN (?` + strings.Repeat(", ?", len(ingredientIDs)-1) + `) OR ` + where
+		for _, id := range ingredientIDs {
+			args = append(args, id)
+		}
+	}
+
+	stmt := fmt.Sprintf(`SELECT u.id, u.uuid, u.first_name, u.last_name, u.email, u.phone, u.notification_frequency, u.created, iu.id, IFNULL(iu.keyword, ''), %s, iu.created, IFNULL(i.id, 0), IFNULL(i.name, '')
+			   FROM ingredient_user AS iu
+			   JOIN user AS u ON iu.user_id = u.id
+		  LEFT JOIN ingredient AS i ON iu.ingredient_id = i.id
+			  WHERE iu.deleted = 0
+			    AND u.status_id = ?
+			    AND (%s)
+		   ORDER BY u.id, iu.id`, scopeColumns, where)
+
+	rows, err := u.DB.Query(stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []*models.User{}
+	var user *models.User
+
+	for rows.Next() {
+		next := &models.User{}
+		i := &models.Ingredient{}
+		ui := models.UserIngredient{}
+		var sc scopeColumnValues
+
+		err = rows.Scan(&next.ID, &next.UUID, &next.FirstName, &next.LastName, &next.Email, &next.Phone, &next.Frequency, &next.Created, &ui.UserIngredientID, &ui.Keyword, &sc.storeIDs, &sc.lat, &sc.lng, &sc.radius, &ui.Created, &i.ID, &i.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		ui.Scope, err = sc.scope()
+		if err != nil {
+			return nil, err
+		}
+		if i.ID > 0 {
+			ui.Ingredient = i
+		}
+
+		if user == nil || user.ID != next.ID {
+			user = next
+			user.Status = models.USER_STATUS_VERIFIED.Slug()
+			users = append(users, user)
+		}
+		user.Ingredients = append(user.Ingredients, ui)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
 // RemoveIngredient removes the UserIngredient association
 func (u *UserModel) RemoveUserIngredient(userIngredientID int64) error {
 	stmt := `UPDATE ingredient_user 
@@ -608,6 +1040,141 @@ func (u *UserModel) RemoveUserIngredient(userIngredientID int64) error {
 	return nil
 }
 
+// AddFlavor subscribes the User to the Flavor
+func (u *UserModel) AddFlavor(userID int64, flavor *models.Flavor) (*models.UserFlavor, error) {
+	stmt := `INSERT INTO flavor_user (flavor_id, user_id) VALUES (?, ?)`
+	res, err := u.DB.Exec(stmt, flavor.ID, userID)
+	if err != nil {
+		if mysqlErr, ok := err.(*mysql.MySQLError); ok {
+			if mysqlErr.Number == 1062 && strings.Contains(mysqlErr.Message, "uk_flavor_user_flavor") {
+				return nil, models.ErrDuplicateUserFlavor
+			}
+		}
+		return nil, err
+	}
+
+	lastInsertId, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	userFlavor := &models.UserFlavor{
+		UserFlavorID: lastInsertId,
+		Flavor:       flavor,
+	}
+
+	stmt = `SELECT created
+			  FROM flavor_user
+			 WHERE id = ?`
+
+	err = u.DB.QueryRow(stmt, lastInsertId).Scan(&userFlavor.Created)
+	if err != nil {
+		return nil, err
+	}
+
+	return userFlavor, nil
+}
+
+// GetFlavors gets the Flavors the User is subscribed to
+func (u *UserModel) GetFlavors(userID int64) ([]*models.UserFlavor, error) {
+	stmt := `SELECT fu.id, fu.created, f.id, f.name, f.description, f.created
+			   FROM flavor_user AS fu
+			   JOIN flavor AS f ON fu.flavor_id = f.id
+			  WHERE fu.user_id = ?
+			    AND fu.deleted = 0
+		   ORDER BY fu.id`
+
+	rows, err := u.DB.Query(stmt, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	userFlavors := []*models.UserFlavor{}
+
+	for rows.Next() {
+		f := &models.Flavor{}
+		uf := &models.UserFlavor{Flavor: f}
+
+		err = rows.Scan(&uf.UserFlavorID, &uf.Created, &f.ID, &f.Name, &f.Description, &f.Created)
+		if err != nil {
+			return nil, err
+		}
+
+		userFlavors = append(userFlavors, uf)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return userFlavors, nil
+}
+
+// RemoveUserFlavor removes the UserFlavor subscription
+func (u *UserModel) RemoveUserFlavor(userFlavorID int64) error {
+	stmt := `UPDATE flavor_user
+				SET deleted = ?
+			  WHERE id = ?
+			    AND deleted = 0`
+
+	res, err := u.DB.Exec(stmt, int32(time.Now().Unix()), userFlavorID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows < 1 {
+		return models.ErrNoneAffected
+	}
+
+	return nil
+}
+
+// GetFlavorSubscribers gets the verified Users who are subscribed to the Flavor identified by
+// flavorID. Each User's Flavors holds the UserFlavor that caused them to be returned.
+func (u *UserModel) GetFlavorSubscribers(flavorID int64) ([]*models.User, error) {
+	stmt := `SELECT u.id, u.uuid, u.first_name, u.last_name, u.email, u.phone, u.notification_frequency, u.created, fu.id, fu.created
+			   FROM flavor_user AS fu
+			   JOIN user AS u ON fu.user_id = u.id
+			  WHERE fu.deleted = 0
+			    AND fu.flavor_id = ?
+			    AND u.status_id = ?
+		   ORDER BY u.id`
+
+	rows, err := u.DB.Query(stmt, flavorID, models.USER_STATUS_VERIFIED)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []*models.User{}
+
+	for rows.Next() {
+		user := &models.User{}
+		uf := models.UserFlavor{Flavor: &models.Flavor{ID: flavorID}}
+
+		err = rows.Scan(&user.ID, &user.UUID, &user.FirstName, &user.LastName, &user.Email, &user.Phone, &user.Frequency, &user.Created, &uf.UserFlavorID, &uf.Created)
+		if err != nil {
+			return nil, err
+		}
+
+		user.Status = models.USER_STATUS_VERIFIED.Slug()
+		user.Flavors = append(user.Flavors, uf)
+		users = append(users, user)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
 func (u *UserModel) CheckValidPermission(p models.Permission) bool {
 	var isValid bool
 	stmt := `SELECT IF(COUNT(*), 'true', 'false') 
@@ -637,6 +1204,44 @@ func (u *UserModel) CheckValidUser(userID int) bool {
 	return isValid
 }
 
+// scopeColumns selects the SubscriptionScope of the ingredient_user aliased `iu`, to be
+// scanned into scopeColumnValues.
+const scopeColumns = `IFNULL((SELECT GROUP_CONCAT(ius.store_id ORDER BY ius.store_id)
+				 FROM ingredient_user_store AS ius
+				WHERE ius.ingredient_user_id = iu.id), ''), iu.lat, iu.lng, iu.radius_miles`
+
+type scopeColumnValues struct {
+	storeIDs string
+	lat      sql.NullFloat64
+	lng      sql.NullFloat64
+	radius   sql.NullFloat64
+}
+
+// scope builds the SubscriptionScope from the values selected by scopeColumns
+func (v scopeColumnValues) scope() (models.SubscriptionScope, error) {
+	scope := models.SubscriptionScope{}
+
+	if v.storeIDs != "" {
+		for _, id := range strings.Split(v.storeIDs, ",") {
+			storeID, err := strconv.ParseInt(id, 10, 64)
+			if err != nil {
+				return scope, err
+			}
+			scope.StoreIDs = append(scope.StoreIDs, storeID)
+		}
+	}
+
+	if v.lat.Valid && v.lng.Valid && v.radius.Valid {
+		scope.Area = &models.Area{
+			Lat:         v.lat.Float64,
+			Lng:         v.lng.Float64,
+			RadiusMiles: v.radius.Float64,
+		}
+	}
+
+	return scope, nil
+}
+
 // NormalizePhone
 func NormalizePhone(p string) string {
 	// retain only the digits
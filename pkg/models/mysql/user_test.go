@@ -1,9 +1,15 @@
 package mysql_test
 
 import (
+	"context"
 	"encoding/base64"
+	"fmt"
 	"testing"
+	"time"
 
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-redis/redis/v8"
+	"github.com/go-sql-driver/mysql"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 
@@ -85,10 +91,68 @@ func TestUserModel(t *testing.T) {
 		require.NoError(t, err)
 	})
 
-	t.Run("get by auth token", func(t *testing.T) {
-		u, err := r.GetByAuthToken(token)
+	t.Run("consume auth token", func(t *testing.T) {
+		u, err := r.ConsumeAuthToken(token)
 		require.NoError(t, err)
 		require.Equal(t, user, u)
+
+		_, err = r.ConsumeAuthToken(token)
+		require.Equal(t, repo.ErrNoAuthTokenFound, err)
+	})
+
+	t.Run("consume auth code", func(t *testing.T) {
+		require.NoError(t, r.SaveAuthCode(user.ID, "123456", time.Minute))
+
+		ok, err := r.ConsumeAuthCode(user.ID, "654321", 3)
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		ok, err = r.ConsumeAuthCode(user.ID, "123456", 3)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		ok, err = r.ConsumeAuthCode(user.ID, "123456", 3)
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("count auth requests", func(t *testing.T) {
+		key := fmt.Sprintf("%s:%s", repo.AUTH_REQUEST_KEY_PREFIX, repo.NormalizePhone(user.Phone))
+		require.NoError(t, rdb.Del(context.Background(), key).Err())
+
+		for want := int64(1); want <= 2; want++ {
+			n, err := r.CountAuthRequest(user.Phone, time.Minute)
+			require.NoError(t, err)
+			require.Equal(t, want, n)
+		}
+
+		ttl, err := rdb.TTL(context.Background(), key).Result()
+		require.NoError(t, err)
+		require.True(t, ttl > 0 && ttl <= time.Minute)
+	})
+
+	t.Run("auth code deleted after too many wrong guesses", func(t *testing.T) {
+		require.NoError(t, r.SaveAuthCode(user.ID, "123456", time.Minute))
+
+		for i := 0; i < 3; i++ {
+			ok, err := r.ConsumeAuthCode(user.ID, "000000", 3)
+			require.NoError(t, err)
+			require.False(t, ok)
+		}
+
+		ok, err := r.ConsumeAuthCode(user.ID, "123456", 3)
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("wrong guesses at an expired auth code don't recreate it", func(t *testing.T) {
+		ok, err := r.ConsumeAuthCode(user.ID, "000000", 3)
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		n, err := rdb.Exists(context.Background(), fmt.Sprintf("%s:%d", repo.AUTH_CODE_KEY_PREFIX, user.ID)).Result()
+		require.NoError(t, err)
+		require.Equal(t, int64(0), n)
 	})
 
 	t.Run("get user list", func(t *testing.T) {
@@ -115,3 +179,166 @@ func TestUserModel(t *testing.T) {
 		require.True(t, ok)
 	})
 }
+
+func TestUserModel_GetSubscribers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening stub DB connection", err)
+	}
+	defer db.Close()
+
+	alice := uuid.New()
+	bob := uuid.New()
+	created := time.Now()
+	cols := []string{"id", "uuid", "first_name", "last_name", "email", "phone", "notification_frequency", "created", "id", "keyword", "store_ids", "lat", "lng", "radius_miles", "created", "id", "name"}
+	rows := sqlmock.NewRows(cols).
+		AddRow(1, alice.String(), "Alice", "Wonder", nil, "4045551212", "immediate", created, 10, "", "", nil, nil, nil, created, 1, "coconut").
+		AddRow(1, alice.String(), "Alice", "Wonder", nil, "4045551212", "immediate", created, 11, "", "1,2", nil, nil, nil, created, 2, "jalapeno").
+		AddRow(2, bob.String(), nil, nil, nil, "4045551313", "daily", created, 12, "pecan", "", 33.733951, -84.349625, 5.0, created, 4, "pecan")
+
+	mock.ExpectQuery(`SELECT (.+) FROM ingredient_user AS iu (.+) AND \(iu.ingredient_id IN \(\?, \?\) OR iu.keyword <> ''\)`).
+		WithArgs(models.USER_STATUS_VERIFIED, 1, 2).
+		WillReturnRows(rows)
+
+	m := repo.UserModel{DB: db}
+	users, err := m.GetSubscribers([]int64{1, 2})
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+
+	require.Equal(t, alice, users[0].UUID)
+	require.Len(t, users[0].Ingredients, 2)
+	require.Equal(t, "jalapeno", users[0].Ingredients[1].Name)
+	require.Empty(t, users[0].Ingredients[0].Scope.StoreIDs)
+	require.Equal(t, []int64{1, 2}, users[0].Ingredients[1].Scope.StoreIDs)
+
+	require.Equal(t, bob, users[1].UUID)
+	require.Len(t, users[1].Ingredients, 1)
+	require.Equal(t, "pecan", users[1].Ingredients[0].Keyword)
+	require.Equal(t, models.FREQUENCY_DAILY, users[1].Frequency)
+	require.Equal(t, &models.Area{Lat: 33.733951, Lng: -84.349625, RadiusMiles: 5}, users[1].Ingredients[0].Scope.Area)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserModel_AddIngredientScoped(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening stub DB connection", err)
+	}
+	defer db.Close()
+
+	scope := models.SubscriptionScope{StoreIDs: []int64{1, 2}}
+	ingredient := &models.Ingredient{ID: 3, Name: "coconut"}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO ingredient_user`).
+		WithArgs(ingredient.ID, 7, "", nil, nil, nil).
+		WillReturnResult(sqlmock.NewResult(10, 1))
+	mock.ExpectExec(`INSERT INTO ingredient_user_store`).
+		WithArgs(10, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO ingredient_user_store`).
+		WithArgs(10, 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT id, created FROM ingredient_user`).
+		WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created"}).AddRow(10, time.Now()))
+	mock.ExpectCommit()
+
+	m := repo.UserModel{DB: db}
+	ui, err := m.AddIngredient(7, ingredient, "", scope)
+	require.NoError(t, err)
+	require.Equal(t, int64(10), ui.UserIngredientID)
+	require.Equal(t, scope, ui.Scope)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserModel_GetFlavorSubscribers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening stub DB connection", err)
+	}
+	defer db.Close()
+
+	alice := uuid.New()
+	created := time.Now()
+	cols := []string{"id", "uuid", "first_name", "last_name", "email", "phone", "notification_frequency", "created", "id", "created"}
+	rows := sqlmock.NewRows(cols).
+		AddRow(1, alice.String(), "Alice", "Wonder", nil, "4045551212", "immediate", created, 20, created)
+
+	mock.ExpectQuery(`SELECT (.+) FROM flavor_user AS fu (.+) AND fu.flavor_id = \?`).
+		WithArgs(5, models.USER_STATUS_VERIFIED).
+		WillReturnRows(rows)
+
+	m := repo.UserModel{DB: db}
+	users, err := m.GetFlavorSubscribers(5)
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+
+	require.Equal(t, alice, users[0].UUID)
+	require.Len(t, users[0].Flavors, 1)
+	require.Equal(t, int64(20), users[0].Flavors[0].UserFlavorID)
+	require.Equal(t, int64(5), users[0].Flavors[0].Flavor.ID)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserModel_GetRoles(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening stub DB connection", err)
+	}
+	defer db.Close()
+
+	created := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "role", "store_id", "created"}).
+		AddRow(1, models.ROLE_STAFF, 3, created).
+		AddRow(2, models.ROLE_ADMIN, 0, created)
+
+	mock.ExpectQuery(`SELECT (.+) FROM role_user WHERE user_id = \?`).
+		WithArgs(7).
+		WillReturnRows(rows)
+
+	m := repo.UserModel{DB: db}
+	roles, err := m.GetRoles(7)
+	require.NoError(t, err)
+	require.Len(t, roles, 2)
+
+	require.Equal(t, models.ROLE_STAFF, roles[0].Role)
+	require.Equal(t, int64(3), roles[0].StoreID)
+	require.Equal(t, models.ROLE_ADMIN, roles[1].Role)
+	require.Equal(t, int64(0), roles[1].StoreID)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserModel_RevokeExpiredToken(t *testing.T) {
+	// Revoking an expired token doesn't reach Redis, so no record is kept without an expiry
+	rdb := redis.NewClient(&redis.Options{Addr: "127.0.0.1:0"})
+	defer rdb.Close()
+
+	m := repo.UserModel{Redis: rdb}
+	require.NoError(t, m.RevokeToken(uuid.New().String(), 0))
+	require.NoError(t, m.RevokeToken(uuid.New().String(), -time.Minute))
+	require.Error(t, m.RevokeToken(uuid.New().String(), time.Minute))
+}
+
+func TestUserModel_AddRoleDuplicate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening stub DB connection", err)
+	}
+	defer db.Close()
+
+	// A role granted at every Store has no store_id, and is unique by its store_scope of 0
+	mock.ExpectExec(`INSERT INTO role_user \(user_id, role, store_id, created\)`).
+		WithArgs(7, models.ROLE_ADMIN, nil, sqlmock.AnyArg()).
+		WillReturnError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry '7-admin-0' for key 'uk_role_user_user_id_role_store_scope'"})
+
+	m := repo.UserModel{DB: db}
+	_, err = m.AddRole(7, models.ROLE_ADMIN, 0)
+	require.Equal(t, models.ErrDuplicateUserRole, err)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
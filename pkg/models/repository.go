@@ -1,6 +1,10 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 //go:generate counterfeiter . UserRepository
 type UserRepository interface {
@@ -10,8 +14,21 @@ type UserRepository interface {
 	GetByUUID(uuid.UUID) (*User, error)
 	GetByCredentials(Credentials) (*User, error)
 	GetByPhone(phone string) (*User, error)
-	GetByAuthToken(string) (*User, error)
+	ConsumeAuthToken(string) (*User, error)
 	SaveAuthToken(string, int) error
+	CountAuthRequest(phone string, window time.Duration) (int64, error)
+	SaveAuthCode(userID int64, code string, ttl time.Duration) error
+	ConsumeAuthCode(userID int64, code string, maxAttempts int) (bool, error)
+	GetByEmail(email string) (*User, error)
+	UpdatePassword(userID int64, password string) error
+	SavePasswordResetToken(token string, userID int64, ttl time.Duration) error
+	ConsumePasswordResetToken(token string) (*User, error)
+	SaveVerificationToken(token string, userID int64, ttl time.Duration) error
+	ConsumeVerificationToken(token string) (*User, error)
+	SaveRefreshToken(token string, userID int64, ttl time.Duration) error
+	ConsumeRefreshToken(token string) (*User, error)
+	RevokeToken(jti string, ttl time.Duration) error
+	IsTokenRevoked(jti string) (bool, error)
 	List(limit int, offset int, order string) ([]*User, error)
 	Delete(int) (bool, error)
 	Count() int
@@ -19,9 +36,18 @@ type UserRepository interface {
 	AddPermission(userID int, p Permission) (int, error)
 	RemovePermission(userPermissionID int) (bool, error)
 	RemoveAllPermissions(userID int) error
-	AddIngredient(userID int64, ingredient *Ingredient, keyword string) (*UserIngredient, error)
+	GetRoles(userID int64) ([]UserRole, error)
+	AddRole(userID int64, role string, storeID int64) (*UserRole, error)
+	RemoveRole(userRoleID int64) error
+	GetStoreStaff(storeID int64) ([]*User, error)
+	AddIngredient(userID int64, ingredient *Ingredient, keyword string, scope SubscriptionScope) (*UserIngredient, error)
 	GetIngredients(userID int64) ([]*UserIngredient, error)
 	RemoveUserIngredient(userIngredientID int64) error
+	GetSubscribers(ingredientIDs []int64) ([]*User, error)
+	AddFlavor(userID int64, flavor *Flavor) (*UserFlavor, error)
+	GetFlavors(userID int64) ([]*UserFlavor, error)
+	RemoveUserFlavor(userFlavorID int64) error
+	GetFlavorSubscribers(flavorID int64) ([]*User, error)
 }
 
 //go:generate counterfeiter . StoreRepository
@@ -34,6 +60,11 @@ type StoreRepository interface {
 	ActivateFlavor(storeID int64, flavorID int64, position int) error
 	DeactivateFlavor(storeID int64, flavorID int64) (bool, error)
 	DeactivateFlavorAtPosition(storeID int64, position int) (bool, error)
+	ListRotations(from time.Time, to time.Time, storeID int64, flavorID int64) ([]*Rotation, error)
+	ListLineups(storeID int64) ([]*Lineup, error)
+	ReplaceLineup(storeID int64, lineup map[int]int64) (*LineupDiff, error)
+	GetLayout(storeID int64) (*Layout, error)
+	SaveLayout(*Layout) error
 }
 
 //go:generate counterfeiter . FlavorRepository
@@ -53,3 +84,53 @@ type IngredientRepository interface {
 	Insert(*Ingredient) (*Ingredient, error)
 	Search(limit int, offset int, order string, search []string) ([]*Ingredient, error)
 }
+
+//go:generate counterfeiter . MessageRepository
+type MessageRepository interface {
+	Insert(phone string, body string) (*Message, error)
+	Get(ID int64) (*Message, error)
+	GetBySID(sid string) (*Message, error)
+	ListByUser(userID int64, limit int, offset int) ([]*Message, error)
+	CountByUser(userID int64) int
+	AddEvent(messageID int64, status string, errorCode string) (*MessageEvent, error)
+	Claim(limit int, lease time.Duration) ([]*Message, error)
+	MarkSent(ID int64, sid string) error
+	MarkFailed(ID int64, reason string, nextAttempt time.Time) error
+	MarkDead(ID int64, reason string) error
+}
+
+//go:generate counterfeiter . SuppressionRepository
+type SuppressionRepository interface {
+	IsSuppressed(phone string) (bool, error)
+	Suppress(phone string, keyword string) (*Suppression, error)
+	Unsuppress(phone string) error
+	List(limit int, offset int) ([]*Suppression, error)
+	Count() int
+}
+
+//go:generate counterfeiter . NotificationRepository
+type NotificationRepository interface {
+	Insert(userID int64, storeID int64, flavorID int64, body string) (*Notification, error)
+	Claim(userID int64, claim string) ([]*Notification, error)
+	Release(claim string) error
+	PendingUsers(frequency Frequency) ([]int64, error)
+	MarkSent(IDs []int64, messageRef string) error
+	CountSent(userID int64, since time.Time) (int, error)
+	LastSent(userID int64) (time.Time, error)
+	GetPolicy(userID int64) (*NotificationPolicy, error)
+	SavePolicy(policy *NotificationPolicy) (*NotificationPolicy, error)
+}
+
+//go:generate counterfeiter . LoginAttemptRepository
+type LoginAttemptRepository interface {
+	Insert(attempt *LoginAttempt) (*LoginAttempt, error)
+}
+
+//go:generate counterfeiter . InventoryRepository
+type InventoryRepository interface {
+	GetBarrel(storeID int64, position int) (*Barrel, error)
+	ListBarrels(storeID int64) ([]*Barrel, error)
+	RecordLevel(barrelID int64, level int, userID int64) (*BarrelLevel, error)
+	GetLowLevel(storeID int64) (int, error)
+	SetLowLevel(storeID int64, level int) error
+}
@@ -0,0 +1,95 @@
+package notify
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/jcorry/morellis/pkg/models"
+)
+
+// MatchesKeyword reports whether the keyword appears in the Flavor's name, description or
+// Ingredient names. Words are compared case-insensitively once their plurals are folded by
+// Fold, so "pecans" matches "pecan" and "cherries" matches "cherry". A keyword of several
+// words matches only where they appear together, in order.
+func MatchesKeyword(keyword string, flavor *models.Flavor) bool {
+	want := Words(keyword)
+	if len(want) == 0 {
+		return false
+	}
+
+	fields := []string{flavor.Name, flavor.Description}
+	for _, i := range flavor.Ingredients {
+		fields = append(fields, i.Name)
+	}
+
+	for _, f := range fields {
+		if containsWords(Words(f), want) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Words splits `s` into words at anything other than a letter or digit, and folds each.
+func Words(s string) []string {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	words := make([]string, 0, len(fields))
+	for _, f := range fields {
+		words = append(words, Fold(f))
+	}
+
+	return words
+}
+
+// invariant are words which end like plurals but are the same in the singular
+var invariant = map[string]bool{
+	"molasses": true,
+}
+
+// Fold lowercases the word and folds English plural endings, so that the singular and plural
+// of a word fold to the same stem: "cherries" and "cherry" to "cherrie", "pies" and "pie" to
+// "pie", "peaches" to "peach" and "pecans" to "pecan". The stems aren't always words.
+func Fold(word string) string {
+	w := strings.ToLower(word)
+	if invariant[w] {
+		return w
+	}
+
+	switch {
+	case len(w) > 3 && strings.HasSuffix(w, "ies"):
+		return strings.TrimSuffix(w, "s")
+	case len(w) > 2 && strings.HasSuffix(w, "y") && !strings.ContainsRune("aeiou", rune(w[len(w)-2])):
+		return strings.TrimSuffix(w, "y") + "ie"
+	case len(w) > 4 && (strings.HasSuffix(w, "oes") || strings.HasSuffix(w, "ches") ||
+		strings.HasSuffix(w, "shes") || strings.HasSuffix(w, "sses") ||
+		strings.HasSuffix(w, "xes") || strings.HasSuffix(w, "zes")):
+		return strings.TrimSuffix(w, "es")
+	case len(w) > 3 && strings.HasSuffix(w, "s") &&
+		!strings.HasSuffix(w, "ss") && !strings.HasSuffix(w, "us") && !strings.HasSuffix(w, "is"):
+		return strings.TrimSuffix(w, "s")
+	}
+
+	return w
+}
+
+// containsWords reports whether `want` appears as a run within `words`
+func containsWords(words []string, want []string) bool {
+	for i := 0; i+len(want) <= len(words); i++ {
+		match := true
+		for j := range want {
+			if words[i+j] != want[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,68 @@
+package notify_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jcorry/morellis/pkg/models"
+	"github.com/jcorry/morellis/pkg/notify"
+)
+
+func TestFold(t *testing.T) {
+	tests := []struct {
+		singular string
+		plural   string
+		stem     string
+	}{
+		{"pecan", "pecans", "pecan"},
+		{"cherry", "cherries", "cherrie"},
+		{"cookie", "cookies", "cookie"},
+		{"pie", "pies", "pie"},
+		{"peach", "peaches", "peach"},
+		{"tomato", "tomatoes", "tomato"},
+		{"glass", "glasses", "glass"},
+		{"key", "keys", "key"},
+		{"Hibiscus", "hibiscus", "hibiscus"},
+		{"molasses", "molasses", "molasses"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.plural, func(t *testing.T) {
+			require.Equal(t, tt.stem, notify.Fold(tt.singular))
+			require.Equal(t, tt.stem, notify.Fold(tt.plural))
+		})
+	}
+}
+
+func TestMatchesKeyword(t *testing.T) {
+	flavor := &models.Flavor{
+		Name:        "Cherry Pecan Crunch",
+		Description: "Sweet cream studded with brandied cherries and cookie pieces.",
+		Ingredients: []models.Ingredient{{Name: "pecans"}, {Name: "graham crackers"}},
+	}
+
+	tests := []struct {
+		keyword string
+		want    bool
+	}{
+		{"pecan", true},
+		{"PECANS", true},
+		{"cherries", true},
+		{"cookies", true},
+		{"graham cracker", true},
+		{"cream studded", true},
+		{"studded cream", false},
+		{"pec", false},
+		{"chocolate", false},
+		{"  ", false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.keyword, func(t *testing.T) {
+			require.Equal(t, tt.want, notify.MatchesKeyword(tt.keyword, flavor))
+		})
+	}
+}
@@ -0,0 +1,389 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/jcorry/morellis/pkg/models"
+	"github.com/jcorry/morellis/pkg/sms"
+)
+
+// Dispatcher notifies Users by SMS when a Flavor matching their saved Ingredients or
+// keywords is activated at a Store. Notifications are held until each User's
+// NotificationPolicy allows them to be sent, and any held back are sent together as a digest.
+// Users who chose a daily or weekly Frequency are sent their Notifications by SendDigests.
+type Dispatcher struct {
+	users         models.UserRepository
+	notifications models.NotificationRepository
+	sender        sms.Messager
+	errorLog      *log.Logger
+}
+
+// NewDispatcher configures and returns a new Dispatcher. Failed sends are written to
+// errorLog, which may be nil.
+func NewDispatcher(users models.UserRepository, notifications models.NotificationRepository, sender sms.Messager, errorLog *log.Logger) *Dispatcher {
+	if errorLog == nil {
+		errorLog = log.New(ioutil.Discard, "", 0)
+	}
+
+	return &Dispatcher{
+		users:         users,
+		notifications: notifications,
+		sender:        sender,
+		errorLog:      errorLog,
+	}
+}
+
+// FlavorActivated notifies each User subscribed to the Flavor, or with a saved Ingredient or
+// keyword matching it, naming the Flavor and the Store at which it was activated. It returns
+// the number of messages sent; Users whose NotificationPolicy doesn't allow a message now are
+// sent a digest later, by Flush, and Users with a daily or weekly Frequency by SendDigests. A
+// failure to notify one User does not prevent notifying the rest; an error is returned after
+// all Users have been tried.
+func (d *Dispatcher) FlavorActivated(ctx context.Context, store *models.Store, flavor *models.Flavor) (int, error) {
+	ingredientIDs := make([]int64, 0, len(flavor.Ingredients))
+	for _, i := range flavor.Ingredients {
+		ingredientIDs = append(ingredientIDs, i.ID)
+	}
+
+	users, err := d.users.GetSubscribers(ingredientIDs)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get subscribers")
+	}
+
+	flavorUsers, err := d.users.GetFlavorSubscribers(flavor.ID)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get flavor subscribers")
+	}
+
+	users = mergeSubscribers(users, flavorUsers)
+
+	message := Message(store, flavor)
+
+	var sent, failed int
+	for _, u := range users {
+		if !Matches(u, store, flavor) {
+			continue
+		}
+
+		_, err = d.notifications.Insert(u.ID, store.ID, flavor.ID, message)
+		if err != nil {
+			d.errorLog.Output(2, fmt.Sprintf("failed to save notification for user %s: %s", u.UUID, err))
+			failed++
+			continue
+		}
+
+		if u.Frequency != "" && u.Frequency != models.FREQUENCY_IMMEDIATE {
+			continue
+		}
+
+		ok, err := d.flush(ctx, u.ID, u.Phone)
+		if err != nil {
+			d.errorLog.Output(2, fmt.Sprintf("failed to notify user %s: %s", u.UUID, err))
+			failed++
+			continue
+		}
+		if ok {
+			sent++
+		}
+	}
+
+	if failed > 0 {
+		return sent, errors.Errorf("failed to notify %d of %d users", failed, sent+failed)
+	}
+
+	return sent, nil
+}
+
+// Flush sends each User notified immediately who has pending Notifications a message, if their
+// NotificationPolicy now allows it, and returns the number of messages sent.
+func (d *Dispatcher) Flush(ctx context.Context) (int, error) {
+	IDs, err := d.notifications.PendingUsers(models.FREQUENCY_IMMEDIATE)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get users with pending notifications")
+	}
+
+	var sent, failed int
+	for _, id := range IDs {
+		ok, err := d.flush(ctx, id, "")
+		if err != nil {
+			d.errorLog.Output(2, fmt.Sprintf("failed to notify user %d: %s", id, err))
+			failed++
+			continue
+		}
+		if ok {
+			sent++
+		}
+	}
+
+	if failed > 0 {
+		return sent, errors.Errorf("failed to notify %d of %d users", failed, len(IDs))
+	}
+
+	return sent, nil
+}
+
+// Run flushes pending Notifications every `interval` until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		_, err := d.Flush(ctx)
+		if err != nil {
+			d.errorLog.Output(2, err.Error())
+		}
+	}
+}
+
+// flush claims the User's pending Notifications and sends them in one message if their
+// NotificationPolicy allows it, reporting whether a message was sent. Notifications which
+// aren't sent are released to be sent later. Quiet hours are local to the Store of the most
+// recent Notification. `phone` overrides the phone number saved with the Notifications.
+func (d *Dispatcher) flush(ctx context.Context, userID int64, phone string) (bool, error) {
+	claim := uuid.New().String()
+	pending, err := d.notifications.Claim(userID, claim)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to claim pending notifications")
+	}
+
+	if len(pending) == 0 {
+		return false, nil
+	}
+	defer d.release(claim)
+
+	policy, err := d.notifications.GetPolicy(userID)
+	if err == models.ErrNoRecord {
+		policy = models.DefaultNotificationPolicy(userID)
+	} else if err != nil {
+		return false, errors.Wrap(err, "failed to get notification policy")
+	}
+
+	now := time.Now()
+	loc := location(pending[len(pending)-1].Timezone)
+
+	sentToday, err := d.notifications.CountSent(userID, Midnight(now, loc))
+	if err != nil {
+		return false, errors.Wrap(err, "failed to count sent notifications")
+	}
+
+	lastSent, err := d.notifications.LastSent(userID)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get last sent notification")
+	}
+
+	if !Allows(policy, now, loc, sentToday, lastSent) {
+		return false, nil
+	}
+
+	if phone == "" {
+		phone = pending[0].Phone
+	}
+
+	return true, d.send(ctx, phone, pending, Digest(pending))
+}
+
+// SendDigests sends each User notified at `frequency` whose digest is due one message
+// summarizing the Notifications pending since their last digest, and returns the number of
+// messages sent.
+func (d *Dispatcher) SendDigests(ctx context.Context, frequency models.Frequency) (int, error) {
+	IDs, err := d.notifications.PendingUsers(frequency)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to get users with pending %s digests", frequency)
+	}
+
+	now := time.Now()
+
+	var sent, failed int
+	for _, id := range IDs {
+		ok, err := d.digest(ctx, id, frequency, now)
+		if err != nil {
+			d.errorLog.Output(2, fmt.Sprintf("failed to send %s digest to user %d: %s", frequency, id, err))
+			failed++
+			continue
+		}
+		if ok {
+			sent++
+		}
+	}
+
+	if failed > 0 {
+		return sent, errors.Errorf("failed to send %s digests to %d of %d users", frequency, failed, len(IDs))
+	}
+
+	return sent, nil
+}
+
+// digest claims the User's pending Notifications and sends them as a Summary if their digest
+// is due and it isn't their quiet hours, reporting whether a message was sent. Notifications
+// which aren't sent are released to be sent later.
+func (d *Dispatcher) digest(ctx context.Context, userID int64, frequency models.Frequency, now time.Time) (bool, error) {
+	claim := uuid.New().String()
+	pending, err := d.notifications.Claim(userID, claim)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to claim pending notifications")
+	}
+
+	if len(pending) == 0 {
+		return false, nil
+	}
+	defer d.release(claim)
+
+	policy, err := d.notifications.GetPolicy(userID)
+	if err == models.ErrNoRecord {
+		policy = models.DefaultNotificationPolicy(userID)
+	} else if err != nil {
+		return false, errors.Wrap(err, "failed to get notification policy")
+	}
+
+	lastSent, err := d.notifications.LastSent(userID)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get last sent notification")
+	}
+
+	loc := location(pending[len(pending)-1].Timezone)
+	if !DigestDue(frequency, now, loc, lastSent, pending[0].Created) || InQuietHours(policy, now.In(loc)) {
+		return false, nil
+	}
+
+	return true, d.send(ctx, pending[0].Phone, pending, Summary(frequency, pending))
+}
+
+// send sends `body` to `phone` and marks the Notifications it was composed from sent.
+func (d *Dispatcher) send(ctx context.Context, phone string, pending []*models.Notification, body string) error {
+	ref, err := d.sender.Send(ctx, phone, body)
+	if err != nil {
+		return err
+	}
+
+	IDs := make([]int64, 0, len(pending))
+	for _, n := range pending {
+		IDs = append(IDs, n.ID)
+	}
+
+	err = d.notifications.MarkSent(IDs, ref)
+	if err != nil {
+		return errors.Wrap(err, "failed to mark notifications sent")
+	}
+
+	return nil
+}
+
+// release releases the claimed Notifications which weren't sent. Those which were sent stay sent.
+func (d *Dispatcher) release(claim string) {
+	err := d.notifications.Release(claim)
+	if err != nil {
+		d.errorLog.Output(2, fmt.Sprintf("failed to release notifications claimed as %s: %s", claim, err))
+	}
+}
+
+// mergeSubscribers adds the Flavor subscriptions of the Users in flavorUsers to the matching
+// User in users, appending any Users who aren't already there.
+func mergeSubscribers(users []*models.User, flavorUsers []*models.User) []*models.User {
+	byID := make(map[int64]*models.User, len(users))
+	for _, u := range users {
+		byID[u.ID] = u
+	}
+
+	for _, fu := range flavorUsers {
+		if u, ok := byID[fu.ID]; ok {
+			u.Flavors = append(u.Flavors, fu.Flavors...)
+			continue
+		}
+		byID[fu.ID] = fu
+		users = append(users, fu)
+	}
+
+	return users
+}
+
+// Matches reports whether the User is subscribed to the Flavor, or whether any of their saved
+// Ingredients or keywords whose scope includes the Store match it. Keywords are matched by
+// MatchesKeyword.
+func Matches(u *models.User, store *models.Store, flavor *models.Flavor) bool {
+	for _, uf := range u.Flavors {
+		if uf.Flavor != nil && uf.Flavor.ID == flavor.ID {
+			return true
+		}
+	}
+
+	for _, ui := range u.Ingredients {
+		if !ui.Scope.Includes(store) {
+			continue
+		}
+
+		if ui.Ingredient != nil {
+			for _, i := range flavor.Ingredients {
+				if ui.Ingredient.ID == i.ID {
+					return true
+				}
+			}
+		}
+
+		if ui.Keyword != "" && MatchesKeyword(ui.Keyword, flavor) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Digest composes one SMS body for the Notifications. A single Notification is sent as is.
+func Digest(notifications []*models.Notification) string {
+	if len(notifications) == 1 {
+		return notifications[0].Body
+	}
+
+	lines := []string{"Here's what's new in the cooler 🍦"}
+	for _, n := range notifications {
+		lines = append(lines, fmt.Sprintf("%s at %s", n.FlavorName, n.StoreName))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Summary composes the daily or weekly digest SMS body for the Notifications, listing the
+// Flavors activated at each Store in the order they were activated.
+func Summary(frequency models.Frequency, notifications []*models.Notification) string {
+	var stores []string
+	flavors := map[string][]string{}
+	seen := map[string]bool{}
+
+	for _, n := range notifications {
+		if _, ok := flavors[n.StoreName]; !ok {
+			stores = append(stores, n.StoreName)
+		}
+
+		key := n.StoreName + "\x00" + n.FlavorName
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		flavors[n.StoreName] = append(flavors[n.StoreName], n.FlavorName)
+	}
+
+	lines := []string{fmt.Sprintf("Your %s flavor digest 🍦", frequency)}
+	for _, store := range stores {
+		lines = append(lines, fmt.Sprintf("%s: %s", store, strings.Join(flavors[store], ", ")))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Message composes the SMS body announcing that the Flavor is active at the Store.
+func Message(store *models.Store, flavor *models.Flavor) string {
+	return fmt.Sprintf(`%s is in the cooler at %s! 🍦`, flavor.Name, store.Name)
+}
@@ -0,0 +1,342 @@
+package notify_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jcorry/morellis/pkg/models"
+	"github.com/jcorry/morellis/pkg/models/modelsfakes"
+	"github.com/jcorry/morellis/pkg/notify"
+	"github.com/jcorry/morellis/pkg/sms/smsfakes"
+)
+
+var (
+	store = &models.Store{ID: 1, Name: "Morellis On Moreland", Lat: 33.733951, Lng: -84.349625}
+
+	coconutJalapeno = &models.Flavor{
+		ID:          1,
+		Name:        "Coconut Jalapeno",
+		Description: "Our fresh made coconut ice cream is infused with just the right amount of fresh jalapenos.",
+		Ingredients: []models.Ingredient{
+			{ID: 1, Name: "coconut"},
+			{ID: 2, Name: "jalapeno"},
+		},
+	}
+)
+
+func subscriber(phone string, subscriptions ...models.UserIngredient) *models.User {
+	return &models.User{
+		UUID:        uuid.New(),
+		Phone:       phone,
+		Ingredients: subscriptions,
+	}
+}
+
+func digestSubscriber(phone string, frequency models.Frequency, subscriptions ...models.UserIngredient) *models.User {
+	u := subscriber(phone, subscriptions...)
+	u.Frequency = frequency
+	return u
+}
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		name string
+		user *models.User
+		want bool
+	}{
+		{
+			"ingredient match",
+			subscriber("4045551212", models.UserIngredient{Ingredient: &models.Ingredient{ID: 2, Name: "jalapeno"}}),
+			true,
+		},
+		{
+			"no ingredient match",
+			subscriber("4045551212", models.UserIngredient{Ingredient: &models.Ingredient{ID: 4, Name: "pecan"}}),
+			false,
+		},
+		{
+			"keyword matches name",
+			subscriber("4045551212", models.UserIngredient{Ingredient: &models.Ingredient{ID: 4}, Keyword: "COCONUT"}),
+			true,
+		},
+		{
+			"keyword matches description",
+			subscriber("4045551212", models.UserIngredient{Ingredient: &models.Ingredient{ID: 4}, Keyword: "fresh made"}),
+			true,
+		},
+		{
+			"keyword does not match",
+			subscriber("4045551212", models.UserIngredient{Ingredient: &models.Ingredient{ID: 4}, Keyword: "chocolate"}),
+			false,
+		},
+		{
+			"flavor subscription",
+			&models.User{Flavors: []models.UserFlavor{{Flavor: &models.Flavor{ID: 1}}}},
+			true,
+		},
+		{
+			"other flavor subscription",
+			&models.User{Flavors: []models.UserFlavor{{Flavor: &models.Flavor{ID: 2}}}},
+			false,
+		},
+		{
+			"scoped to the store",
+			subscriber("4045551212", models.UserIngredient{
+				Ingredient: &models.Ingredient{ID: 2, Name: "jalapeno"},
+				Scope:      models.SubscriptionScope{StoreIDs: []int64{2, 1}},
+			}),
+			true,
+		},
+		{
+			"scoped to another store",
+			subscriber("4045551212", models.UserIngredient{
+				Ingredient: &models.Ingredient{ID: 2, Name: "jalapeno"},
+				Scope:      models.SubscriptionScope{StoreIDs: []int64{2}},
+			}),
+			false,
+		},
+		{
+			"scoped to an area around the store",
+			subscriber("4045551212", models.UserIngredient{
+				Ingredient: &models.Ingredient{ID: 2, Name: "jalapeno"},
+				Scope:      models.SubscriptionScope{Area: &models.Area{Lat: 33.7488, Lng: -84.3877, RadiusMiles: 5}},
+			}),
+			true,
+		},
+		{
+			"scoped to an area away from the store",
+			subscriber("4045551212", models.UserIngredient{
+				Ingredient: &models.Ingredient{ID: 2, Name: "jalapeno"},
+				Scope:      models.SubscriptionScope{Area: &models.Area{Lat: 33.922714, Lng: -84.315169, RadiusMiles: 5}},
+			}),
+			false,
+		},
+		{
+			"no subscriptions",
+			subscriber("4045551212"),
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, notify.Matches(tt.user, store, coconutJalapeno))
+		})
+	}
+}
+
+func TestDispatcher_FlavorActivated(t *testing.T) {
+	tests := []struct {
+		name        string
+		users       []*models.User
+		flavorUsers []*models.User
+		usersErr    error
+		sendErr     error
+		wantSaved   int
+		wantPhones  []string
+		wantSent    int
+		wantErr     bool
+	}{
+		{
+			name: "notifies each matching user once",
+			users: []*models.User{
+				subscriber("4045551212",
+					models.UserIngredient{Ingredient: &models.Ingredient{ID: 1, Name: "coconut"}},
+					models.UserIngredient{Ingredient: &models.Ingredient{ID: 2, Name: "jalapeno"}},
+				),
+				subscriber("4045551313", models.UserIngredient{Ingredient: &models.Ingredient{ID: 4}, Keyword: "pecan"}),
+				subscriber("4045551414", models.UserIngredient{Ingredient: &models.Ingredient{ID: 4}, Keyword: "coconut"}),
+			},
+			wantPhones: []string{"4045551212", "4045551414"},
+			wantSent:   2,
+		},
+		{
+			name: "notifies flavor subscribers once",
+			users: []*models.User{
+				{ID: 1, Phone: "4045551212", Ingredients: []models.UserIngredient{{Ingredient: &models.Ingredient{ID: 1, Name: "coconut"}}}},
+			},
+			flavorUsers: []*models.User{
+				{ID: 1, Phone: "4045551212", Flavors: []models.UserFlavor{{Flavor: &models.Flavor{ID: 1}}}},
+				{ID: 2, Phone: "4045551313", Flavors: []models.UserFlavor{{Flavor: &models.Flavor{ID: 1}}}},
+			},
+			wantPhones: []string{"4045551212", "4045551313"},
+			wantSent:   2,
+		},
+		{
+			name: "holds notifications for digest users",
+			users: []*models.User{
+				subscriber("4045551212", models.UserIngredient{Ingredient: &models.Ingredient{ID: 1, Name: "coconut"}}),
+				digestSubscriber("4045551313", models.FREQUENCY_DAILY, models.UserIngredient{Ingredient: &models.Ingredient{ID: 1, Name: "coconut"}}),
+			},
+			wantSaved:  2,
+			wantPhones: []string{"4045551212"},
+			wantSent:   1,
+		},
+		{
+			name:     "no subscribers",
+			users:    []*models.User{},
+			wantSent: 0,
+		},
+		{
+			name:     "subscriber lookup fails",
+			usersErr: errors.New("db error"),
+			wantErr:  true,
+		},
+		{
+			name: "send fails",
+			users: []*models.User{
+				subscriber("4045551212", models.UserIngredient{Ingredient: &models.Ingredient{ID: 1, Name: "coconut"}}),
+			},
+			sendErr:    errors.New("twilio error"),
+			wantPhones: []string{"4045551212"},
+			wantSent:   0,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			users := &modelsfakes.FakeUserRepository{}
+			users.GetSubscribersReturns(tt.users, tt.usersErr)
+			users.GetFlavorSubscribersReturns(tt.flavorUsers, nil)
+			sender := &smsfakes.FakeMessager{}
+			sender.SendReturns("message-sid", tt.sendErr)
+			notifications := &modelsfakes.FakeNotificationRepository{}
+			notifications.ClaimReturns([]*models.Notification{
+				{ID: 1, Body: "Coconut Jalapeno is in the cooler at Morellis On Moreland! 🍦"},
+			}, nil)
+			notifications.GetPolicyReturns(&models.NotificationPolicy{}, nil)
+
+			d := notify.NewDispatcher(users, notifications, sender, nil)
+			sent, err := d.FlavorActivated(context.TODO(), store, coconutJalapeno)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FlavorActivated() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			require.Equal(t, tt.wantSent, sent)
+
+			require.Equal(t, 1, users.GetSubscribersCallCount())
+			require.Equal(t, []int64{1, 2}, users.GetSubscribersArgsForCall(0))
+
+			wantSaved := tt.wantSaved
+			if wantSaved == 0 {
+				wantSaved = len(tt.wantPhones)
+			}
+			require.Equal(t, wantSaved, notifications.InsertCallCount())
+			require.Equal(t, tt.wantSent, notifications.MarkSentCallCount())
+			require.Equal(t, len(tt.wantPhones), sender.SendCallCount())
+			for i, phone := range tt.wantPhones {
+				_, number, message := sender.SendArgsForCall(i)
+				require.Equal(t, phone, number)
+				require.Equal(t, "Coconut Jalapeno is in the cooler at Morellis On Moreland! 🍦", message)
+			}
+		})
+	}
+}
+
+func TestDispatcher_FlushHeldByPolicy(t *testing.T) {
+	notifications := &modelsfakes.FakeNotificationRepository{}
+	notifications.PendingUsersReturns([]int64{1}, nil)
+	notifications.ClaimReturns([]*models.Notification{
+		{ID: 1, Phone: "4045551212", Body: "Coconut Jalapeno is in the cooler at Morellis On Moreland! 🍦"},
+	}, nil)
+	notifications.GetPolicyReturns(&models.NotificationPolicy{MaxPerDay: 1}, nil)
+	notifications.CountSentReturns(1, nil)
+	sender := &smsfakes.FakeMessager{}
+
+	d := notify.NewDispatcher(&modelsfakes.FakeUserRepository{}, notifications, sender, nil)
+	sent, err := d.Flush(context.TODO())
+	require.NoError(t, err)
+	require.Equal(t, 0, sent)
+	require.Equal(t, 0, sender.SendCallCount())
+	require.Equal(t, 0, notifications.MarkSentCallCount())
+	// Notifications held back are released to be claimed again
+	userID, claim := notifications.ClaimArgsForCall(0)
+	require.Equal(t, int64(1), userID)
+	require.Equal(t, 1, notifications.ReleaseCallCount())
+	require.Equal(t, claim, notifications.ReleaseArgsForCall(0))
+
+	notifications.CountSentReturns(0, nil)
+	sent, err = d.Flush(context.TODO())
+	require.NoError(t, err)
+	require.Equal(t, 1, sent)
+	require.Equal(t, 1, sender.SendCallCount())
+	_, number, _ := sender.SendArgsForCall(0)
+	require.Equal(t, "4045551212", number)
+}
+
+func TestDispatcher_FlushClaimed(t *testing.T) {
+	notifications := &modelsfakes.FakeNotificationRepository{}
+	notifications.PendingUsersReturns([]int64{1}, nil)
+	// Another Dispatcher already claimed the User's pending Notifications
+	notifications.ClaimReturns([]*models.Notification{}, nil)
+	sender := &smsfakes.FakeMessager{}
+
+	d := notify.NewDispatcher(&modelsfakes.FakeUserRepository{}, notifications, sender, nil)
+	sent, err := d.Flush(context.TODO())
+	require.NoError(t, err)
+	require.Equal(t, 0, sent)
+	require.Equal(t, 0, notifications.GetPolicyCallCount())
+	require.Equal(t, 0, sender.SendCallCount())
+	require.Equal(t, 0, notifications.ReleaseCallCount())
+}
+
+func TestDigest(t *testing.T) {
+	single := []*models.Notification{{Body: "Coconut Jalapeno is in the cooler at Morellis On Moreland! 🍦"}}
+	require.Equal(t, single[0].Body, notify.Digest(single))
+
+	several := []*models.Notification{
+		{FlavorName: "Coconut Jalapeno", StoreName: "Morellis On Moreland"},
+		{FlavorName: "Salted Caramel", StoreName: "Morellis Decatur"},
+	}
+	require.Equal(t, "Here's what's new in the cooler 🍦\nCoconut Jalapeno at Morellis On Moreland\nSalted Caramel at Morellis Decatur", notify.Digest(several))
+}
+
+func TestDispatcher_SendDigests(t *testing.T) {
+	yesterday := time.Now().AddDate(0, 0, -1)
+
+	notifications := &modelsfakes.FakeNotificationRepository{}
+	notifications.PendingUsersReturns([]int64{1}, nil)
+	notifications.ClaimReturns([]*models.Notification{
+		{ID: 1, Phone: "4045551212", StoreName: "Morellis On Moreland", FlavorName: "Coconut Jalapeno", Created: yesterday.Add(-time.Hour)},
+		{ID: 2, Phone: "4045551212", StoreName: "Morellis On Moreland", FlavorName: "Salted Caramel", Created: yesterday},
+	}, nil)
+	notifications.GetPolicyReturns(&models.NotificationPolicy{}, nil)
+	sender := &smsfakes.FakeMessager{}
+	sender.SendReturns("message-sid", nil)
+
+	d := notify.NewDispatcher(&modelsfakes.FakeUserRepository{}, notifications, sender, nil)
+	sent, err := d.SendDigests(context.TODO(), models.FREQUENCY_DAILY)
+	require.NoError(t, err)
+	require.Equal(t, 1, sent)
+
+	require.Equal(t, models.FREQUENCY_DAILY, notifications.PendingUsersArgsForCall(0))
+
+	_, number, message := sender.SendArgsForCall(0)
+	require.Equal(t, "4045551212", number)
+	require.Equal(t, "Your daily flavor digest 🍦\nMorellis On Moreland: Coconut Jalapeno, Salted Caramel", message)
+
+	IDs, ref := notifications.MarkSentArgsForCall(0)
+	require.Equal(t, []int64{1, 2}, IDs)
+	require.Equal(t, "message-sid", ref)
+}
+
+func TestSummary(t *testing.T) {
+	notifications := []*models.Notification{
+		{FlavorName: "Coconut Jalapeno", StoreName: "Morellis On Moreland"},
+		{FlavorName: "Salted Caramel", StoreName: "Morellis Decatur"},
+		{FlavorName: "Salted Caramel", StoreName: "Morellis On Moreland"},
+		{FlavorName: "Coconut Jalapeno", StoreName: "Morellis On Moreland"},
+	}
+
+	want := "Your weekly flavor digest 🍦\n" +
+		"Morellis On Moreland: Coconut Jalapeno, Salted Caramel\n" +
+		"Morellis Decatur: Salted Caramel"
+	require.Equal(t, want, notify.Summary(models.FREQUENCY_WEEKLY, notifications))
+}
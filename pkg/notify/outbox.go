@@ -0,0 +1,178 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/jcorry/morellis/pkg/models"
+	"github.com/jcorry/morellis/pkg/sms"
+)
+
+const (
+	DEFAULT_WORKERS       int           = 4
+	DEFAULT_BATCH_SIZE    int           = 20
+	DEFAULT_MAX_ATTEMPTS  int           = 6
+	DEFAULT_POLL_INTERVAL time.Duration = 2 * time.Second
+	DEFAULT_BACKOFF       time.Duration = 30 * time.Second
+	MAX_BACKOFF           time.Duration = time.Hour
+	CLAIM_LEASE           time.Duration = 5 * time.Minute
+)
+
+// Outbox is an sms.Messager that queues messages in the outbox to be sent asynchronously
+// by a Worker, rather than sending them to the provider directly.
+type Outbox struct {
+	messages models.MessageRepository
+}
+
+// NewOutbox configures and returns a new Outbox
+func NewOutbox(messages models.MessageRepository) *Outbox {
+	return &Outbox{messages: messages}
+}
+
+// Send queues `message` for delivery to `number`. The returned string is the ID of the
+// queued Message; the provider's SID is recorded against it once it has been sent.
+func (o *Outbox) Send(ctx context.Context, number, message string) (string, error) {
+	msg, err := o.messages.Insert(number, message)
+	if err != nil {
+		return "", err
+	}
+
+	return strconv.FormatInt(msg.ID, 10), nil
+}
+
+// Worker drains the outbox, sending queued Messages with `sender`. Failed sends are
+// retried with exponential backoff until MaxAttempts is reached, after which the
+// Message is marked dead.
+type Worker struct {
+	messages models.MessageRepository
+	sender   sms.Messager
+	errorLog *log.Logger
+
+	Workers      int
+	BatchSize    int
+	MaxAttempts  int
+	PollInterval time.Duration
+	Backoff      time.Duration
+}
+
+// NewWorker configures and returns a new Worker using the default settings. errorLog may be nil.
+func NewWorker(messages models.MessageRepository, sender sms.Messager, errorLog *log.Logger) *Worker {
+	if errorLog == nil {
+		errorLog = log.New(ioutil.Discard, "", 0)
+	}
+
+	return &Worker{
+		messages:     messages,
+		sender:       sender,
+		errorLog:     errorLog,
+		Workers:      DEFAULT_WORKERS,
+		BatchSize:    DEFAULT_BATCH_SIZE,
+		MaxAttempts:  DEFAULT_MAX_ATTEMPTS,
+		PollInterval: DEFAULT_POLL_INTERVAL,
+		Backoff:      DEFAULT_BACKOFF,
+	}
+}
+
+// Run drains the outbox every PollInterval until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		_, err := w.Drain(ctx)
+		if err != nil {
+			w.errorLog.Output(2, err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Drain claims a batch of due Messages and sends them concurrently, returning the number
+// of Messages claimed.
+func (w *Worker) Drain(ctx context.Context) (int, error) {
+	messages, err := w.messages.Claim(w.BatchSize, CLAIM_LEASE)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to claim outbox messages")
+	}
+
+	workers := w.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	var wg sync.WaitGroup
+	queue := make(chan *models.Message)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for msg := range queue {
+				w.deliver(ctx, msg)
+			}
+		}()
+	}
+
+	for _, msg := range messages {
+		queue <- msg
+	}
+	close(queue)
+	wg.Wait()
+
+	return len(messages), nil
+}
+
+func (w *Worker) deliver(ctx context.Context, msg *models.Message) {
+	sid, err := w.sender.Send(ctx, msg.Phone, msg.Body)
+	if err == nil {
+		err = w.messages.MarkSent(msg.ID, sid)
+		if err != nil {
+			w.errorLog.Output(2, fmt.Sprintf("failed to mark message %d sent: %s", msg.ID, err))
+		}
+		return
+	}
+
+	if errors.Cause(err) == sms.ErrSuppressed {
+		// The recipient opted out; retrying won't change that
+		err = w.messages.MarkDead(msg.ID, err.Error())
+	} else if msg.Attempts >= w.MaxAttempts {
+		w.errorLog.Output(2, fmt.Sprintf("message %d is dead after %d attempts: %s", msg.ID, msg.Attempts, err))
+		err = w.messages.MarkDead(msg.ID, err.Error())
+	} else {
+		err = w.messages.MarkFailed(msg.ID, err.Error(), time.Now().Add(Backoff(msg.Attempts, w.Backoff)))
+	}
+
+	if err != nil {
+		w.errorLog.Output(2, fmt.Sprintf("failed to mark message %d failed: %s", msg.ID, err))
+	}
+}
+
+// Backoff returns how long to wait before the next attempt, following `attempt` failed
+// attempts. The delay doubles from `base` with each attempt, up to MAX_BACKOFF.
+func Backoff(attempt int, base time.Duration) time.Duration {
+	if attempt < 1 {
+		return base
+	}
+
+	d := base
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if d >= MAX_BACKOFF {
+			return MAX_BACKOFF
+		}
+	}
+
+	return d
+}
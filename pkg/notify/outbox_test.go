@@ -0,0 +1,107 @@
+package notify_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jcorry/morellis/pkg/models"
+	"github.com/jcorry/morellis/pkg/models/modelsfakes"
+	"github.com/jcorry/morellis/pkg/notify"
+	"github.com/jcorry/morellis/pkg/sms"
+	"github.com/jcorry/morellis/pkg/sms/smsfakes"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, 30 * time.Second},
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{5, 8 * time.Minute},
+		{12, notify.MAX_BACKOFF},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.want, notify.Backoff(tt.attempt, 30*time.Second), "attempt %d", tt.attempt)
+	}
+}
+
+func TestOutbox_Send(t *testing.T) {
+	messages := &modelsfakes.FakeMessageRepository{}
+	messages.InsertReturns(&models.Message{ID: 42}, nil)
+
+	id, err := notify.NewOutbox(messages).Send(context.TODO(), "4045551212", "hello")
+	require.NoError(t, err)
+	require.Equal(t, "42", id)
+
+	phone, body := messages.InsertArgsForCall(0)
+	require.Equal(t, "4045551212", phone)
+	require.Equal(t, "hello", body)
+}
+
+func TestWorker_Drain(t *testing.T) {
+	tests := []struct {
+		name       string
+		attempts   int
+		sendErr    error
+		wantSent   int
+		wantFailed int
+		wantDead   int
+	}{
+		{"sent", 1, nil, 1, 0, 0},
+		{"failed is retried", 2, errors.New("twilio error"), 0, 1, 0},
+		{"failed on last attempt is dead", notify.DEFAULT_MAX_ATTEMPTS, errors.New("twilio error"), 0, 0, 1},
+		{"suppressed is dead", 1, sms.ErrSuppressed, 0, 0, 1},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			messages := &modelsfakes.FakeMessageRepository{}
+			messages.ClaimReturns([]*models.Message{
+				{ID: 7, Phone: "4045551212", Body: "hello", Attempts: tt.attempts},
+			}, nil)
+			sender := &smsfakes.FakeMessager{}
+			sender.SendReturns("SM123", tt.sendErr)
+
+			w := notify.NewWorker(messages, sender, nil)
+			n, err := w.Drain(context.TODO())
+			require.NoError(t, err)
+			require.Equal(t, 1, n)
+
+			require.Equal(t, 1, sender.SendCallCount())
+			require.Equal(t, tt.wantSent, messages.MarkSentCallCount())
+			require.Equal(t, tt.wantFailed, messages.MarkFailedCallCount())
+			require.Equal(t, tt.wantDead, messages.MarkDeadCallCount())
+
+			if tt.wantSent > 0 {
+				id, sid := messages.MarkSentArgsForCall(0)
+				require.Equal(t, int64(7), id)
+				require.Equal(t, "SM123", sid)
+			}
+
+			if tt.wantFailed > 0 {
+				_, reason, next := messages.MarkFailedArgsForCall(0)
+				require.Equal(t, "twilio error", reason)
+				require.WithinDuration(t, time.Now().Add(notify.Backoff(tt.attempts, notify.DEFAULT_BACKOFF)), next, time.Second)
+			}
+		})
+	}
+}
+
+func TestWorker_DrainClaimError(t *testing.T) {
+	messages := &modelsfakes.FakeMessageRepository{}
+	messages.ClaimReturns(nil, errors.New("db error"))
+	sender := &smsfakes.FakeMessager{}
+
+	_, err := notify.NewWorker(messages, sender, nil).Drain(context.TODO())
+	require.Error(t, err)
+	require.Equal(t, 0, sender.SendCallCount())
+}
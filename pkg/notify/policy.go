@@ -0,0 +1,112 @@
+package notify
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/jcorry/morellis/pkg/models"
+)
+
+// Allows reports whether the policy allows a message to be sent at `now`, local to `loc`,
+// given the number of messages already sent since local midnight and when the last one was sent.
+func Allows(p *models.NotificationPolicy, now time.Time, loc *time.Location, sentToday int, lastSent time.Time) bool {
+	if p.MaxPerDay > 0 && sentToday >= p.MaxPerDay {
+		return false
+	}
+
+	if p.MinIntervalMinutes > 0 && !lastSent.IsZero() &&
+		now.Before(lastSent.Add(time.Duration(p.MinIntervalMinutes)*time.Minute)) {
+		return false
+	}
+
+	return !InQuietHours(p, now.In(loc))
+}
+
+// InQuietHours reports whether the clock time of `t` falls within the policy's quiet hours.
+// Quiet hours which can't be parsed are ignored.
+func InQuietHours(p *models.NotificationPolicy, t time.Time) bool {
+	start, err := ParseClock(p.QuietStart)
+	if err != nil {
+		return false
+	}
+	end, err := ParseClock(p.QuietEnd)
+	if err != nil || start == end {
+		return false
+	}
+
+	now := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+
+	if start < end {
+		return now >= start && now < end
+	}
+
+	// Quiet hours span midnight
+	return now >= start || now < end
+}
+
+// ParseClock parses a "15:04" clock time into the time since midnight.
+func ParseClock(clock string) (time.Duration, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid clock time %q", clock)
+	}
+
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// Midnight returns the start of the day of `t` in `loc`.
+func Midnight(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// location loads the named time zone, falling back to DEFAULT_TIMEZONE.
+func location(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil || name == "" {
+		loc, err = time.LoadLocation(models.DEFAULT_TIMEZONE)
+		if err != nil {
+			return time.UTC
+		}
+	}
+
+	return loc
+}
+
+// DIGEST_TIME is the "15:04" clock time, local to the Store, at which digests are sent.
+// Weekly digests are sent on DIGEST_WEEKDAY.
+const (
+	DIGEST_TIME    string       = "11:00"
+	DIGEST_WEEKDAY time.Weekday = time.Friday
+)
+
+// DigestDue reports whether a daily or weekly digest is due at `now`, local to `loc`. A digest
+// is due once the most recent scheduled digest time has passed if nothing has been sent since
+// it, and the oldest pending Notification was created before it.
+func DigestDue(frequency models.Frequency, now time.Time, loc *time.Location, lastSent time.Time, oldestPending time.Time) bool {
+	at, err := ParseClock(DIGEST_TIME)
+	if err != nil {
+		return false
+	}
+
+	local := now.In(loc)
+	scheduled := time.Date(local.Year(), local.Month(), local.Day(), int(at/time.Hour), int(at%time.Hour/time.Minute), 0, 0, loc)
+
+	switch frequency {
+	case models.FREQUENCY_DAILY:
+		if scheduled.After(now) {
+			scheduled = scheduled.AddDate(0, 0, -1)
+		}
+	case models.FREQUENCY_WEEKLY:
+		days := (int(scheduled.Weekday()) - int(DIGEST_WEEKDAY) + 7) % 7
+		scheduled = scheduled.AddDate(0, 0, -days)
+		if scheduled.After(now) {
+			scheduled = scheduled.AddDate(0, 0, -7)
+		}
+	default:
+		return false
+	}
+
+	return lastSent.Before(scheduled) && oldestPending.Before(scheduled)
+}
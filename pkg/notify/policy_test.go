@@ -0,0 +1,102 @@
+package notify_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jcorry/morellis/pkg/models"
+	"github.com/jcorry/morellis/pkg/notify"
+)
+
+func TestInQuietHours(t *testing.T) {
+	overnight := &models.NotificationPolicy{QuietStart: "21:00", QuietEnd: "09:00"}
+	afternoon := &models.NotificationPolicy{QuietStart: "13:00", QuietEnd: "14:30"}
+
+	tests := []struct {
+		name   string
+		policy *models.NotificationPolicy
+		clock  string
+		want   bool
+	}{
+		{"overnight before start", overnight, "20:59", false},
+		{"overnight at start", overnight, "21:00", true},
+		{"overnight after midnight", overnight, "03:00", true},
+		{"overnight at end", overnight, "09:00", false},
+		{"same day inside", afternoon, "14:00", true},
+		{"same day outside", afternoon, "14:30", false},
+		{"no quiet hours", &models.NotificationPolicy{}, "03:00", false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			now, err := time.Parse("15:04", tt.clock)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, notify.InQuietHours(tt.policy, now))
+		})
+	}
+}
+
+func TestAllows(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	noon := time.Date(2021, 3, 5, 12, 0, 0, 0, loc)
+	policy := &models.NotificationPolicy{MaxPerDay: 3, MinIntervalMinutes: 60, QuietStart: "21:00", QuietEnd: "09:00"}
+
+	tests := []struct {
+		name      string
+		now       time.Time
+		sentToday int
+		lastSent  time.Time
+		want      bool
+	}{
+		{"never sent", noon, 0, time.Time{}, true},
+		{"under limit", noon, 2, noon.Add(-2 * time.Hour), true},
+		{"at limit", noon, 3, noon.Add(-2 * time.Hour), false},
+		{"too soon", noon, 1, noon.Add(-30 * time.Minute), false},
+		{"quiet hours", time.Date(2021, 3, 5, 22, 0, 0, 0, loc), 0, time.Time{}, false},
+		{"quiet hours in store time zone", time.Date(2021, 3, 5, 23, 0, 0, 0, time.UTC), 0, time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, notify.Allows(policy, tt.now, loc, tt.sentToday, tt.lastSent))
+		})
+	}
+}
+
+func TestDigestDue(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	// Friday 5 March 2021
+	friday := func(hour int) time.Time { return time.Date(2021, 3, 5, hour, 0, 0, 0, loc) }
+
+	tests := []struct {
+		name          string
+		frequency     models.Frequency
+		now           time.Time
+		lastSent      time.Time
+		oldestPending time.Time
+		want          bool
+	}{
+		{"daily after digest time", models.FREQUENCY_DAILY, friday(12), friday(-13), friday(-2), true},
+		{"daily already sent today", models.FREQUENCY_DAILY, friday(12), friday(11), friday(-2), false},
+		{"daily pending since digest time", models.FREQUENCY_DAILY, friday(12), time.Time{}, friday(11), false},
+		{"daily before digest time covers yesterday", models.FREQUENCY_DAILY, friday(9), friday(-24), friday(-14), true},
+		{"weekly on digest day", models.FREQUENCY_WEEKLY, friday(12), friday(-7 * 24), friday(-48), true},
+		{"weekly already sent", models.FREQUENCY_WEEKLY, friday(36), friday(11), friday(20), false},
+		{"immediate never digested", models.FREQUENCY_IMMEDIATE, friday(12), time.Time{}, friday(-2), false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, notify.DigestDue(tt.frequency, tt.now, loc, tt.lastSent, tt.oldestPending))
+		})
+	}
+}
@@ -0,0 +1,152 @@
+// Package report summarizes the history of the Flavors rotated through the Positions at each
+// Store, for the reporting dashboard.
+package report
+
+import (
+	"sort"
+
+	"github.com/jcorry/morellis/pkg/models"
+)
+
+// Stats summarizes a set of Rotations. Active counts the Rotations which haven't been
+// deactivated. The durations, in hours, are of the Rotations which have, and are 0 until there
+// are any.
+type Stats struct {
+	Rotations     int     `json:"rotations"`
+	Active        int     `json:"active"`
+	AverageHours  float64 `json:"averageHours"`
+	LongestHours  float64 `json:"longestHours"`
+	ShortestHours float64 `json:"shortestHours"`
+}
+
+// StoreStats summarizes the Rotations at a Store
+type StoreStats struct {
+	StoreID   int64  `json:"storeId"`
+	StoreName string `json:"storeName"`
+	Stats
+}
+
+// FlavorStats summarizes the Rotations of a Flavor
+type FlavorStats struct {
+	Flavor *models.Flavor `json:"flavor"`
+	Stats
+}
+
+// PositionTimeline is the Rotations which occupied a Position at a Store, oldest first
+type PositionTimeline struct {
+	Position  int                `json:"position"`
+	Rotations []*models.Rotation `json:"rotations"`
+}
+
+// Summarize summarizes the Rotations
+func Summarize(rotations []*models.Rotation) Stats {
+	s := Stats{}
+	var total float64
+	var completed int
+
+	for _, r := range rotations {
+		s.Rotations++
+
+		if r.Deactivated == nil {
+			s.Active++
+			continue
+		}
+
+		hours := r.Deactivated.Sub(r.Activated).Hours()
+		total += hours
+
+		if completed == 0 || hours > s.LongestHours {
+			s.LongestHours = hours
+		}
+		if completed == 0 || hours < s.ShortestHours {
+			s.ShortestHours = hours
+		}
+		completed++
+	}
+
+	if completed > 0 {
+		s.AverageHours = total / float64(completed)
+	}
+
+	return s
+}
+
+// ByStore summarizes the Rotations at each Store, ordered by Store name
+func ByStore(rotations []*models.Rotation) []*StoreStats {
+	groups := map[int64][]*models.Rotation{}
+	stores := []*StoreStats{}
+
+	for _, r := range rotations {
+		if _, ok := groups[r.StoreID]; !ok {
+			stores = append(stores, &StoreStats{StoreID: r.StoreID, StoreName: r.StoreName})
+		}
+		groups[r.StoreID] = append(groups[r.StoreID], r)
+	}
+
+	for _, s := range stores {
+		s.Stats = Summarize(groups[s.StoreID])
+	}
+
+	sort.SliceStable(stores, func(i, j int) bool {
+		return stores[i].StoreName < stores[j].StoreName
+	})
+
+	return stores
+}
+
+// ByFlavor summarizes the Rotations of each Flavor, ordered by the number of Rotations, most
+// first, then by Flavor name.
+func ByFlavor(rotations []*models.Rotation) []*FlavorStats {
+	groups := map[int64][]*models.Rotation{}
+	flavors := []*FlavorStats{}
+
+	for _, r := range rotations {
+		if _, ok := groups[r.Flavor.ID]; !ok {
+			flavors = append(flavors, &FlavorStats{Flavor: r.Flavor})
+		}
+		groups[r.Flavor.ID] = append(groups[r.Flavor.ID], r)
+	}
+
+	for _, f := range flavors {
+		f.Stats = Summarize(groups[f.Flavor.ID])
+	}
+
+	sort.SliceStable(flavors, func(i, j int) bool {
+		a, b := flavors[i], flavors[j]
+		if a.Rotations != b.Rotations {
+			return a.Rotations > b.Rotations
+		}
+		return a.Flavor.Name < b.Flavor.Name
+	})
+
+	return flavors
+}
+
+// Timeline groups the Rotations at a Store by the Position they occupied, ordered by Position,
+// with each Position's Rotations ordered by when they were activated.
+func Timeline(rotations []*models.Rotation) []*PositionTimeline {
+	byPosition := map[int]*PositionTimeline{}
+	timeline := []*PositionTimeline{}
+
+	for _, r := range rotations {
+		p, ok := byPosition[r.Position]
+		if !ok {
+			p = &PositionTimeline{Position: r.Position}
+			byPosition[r.Position] = p
+			timeline = append(timeline, p)
+		}
+		p.Rotations = append(p.Rotations, r)
+	}
+
+	sort.SliceStable(timeline, func(i, j int) bool {
+		return timeline[i].Position < timeline[j].Position
+	})
+
+	for _, p := range timeline {
+		sort.SliceStable(p.Rotations, func(i, j int) bool {
+			return p.Rotations[i].Activated.Before(p.Rotations[j].Activated)
+		})
+	}
+
+	return timeline
+}
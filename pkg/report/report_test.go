@@ -0,0 +1,120 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jcorry/morellis/pkg/models"
+)
+
+var start = time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func rotation(id int64, storeID int64, position int, flavor *models.Flavor, activated float64, deactivated float64) *models.Rotation {
+	r := &models.Rotation{
+		ID:        id,
+		StoreID:   storeID,
+		StoreName: map[int64]string{1: "Midtown", 2: "Decatur"}[storeID],
+		Position:  position,
+		Flavor:    flavor,
+		Activated: start.Add(time.Duration(activated * float64(time.Hour))),
+	}
+
+	if deactivated > 0 {
+		d := start.Add(time.Duration(deactivated * float64(time.Hour)))
+		r.Deactivated = &d
+	}
+
+	return r
+}
+
+func TestSummarize(t *testing.T) {
+	vanilla := &models.Flavor{ID: 1, Name: "Vanilla"}
+
+	tests := []struct {
+		name      string
+		rotations []*models.Rotation
+		want      Stats
+	}{
+		{"no rotations", nil, Stats{}},
+		{
+			"only active rotations",
+			[]*models.Rotation{rotation(1, 1, 1, vanilla, 0, 0)},
+			Stats{Rotations: 1, Active: 1},
+		},
+		{
+			"completed and active rotations",
+			[]*models.Rotation{
+				rotation(1, 1, 1, vanilla, 0, 24),
+				rotation(2, 1, 1, vanilla, 24, 36),
+				rotation(3, 1, 2, vanilla, 0, 0),
+			},
+			Stats{Rotations: 3, Active: 1, AverageHours: 18, LongestHours: 24, ShortestHours: 12},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, Summarize(tt.rotations))
+		})
+	}
+}
+
+func TestByStore(t *testing.T) {
+	vanilla := &models.Flavor{ID: 1, Name: "Vanilla"}
+
+	stores := ByStore([]*models.Rotation{
+		rotation(1, 1, 1, vanilla, 0, 10),
+		rotation(2, 2, 1, vanilla, 0, 20),
+		rotation(3, 1, 1, vanilla, 10, 0),
+	})
+
+	require.Len(t, stores, 2)
+	require.Equal(t, "Decatur", stores[0].StoreName)
+	require.Equal(t, 1, stores[0].Rotations)
+	require.Equal(t, float64(20), stores[0].AverageHours)
+	require.Equal(t, "Midtown", stores[1].StoreName)
+	require.Equal(t, 2, stores[1].Rotations)
+	require.Equal(t, 1, stores[1].Active)
+}
+
+func TestByFlavor(t *testing.T) {
+	vanilla := &models.Flavor{ID: 1, Name: "Vanilla"}
+	pecan := &models.Flavor{ID: 2, Name: "Butter Pecan"}
+	mint := &models.Flavor{ID: 3, Name: "Mint"}
+
+	flavors := ByFlavor([]*models.Rotation{
+		rotation(1, 1, 1, vanilla, 0, 10),
+		rotation(2, 1, 2, mint, 0, 4),
+		rotation(3, 2, 1, pecan, 0, 6),
+		rotation(4, 1, 2, mint, 4, 0),
+	})
+
+	require.Len(t, flavors, 3)
+	require.Equal(t, "Mint", flavors[0].Flavor.Name)
+	require.Equal(t, 2, flavors[0].Rotations)
+	require.Equal(t, float64(4), flavors[0].LongestHours)
+	// Flavors with as many rotations are ordered by name
+	require.Equal(t, "Butter Pecan", flavors[1].Flavor.Name)
+	require.Equal(t, "Vanilla", flavors[2].Flavor.Name)
+}
+
+func TestTimeline(t *testing.T) {
+	vanilla := &models.Flavor{ID: 1, Name: "Vanilla"}
+	mint := &models.Flavor{ID: 3, Name: "Mint"}
+
+	timeline := Timeline([]*models.Rotation{
+		rotation(3, 1, 2, mint, 0, 0),
+		rotation(2, 1, 1, mint, 10, 0),
+		rotation(1, 1, 1, vanilla, 0, 10),
+	})
+
+	require.Len(t, timeline, 2)
+	require.Equal(t, 1, timeline[0].Position)
+	require.Len(t, timeline[0].Rotations, 2)
+	require.Equal(t, int64(1), timeline[0].Rotations[0].ID)
+	require.Equal(t, int64(2), timeline[0].Rotations[1].ID)
+	require.Equal(t, 2, timeline[1].Position)
+	require.Len(t, timeline[1].Rotations, 1)
+}
@@ -0,0 +1,78 @@
+package sms
+
+import (
+	"strings"
+)
+
+const (
+	CMD_ADD    = "ADD"
+	CMD_REMOVE = "REMOVE"
+	CMD_LIST   = "LIST"
+	CMD_STORES = "STORES"
+	CMD_STOP   = "STOP"
+	CMD_START  = "START"
+	CMD_HELP   = "HELP"
+	CMD_LOGIN  = "LOGIN"
+)
+
+// commandAliases maps the words customers might reasonably text to the command they mean.
+var commandAliases = map[string]string{
+	"ADD":         CMD_ADD,
+	"SUB":         CMD_ADD,
+	"SUBSCRIBE":   CMD_ADD,
+	"FOLLOW":      CMD_ADD,
+	"REMOVE":      CMD_REMOVE,
+	"RM":          CMD_REMOVE,
+	"DEL":         CMD_REMOVE,
+	"DELETE":      CMD_REMOVE,
+	"UNFOLLOW":    CMD_REMOVE,
+	"LIST":        CMD_LIST,
+	"LS":          CMD_LIST,
+	"MINE":        CMD_LIST,
+	"STORES":      CMD_STORES,
+	"STORE":       CMD_STORES,
+	"LOCATIONS":   CMD_STORES,
+	"STOP":        CMD_STOP,
+	"STOPALL":     CMD_STOP,
+	"CANCEL":      CMD_STOP,
+	"END":         CMD_STOP,
+	"QUIT":        CMD_STOP,
+	"START":       CMD_START,
+	"UNSTOP":      CMD_START,
+	"HELP":        CMD_HELP,
+	"INFO":        CMD_HELP,
+	"?":           CMD_HELP,
+	"LOGIN":       CMD_LOGIN,
+	"APP":         CMD_LOGIN,
+	"UNSUBSCRIBE": CMD_STOP,
+}
+
+// Command is an instruction parsed from the body of an inbound SMS. Keyword is the word the
+// customer actually texted to give it, upper-cased, which may be any of the Name's aliases.
+type Command struct {
+	Name    string
+	Keyword string
+	Args    string
+}
+
+// ParseCommand parses the body of an inbound SMS into a Command. The first word names
+// the command, case-insensitively, and the remainder of the message is its argument.
+// Messages that don't begin with a known command are parsed as HELP, with the whole
+// message as the argument.
+func ParseCommand(body string) Command {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return Command{Name: CMD_HELP}
+	}
+
+	fields := strings.Fields(body)
+	keyword := strings.ToUpper(strings.Trim(fields[0], ".!:,"))
+	name, ok := commandAliases[keyword]
+	if !ok {
+		return Command{Name: CMD_HELP, Args: body}
+	}
+
+	args := strings.TrimSpace(strings.TrimPrefix(body, fields[0]))
+
+	return Command{Name: name, Keyword: keyword, Args: strings.ToLower(args)}
+}
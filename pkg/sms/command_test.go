@@ -0,0 +1,38 @@
+package sms_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jcorry/morellis/pkg/sms"
+)
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		body string
+		want sms.Command
+	}{
+		{"ADD coconut", sms.Command{Name: sms.CMD_ADD, Keyword: "ADD", Args: "coconut"}},
+		{"  add   Peanut Butter ", sms.Command{Name: sms.CMD_ADD, Keyword: "ADD", Args: "peanut butter"}},
+		{"Subscribe: pecan", sms.Command{Name: sms.CMD_ADD, Keyword: "SUBSCRIBE", Args: "pecan"}},
+		{"REMOVE pecan", sms.Command{Name: sms.CMD_REMOVE, Keyword: "REMOVE", Args: "pecan"}},
+		{"list", sms.Command{Name: sms.CMD_LIST, Keyword: "LIST"}},
+		{"Stores", sms.Command{Name: sms.CMD_STORES, Keyword: "STORES"}},
+		{"STOP", sms.Command{Name: sms.CMD_STOP, Keyword: "STOP"}},
+		{"cancel", sms.Command{Name: sms.CMD_STOP, Keyword: "CANCEL"}},
+		{"Unsubscribe", sms.Command{Name: sms.CMD_STOP, Keyword: "UNSUBSCRIBE"}},
+		{"START", sms.Command{Name: sms.CMD_START, Keyword: "START"}},
+		{"help", sms.Command{Name: sms.CMD_HELP, Keyword: "HELP"}},
+		{"login", sms.Command{Name: sms.CMD_LOGIN, Keyword: "LOGIN"}},
+		{"", sms.Command{Name: sms.CMD_HELP}},
+		{"is coconut back?", sms.Command{Name: sms.CMD_HELP, Args: "is coconut back?"}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.body, func(t *testing.T) {
+			require.Equal(t, tt.want, sms.ParseCommand(tt.body))
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package sms
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// ErrInvalidSignature is returned when a webhook request can't be authenticated as coming
+// from the provider.
+var ErrInvalidSignature = errors.New("sms: invalid webhook signature")
+
+// Provider is an SMS provider. As well as sending messages, a Provider authenticates the
+// webhooks it posts to us and parses them into provider-neutral types.
+type Provider interface {
+	Messager
+	ParseInbound(r *http.Request) (*InboundMessage, error)
+	ParseStatus(r *http.Request) (*StatusReport, error)
+}
+
+// Replier is implemented by Providers which can reply to an inbound message in the response
+// to its webhook, rather than as a separate outbound message. Reply returns the response body
+// and its content type.
+type Replier interface {
+	Reply(body string) (contentType string, response []byte, err error)
+}
+
+// InboundMessage is an SMS sent to us by a customer. ID is the provider's identifier for it.
+type InboundMessage struct {
+	ID   string
+	From string
+	To   string
+	Body string
+}
+
+// StatusReport is a delivery status reported by the provider for a message we sent. ID is
+// the provider's identifier returned when the message was sent. ErrorCode is empty unless the
+// message could not be delivered.
+type StatusReport struct {
+	ID        string
+	Status    string
+	ErrorCode string
+}
@@ -0,0 +1,47 @@
+package sms
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// ErrSuppressed is returned when sending to a number which has opted out of receiving messages.
+var ErrSuppressed = errors.New("sms: recipient has opted out")
+
+// Suppressor reports whether a phone number has opted out of receiving messages.
+type Suppressor interface {
+	IsSuppressed(phone string) (bool, error)
+}
+
+// SuppressingMessager is a Messager which refuses to send to numbers that have opted out,
+// before handing the message to the Messager it wraps. Wrapping the provider's Messager
+// ensures every outbound message is checked, however it was sent.
+type SuppressingMessager struct {
+	next       Messager
+	suppressor Suppressor
+}
+
+// NewSuppressingMessager configures and returns a new SuppressingMessager wrapping `next`.
+func NewSuppressingMessager(next Messager, suppressor Suppressor) *SuppressingMessager {
+	return &SuppressingMessager{
+		next:       next,
+		suppressor: suppressor,
+	}
+}
+
+// Send sends the message with the wrapped Messager, unless `number` has opted out, in which
+// case ErrSuppressed is returned. If the suppression list can't be checked the message is
+// not sent.
+func (m *SuppressingMessager) Send(ctx context.Context, number, message string) (string, error) {
+	suppressed, err := m.suppressor.IsSuppressed(number)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to check suppression list")
+	}
+
+	if suppressed {
+		return "", ErrSuppressed
+	}
+
+	return m.next.Send(ctx, number, message)
+}
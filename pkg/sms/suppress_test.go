@@ -0,0 +1,55 @@
+package sms_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jcorry/morellis/pkg/sms"
+	"github.com/jcorry/morellis/pkg/sms/smsfakes"
+)
+
+type suppressor struct {
+	suppressed bool
+	err        error
+}
+
+func (s suppressor) IsSuppressed(phone string) (bool, error) {
+	return s.suppressed, s.err
+}
+
+func TestSuppressingMessager_Send(t *testing.T) {
+	tests := []struct {
+		name       string
+		suppressor suppressor
+		wantSends  int
+		wantErr    error
+	}{
+		{"not suppressed", suppressor{}, 1, nil},
+		{"suppressed", suppressor{suppressed: true}, 0, sms.ErrSuppressed},
+		{"suppression list unavailable", suppressor{err: errors.New("db error")}, 0, nil},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			next := &smsfakes.FakeMessager{}
+			next.SendReturns("SM123", nil)
+
+			sid, err := sms.NewSuppressingMessager(next, tt.suppressor).Send(context.TODO(), "4045551212", "hello")
+			require.Equal(t, tt.wantSends, next.SendCallCount())
+
+			switch {
+			case tt.wantSends > 0:
+				require.NoError(t, err)
+				require.Equal(t, "SM123", sid)
+			case tt.wantErr != nil:
+				require.Equal(t, tt.wantErr, err)
+			default:
+				require.Error(t, err)
+			}
+		})
+	}
+}
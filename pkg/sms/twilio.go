@@ -16,24 +16,51 @@ import (
 	"github.com/pkg/errors"
 )
 
+const TWILIO_BASE_URL = `https://api.twilio.com`
+
 // TwilioMessager is a Twilio message sending struct
 type TwilioMessager struct {
-	client *http.Client
-	sid    string
-	token  string
-	from   string
+	client         *http.Client
+	sid            string
+	token          string
+	from           string
+	baseURL        string
+	webhookHost    string
+	statusCallback string
 }
 
 // NewTwilioMessager configures and returns a new TwilioMessager
 func NewTwilioMessager(c *http.Client, sid, token, from string) TwilioMessager {
 	return TwilioMessager{
-		client: c,
-		sid:    sid,
-		token:  token,
-		from:   from,
+		client:  c,
+		sid:     sid,
+		token:   token,
+		from:    from,
+		baseURL: TWILIO_BASE_URL,
 	}
 }
 
+// WithBaseURL returns a copy of the TwilioMessager which sends requests to the REST API at `url`
+// rather than TWILIO_BASE_URL.
+func (t TwilioMessager) WithBaseURL(url string) TwilioMessager {
+	t.baseURL = url
+	return t
+}
+
+// WithWebhookHost returns a copy of the TwilioMessager which validates webhook signatures as
+// requests to `host`, the scheme and host Twilio was configured to post to.
+func (t TwilioMessager) WithWebhookHost(host string) TwilioMessager {
+	t.webhookHost = host
+	return t
+}
+
+// WithStatusCallback returns a copy of the TwilioMessager which asks Twilio to post delivery
+// status updates for each message it sends to `url`.
+func (t TwilioMessager) WithStatusCallback(url string) TwilioMessager {
+	t.statusCallback = url
+	return t
+}
+
 // Send sends an SMS containing `message` via twilio to `number`
 func (t TwilioMessager) Send(ctx context.Context, number, message string) (string, error) {
 	req, err := t.SMSRequest(number, message)
@@ -64,7 +91,7 @@ func (t TwilioMessager) Send(ctx context.Context, number, message string) (strin
 
 // SMSRequest configures an HTTP request to send to the Twilio REST API
 func (t TwilioMessager) SMSRequest(to, body string) (*http.Request, error) {
-	u, err := url.Parse(fmt.Sprintf(`https://api.twilio.com/2010-04-01/Accounts/%s/Messages.json`, t.sid))
+	u, err := url.Parse(fmt.Sprintf(`%s/2010-04-01/Accounts/%s/Messages.json`, t.baseURL, t.sid))
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to parse sms request url")
 	}
@@ -72,6 +99,9 @@ func (t TwilioMessager) SMSRequest(to, body string) (*http.Request, error) {
 	d.Set("To", to)
 	d.Set("From", t.from)
 	d.Set("Body", body)
+	if t.statusCallback != "" {
+		d.Set("StatusCallback", t.statusCallback)
+	}
 
 	req, err := http.NewRequest("POST", u.String(), strings.NewReader(d.Encode()))
 	if err != nil {
@@ -84,6 +114,47 @@ func (t TwilioMessager) SMSRequest(to, body string) (*http.Request, error) {
 	return req, nil
 }
 
+// ParseInbound validates the signature of an incoming message webhook and returns the message.
+func (t TwilioMessager) ParseInbound(r *http.Request) (*InboundMessage, error) {
+	err := ValidateIncomingRequest(t.webhookHost, t.token, r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &InboundMessage{
+		ID:   r.FormValue(`MessageSid`),
+		From: r.FormValue(`From`),
+		To:   r.FormValue(`To`),
+		Body: r.FormValue(`Body`),
+	}, nil
+}
+
+// ParseStatus validates the signature of a status callback webhook and returns the delivery
+// status it reports.
+func (t TwilioMessager) ParseStatus(r *http.Request) (*StatusReport, error) {
+	err := ValidateIncomingRequest(t.webhookHost, t.token, r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StatusReport{
+		ID:        r.FormValue(`MessageSid`),
+		Status:    r.FormValue(`MessageStatus`),
+		ErrorCode: r.FormValue(`ErrorCode`),
+	}, nil
+}
+
+// Reply answers an incoming message webhook with a TwiML document, so that the reply is sent
+// without a separate request to the REST API.
+func (t TwilioMessager) Reply(body string) (string, []byte, error) {
+	b, err := NewTwiML().Message(body).Marshal()
+	if err != nil {
+		return "", nil, err
+	}
+
+	return "text/xml", b, nil
+}
+
 // ValidateIncomingRequest returns an error if the incoming req could not be
 // validated as coming from Twilio.
 //
@@ -106,7 +177,7 @@ func ValidateIncomingRequest(host string, authToken string, req *http.Request) (
 func validateIncomingRequest(host string, authToken string, URL string, postForm url.Values, xTwilioSignature string) (err error) {
 	expectedTwilioSignature := GetExpectedTwilioSignature(host, authToken, URL, postForm)
 	if xTwilioSignature != expectedTwilioSignature {
-		err = errors.New("Bad X-Twilio-Signature")
+		err = errors.Wrap(ErrInvalidSignature, "Bad X-Twilio-Signature")
 		return
 	}
 
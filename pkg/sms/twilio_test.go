@@ -187,3 +187,13 @@ func NewTestClient(fn RoundTripFunc) *http.Client {
 		Transport: fn,
 	}
 }
+
+func TestTwilioMessager_WithStatusCallback(t *testing.T) {
+	tm := sms.NewTwilioMessager(c, "foo", "bar", "212-867-5309").WithStatusCallback("https://example.com/webhooks/v1/sms/status")
+	req, err := tm.SMSRequest("404-515-0400", "test message")
+	require.NoError(t, err)
+
+	body, err := ioutil.ReadAll(req.Body)
+	require.NoError(t, err)
+	require.Equal(t, `Body=test+message&From=212-867-5309&StatusCallback=https%3A%2F%2Fexample.com%2Fwebhooks%2Fv1%2Fsms%2Fstatus&To=404-515-0400`, string(body))
+}
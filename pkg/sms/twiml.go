@@ -0,0 +1,49 @@
+package sms
+
+import (
+	"encoding/xml"
+)
+
+// TwiML is a Twilio Markup Language document used to reply to an inbound message webhook.
+// Replying with TwiML sends the reply as part of the webhook response, rather than as a
+// separate request to the REST API.
+// See https://www.twilio.com/docs/messaging/twiml
+type TwiML struct {
+	XMLName  xml.Name       `xml:"Response"`
+	Messages []TwiMLMessage `xml:"Message"`
+}
+
+// TwiMLMessage is a <Message> verb. To is only required when replying to a number other
+// than the sender.
+type TwiMLMessage struct {
+	To   string `xml:"to,attr,omitempty"`
+	Body string `xml:"Body"`
+}
+
+// NewTwiML returns an empty TwiML response. An empty response acknowledges the webhook
+// without replying.
+func NewTwiML() *TwiML {
+	return &TwiML{}
+}
+
+// Message adds a reply message with the body to the response.
+func (t *TwiML) Message(body string) *TwiML {
+	t.Messages = append(t.Messages, TwiMLMessage{Body: body})
+	return t
+}
+
+// MessageTo adds a message with the body, to be sent to `number`, to the response.
+func (t *TwiML) MessageTo(number, body string) *TwiML {
+	t.Messages = append(t.Messages, TwiMLMessage{To: number, Body: body})
+	return t
+}
+
+// Marshal encodes the response as an XML document.
+func (t *TwiML) Marshal() ([]byte, error) {
+	b, err := xml.Marshal(t)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), b...), nil
+}
@@ -0,0 +1,42 @@
+package sms_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jcorry/morellis/pkg/sms"
+)
+
+func TestTwiML_Marshal(t *testing.T) {
+	tests := []struct {
+		name  string
+		twiml *sms.TwiML
+		want  string
+	}{
+		{
+			"empty",
+			sms.NewTwiML(),
+			`<Response></Response>`,
+		},
+		{
+			"message",
+			sms.NewTwiML().Message("Got it! We'll text you when a flavor with coconut is in the cooler."),
+			`<Response><Message><Body>Got it! We&#39;ll text you when a flavor with coconut is in the cooler.</Body></Message></Response>`,
+		},
+		{
+			"messages",
+			sms.NewTwiML().Message("one").MessageTo("+14045551212", "two & three"),
+			`<Response><Message><Body>one</Body></Message><Message to="+14045551212"><Body>two &amp; three</Body></Message></Response>`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := tt.twiml.Marshal()
+			require.NoError(t, err)
+			require.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+tt.want, string(b))
+		})
+	}
+}
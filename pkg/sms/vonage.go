@@ -0,0 +1,213 @@
+package sms
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const VONAGE_BASE_URL = `https://rest.nexmo.com`
+
+// VonageMessager sends messages with the Vonage (formerly Nexmo) SMS API.
+// See https://developer.vonage.com/messaging/sms/overview
+type VonageMessager struct {
+	client          *http.Client
+	key             string
+	secret          string
+	signatureSecret string
+	from            string
+	baseURL         string
+	statusCallback  string
+}
+
+// NewVonageMessager configures and returns a new VonageMessager. `signatureSecret` is used to
+// validate the signatures of incoming webhooks.
+func NewVonageMessager(c *http.Client, key, secret, signatureSecret, from string) VonageMessager {
+	return VonageMessager{
+		client:          c,
+		key:             key,
+		secret:          secret,
+		signatureSecret: signatureSecret,
+		from:            from,
+		baseURL:         VONAGE_BASE_URL,
+	}
+}
+
+// WithBaseURL returns a copy of the VonageMessager which sends requests to the API at `url`
+// rather than VONAGE_BASE_URL.
+func (v VonageMessager) WithBaseURL(url string) VonageMessager {
+	v.baseURL = url
+	return v
+}
+
+// WithStatusCallback returns a copy of the VonageMessager which asks Vonage to post delivery
+// receipts for each message it sends to `url`.
+func (v VonageMessager) WithStatusCallback(url string) VonageMessager {
+	v.statusCallback = url
+	return v
+}
+
+// Send sends an SMS containing `message` via Vonage to `number`
+func (v VonageMessager) Send(ctx context.Context, number, message string) (string, error) {
+	req, err := v.SMSRequest(number, message)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get sms request")
+	}
+
+	res, err := v.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		b, e := ioutil.ReadAll(res.Body)
+		if e != nil {
+			return "", errors.Wrap(e, "failed to read vonage response body")
+		}
+		return "", errors.Errorf("failed to send vonage message: %s", string(b))
+	}
+
+	var d struct {
+		Messages []struct {
+			MessageID string `json:"message-id"`
+			Status    string `json:"status"`
+			ErrorText string `json:"error-text"`
+		} `json:"messages"`
+	}
+
+	err = json.NewDecoder(res.Body).Decode(&d)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to decode the vonage API response")
+	}
+
+	if len(d.Messages) == 0 {
+		return "", errors.New("vonage API response contained no messages")
+	}
+
+	// Long messages are split into parts, each of which is reported separately. The message
+	// is identified by its first part.
+	for _, m := range d.Messages {
+		if m.Status != "0" {
+			return "", errors.Errorf("failed to send vonage message: status %s: %s", m.Status, m.ErrorText)
+		}
+	}
+
+	return d.Messages[0].MessageID, nil
+}
+
+// SMSRequest configures an HTTP request to send to the Vonage SMS API
+func (v VonageMessager) SMSRequest(to, body string) (*http.Request, error) {
+	u, err := url.Parse(fmt.Sprintf(`%s/sms/json`, v.baseURL))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse sms request url")
+	}
+
+	d := url.Values{}
+	d.Set("api_key", v.key)
+	d.Set("api_secret", v.secret)
+	d.Set("to", to)
+	d.Set("from", v.from)
+	d.Set("text", body)
+	d.Set("type", "unicode")
+	if v.statusCallback != "" {
+		d.Set("callback", v.statusCallback)
+		d.Set("status-report-req", "1")
+	}
+
+	req, err := http.NewRequest("POST", u.String(), strings.NewReader(d.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Accept", "application/json")
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	return req, nil
+}
+
+// ParseInbound validates the signature of an inbound message webhook and returns the message.
+func (v VonageMessager) ParseInbound(r *http.Request) (*InboundMessage, error) {
+	err := v.validateIncomingRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &InboundMessage{
+		ID:   r.FormValue(`messageId`),
+		From: r.FormValue(`msisdn`),
+		To:   r.FormValue(`to`),
+		Body: r.FormValue(`text`),
+	}, nil
+}
+
+// ParseStatus validates the signature of a delivery receipt webhook and returns the delivery
+// status it reports.
+func (v VonageMessager) ParseStatus(r *http.Request) (*StatusReport, error) {
+	err := v.validateIncomingRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &StatusReport{
+		ID:     r.FormValue(`messageId`),
+		Status: r.FormValue(`status`),
+	}
+
+	if code := r.FormValue(`err-code`); code != "0" {
+		report.ErrorCode = code
+	}
+
+	return report, nil
+}
+
+func (v VonageMessager) validateIncomingRequest(r *http.Request) error {
+	err := r.ParseForm()
+	if err != nil {
+		return err
+	}
+
+	expected := GetExpectedVonageSignature(v.signatureSecret, r.Form)
+	if subtle.ConstantTimeCompare([]byte(strings.ToLower(r.Form.Get("sig"))), []byte(expected)) != 1 {
+		return errors.Wrap(ErrInvalidSignature, "Bad Vonage sig")
+	}
+
+	return nil
+}
+
+// GetExpectedVonageSignature returns the md5hash signature Vonage would send with the webhook
+// parameters `params`.
+// See https://developer.vonage.com/concepts/guides/signing-messages
+func GetExpectedVonageSignature(signatureSecret string, params url.Values) string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		if key == "sig" {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// Each parameter is appended as &key=value, with any & or = in the value replaced by _
+	replacer := strings.NewReplacer("&", "_", "=", "_")
+
+	var b strings.Builder
+	for _, key := range keys {
+		b.WriteString("&" + key + "=" + replacer.Replace(params.Get(key)))
+	}
+	b.WriteString(signatureSecret)
+
+	sum := md5.Sum([]byte(b.String()))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,175 @@
+package sms_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jcorry/morellis/pkg/sms"
+)
+
+func TestVonageMessager_Send(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    string
+		wantErr bool
+	}{
+		{"success", http.StatusOK, `{"message-count":"1","messages":[{"to":"14045551212","message-id":"0A0000000123ABCD1","status":"0"}]}`, "0A0000000123ABCD1", false},
+		{"err: rejected", http.StatusOK, `{"message-count":"1","messages":[{"status":"2","error-text":"Missing to param"}]}`, "", true},
+		{"err: bad request", http.StatusBadRequest, `bad request`, "", true},
+		{"err: undecodable response", http.StatusOK, `{"messages":`, "", true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var form url.Values
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, "/sms/json", r.URL.Path)
+				require.NoError(t, r.ParseForm())
+				form = r.PostForm
+
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer ts.Close()
+
+			vm := sms.NewVonageMessager(ts.Client(), "key", "secret", "sigsecret", "18005551212").
+				WithBaseURL(ts.URL).
+				WithStatusCallback("https://example.com/webhooks/v1/sms/status")
+
+			got, err := vm.Send(context.TODO(), "14045551212", "Test Message")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			require.Equal(t, tt.want, got)
+
+			require.Equal(t, "key", form.Get("api_key"))
+			require.Equal(t, "secret", form.Get("api_secret"))
+			require.Equal(t, "14045551212", form.Get("to"))
+			require.Equal(t, "18005551212", form.Get("from"))
+			require.Equal(t, "Test Message", form.Get("text"))
+			require.Equal(t, "https://example.com/webhooks/v1/sms/status", form.Get("callback"))
+		})
+	}
+}
+
+func TestVonageMessager_ParseInbound(t *testing.T) {
+	vm := sms.NewVonageMessager(&http.Client{}, "key", "secret", "sigsecret", "18005551212")
+
+	form := url.Values{
+		"msisdn":    {"14045551212"},
+		"to":        {"18005551212"},
+		"messageId": {"0A0000000123ABCD1"},
+		"text":      {"ADD coconut & pecan"},
+		"timestamp": {"1614556800"},
+	}
+
+	tests := []struct {
+		name    string
+		sig     string
+		wantErr bool
+	}{
+		{"valid signature", sms.GetExpectedVonageSignature("sigsecret", form), false},
+		{"invalid signature", sms.GetExpectedVonageSignature("wrong", form), true},
+		{"no signature", "", true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			f := url.Values{}
+			for k, v := range form {
+				f[k] = v
+			}
+			if tt.sig != "" {
+				f.Set("sig", tt.sig)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/webhooks/v1/sms/inbound", strings.NewReader(f.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			msg, err := vm.ParseInbound(req)
+			if tt.wantErr {
+				require.Equal(t, sms.ErrInvalidSignature, errors.Cause(err))
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, &sms.InboundMessage{
+				ID:   "0A0000000123ABCD1",
+				From: "14045551212",
+				To:   "18005551212",
+				Body: "ADD coconut & pecan",
+			}, msg)
+		})
+	}
+}
+
+func TestVonageMessager_ParseStatus(t *testing.T) {
+	vm := sms.NewVonageMessager(&http.Client{}, "key", "secret", "sigsecret", "18005551212")
+
+	form := url.Values{
+		"messageId": {"0A0000000123ABCD1"},
+		"status":    {"failed"},
+		"err-code":  {"6"},
+	}
+	form.Set("sig", sms.GetExpectedVonageSignature("sigsecret", form))
+
+	req := httptest.NewRequest(http.MethodGet, "/webhooks/v1/sms/status?"+form.Encode(), nil)
+
+	report, err := vm.ParseStatus(req)
+	require.NoError(t, err)
+	require.Equal(t, &sms.StatusReport{ID: "0A0000000123ABCD1", Status: "failed", ErrorCode: "6"}, report)
+}
+
+func TestTwilioMessager_ParseInbound(t *testing.T) {
+	tm := sms.NewTwilioMessager(&http.Client{}, "foo", "bar", "18005551212").WithWebhookHost("https://example.com")
+
+	form := url.Values{
+		"MessageSid": {"SM123"},
+		"From":       {"+14045551212"},
+		"To":         {"+18005551212"},
+		"Body":       {"LIST"},
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/v1/sms/inbound", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Twilio-Signature", sms.GetExpectedTwilioSignature("https://example.com", "bar", "/webhooks/v1/sms/inbound", form))
+
+	msg, err := tm.ParseInbound(req)
+	require.NoError(t, err)
+	require.Equal(t, &sms.InboundMessage{ID: "SM123", From: "+14045551212", To: "+18005551212", Body: "LIST"}, msg)
+
+	req = httptest.NewRequest(http.MethodPost, "/webhooks/v1/sms/inbound", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Twilio-Signature", "foo")
+
+	_, err = tm.ParseInbound(req)
+	require.Equal(t, sms.ErrInvalidSignature, errors.Cause(err))
+}
+
+func TestTwilioMessager_SendBaseURL(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/2010-04-01/Accounts/foo/Messages.json", r.URL.Path)
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.Contains(t, string(body), "To=4045551212")
+
+		w.Write([]byte(`{"sid": "SM123"}`))
+	}))
+	defer ts.Close()
+
+	sid, err := sms.NewTwilioMessager(ts.Client(), "foo", "bar", "18005551212").WithBaseURL(ts.URL).Send(context.TODO(), "4045551212", "hello")
+	require.NoError(t, err)
+	require.Equal(t, "SM123", sid)
+}